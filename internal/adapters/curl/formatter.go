@@ -0,0 +1,324 @@
+package curl
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// placeholderPattern matches the {{variable}} placeholders of .http files
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// Formatter writes HTTP files as executable shell scripts running curl
+type Formatter struct{}
+
+// FormatHTTPFile formats an HTTPFile as a bash script. Its global variables
+// default to their values and can be overridden from the environment, e.g.
+// baseUrl=http://localhost:8080 ./pets.sh
+func (f *Formatter) FormatHTTPFile(file *models.HTTPFile) string {
+	var builder strings.Builder
+
+	builder.WriteString("#!/usr/bin/env bash\n")
+	builder.WriteString("set -euo pipefail\n")
+
+	if len(file.GlobalVars) > 0 {
+		builder.WriteString("\n# Variables, each one can be overridden from the environment\n")
+		for _, name := range declarationOrder(file.GlobalVars) {
+			if comment := file.VarComments[name]; comment != "" {
+				builder.WriteString(fmt.Sprintf("# %s: %s\n", name, comment))
+			}
+			builder.WriteString(fmt.Sprintf("%s=\"${%s:-%s}\"\n", shellName(name), shellName(name), expand(file.GlobalVars[name], nil, escapeDefault)))
+		}
+	}
+
+	// Values read from the responses of requests are captured after them
+	captures := make(map[string][]models.ResponseCapture)
+	for _, name := range sortedKeys(file.ResponseVars) {
+		ref := file.ResponseVars[name]
+		captures[ref.Request] = append(captures[ref.Request], models.ResponseCapture{Variable: name, Path: ref.Path})
+	}
+
+	for _, req := range file.Requests {
+		if extra := captures[req.ID]; len(extra) > 0 {
+			all := append([]models.ResponseCapture{}, req.Captures...)
+			for _, capture := range extra {
+				if !capturesVariable(req.Captures, capture.Variable) {
+					all = append(all, capture)
+				}
+			}
+			req.Captures = all
+		}
+		builder.WriteString("\n")
+		builder.WriteString(formatRequest(req, file.GlobalVars))
+	}
+
+	return builder.String()
+}
+
+// capturesVariable reports whether captures store a variable
+func capturesVariable(captures []models.ResponseCapture, name string) bool {
+	for _, capture := range captures {
+		if capture.Variable == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatHTTPRequest formats a single HTTPRequest as a curl command
+func (f *Formatter) FormatHTTPRequest(req models.HTTPRequest) string {
+	return formatRequest(req, nil)
+}
+
+// formatRequest formats a request as a curl command. Placeholders of global
+// variables become shell variables; the others default to the value of the
+// request variable, if any. Captured values are read from the response with jq.
+func formatRequest(req models.HTTPRequest, globals map[string]string) string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("# %s\n", req.Name))
+	if req.Description != "" && req.Description != req.Name {
+		builder.WriteString(fmt.Sprintf("# %s\n", strings.Join(strings.Fields(req.Description), " ")))
+	}
+
+	defaults := func(name string) (string, bool) {
+		if _, ok := globals[name]; ok {
+			return "", false
+		}
+		return req.Vars[name], true
+	}
+	arg := func(s string) string {
+		return "\"" + expand(s, defaults, escapeDoubleQuoted) + "\""
+	}
+
+	args := []string{"curl --fail --silent --show-error"}
+	if strings.EqualFold(req.Method, "HEAD") {
+		args = append(args, "--head")
+	} else {
+		args = append(args, "--request "+strings.ToUpper(req.Method))
+	}
+
+	// curl writes the Content-Type of forms, with its own boundary
+	multipart := len(req.FormParts) > 0
+
+	// Basic credentials are sent with --user, which encodes them
+	basic := req.Auth != nil && req.Auth.Type == models.AuthBasic
+	if basic {
+		args = append(args, "--user "+arg(req.Auth.Username+":"+req.Auth.Password))
+	}
+
+	names := make([]string, 0, len(req.Headers))
+	for name := range req.Headers {
+		if multipart && strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+		if basic && strings.EqualFold(name, "Authorization") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "--header "+arg(name+": "+req.Headers[name]))
+	}
+
+	if multipart {
+		for _, part := range req.FormParts {
+			args = append(args, formArg(part, arg))
+		}
+	} else if req.Body != "" {
+		args = append(args, "--data-raw "+heredoc(req.Body, defaults))
+	} else if req.BodyFile != "" {
+		args = append(args, "--data-binary "+arg("@"+req.BodyFile))
+	}
+	args = append(args, "--write-out '\\n'")
+
+	url := req.Path
+	if strings.HasPrefix(url, "/") {
+		url = "{{baseUrl}}" + url
+	}
+	args = append(args, arg(url))
+	command := strings.Join(args, " \\\n  ")
+
+	if len(req.Captures) == 0 {
+		builder.WriteString(command)
+		builder.WriteString("\n")
+		return builder.String()
+	}
+
+	builder.WriteString("response=$(" + command + ")\n")
+	builder.WriteString("printf '%s\\n' \"$response\"\n")
+	for _, capture := range req.Captures {
+		builder.WriteString(fmt.Sprintf("%s=$(printf '%%s' \"$response\" | jq -r '%s')\n", shellName(capture.Variable), jqPath(capture.Path)))
+	}
+	return builder.String()
+}
+
+// formArg returns the curl option sending a multipart part: --form reading a
+// file for file parts, --form-string for text, which curl sends as is
+func formArg(part models.FormPart, arg func(string) string) string {
+	if part.File == "" {
+		return "--form-string " + arg(part.Name+"="+part.Value)
+	}
+
+	value := part.Name + "=@" + part.File
+	if part.FileName != "" && part.FileName != path.Base(part.File) {
+		value += ";filename=" + part.FileName
+	}
+	if part.ContentType != "" {
+		value += ";type=" + part.ContentType
+	}
+	return "--form " + arg(value)
+}
+
+// heredoc quotes a body as an argument read from a heredoc, without the
+// newline the heredoc ends with. Bodies without placeholders are quoted so
+// that the shell leaves them untouched; otherwise the characters the shell
+// interprets are escaped before the placeholders are expanded.
+func heredoc(body string, defaults func(string) (string, bool)) string {
+	delimiter := "BODY"
+	lines := strings.Split(body, "\n")
+	for n := 2; containsLine(lines, delimiter); n++ {
+		delimiter = fmt.Sprintf("BODY%d", n)
+	}
+
+	if !placeholderPattern.MatchString(body) {
+		return fmt.Sprintf("\"$(cat <<'%s'\n%s\n%s\n)\"", delimiter, body, delimiter)
+	}
+	return fmt.Sprintf("\"$(cat <<%s\n%s\n%s\n)\"", delimiter, expand(body, defaults, escapeHeredoc), delimiter)
+}
+
+// containsLine reports whether one of the lines equals s
+func containsLine(lines []string, s string) bool {
+	for _, line := range lines {
+		if line == s {
+			return true
+		}
+	}
+	return false
+}
+
+// expand escapes a string for the shell and turns its placeholders into
+// parameter expansions. defaults returns the default value of a variable
+// that is not a global one; with nil defaults all variables are global.
+func expand(s string, defaults func(string) (string, bool), escape func(string) string) string {
+	var builder strings.Builder
+	last := 0
+	for _, match := range placeholderPattern.FindAllStringSubmatchIndex(s, -1) {
+		builder.WriteString(escape(s[last:match[0]]))
+		name := shellName(s[match[2]:match[3]])
+
+		value, local := "", false
+		if defaults != nil {
+			value, local = defaults(s[match[2]:match[3]])
+		}
+		if local {
+			builder.WriteString(fmt.Sprintf("${%s:-%s}", name, escapeDefault(value)))
+		} else {
+			builder.WriteString(fmt.Sprintf("${%s}", name))
+		}
+		last = match[1]
+	}
+	builder.WriteString(escape(s[last:]))
+	return builder.String()
+}
+
+var (
+	doubleQuotedEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	heredocEscaper      = strings.NewReplacer(`\`, `\\`, "$", `\$`, "`", "\\`")
+	defaultEscaper      = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`", "}", `\}`)
+)
+
+// escapeDoubleQuoted escapes the characters interpreted inside double quotes
+func escapeDoubleQuoted(s string) string {
+	return doubleQuotedEscaper.Replace(s)
+}
+
+// escapeHeredoc escapes the characters interpreted inside an unquoted heredoc
+func escapeHeredoc(s string) string {
+	return heredocEscaper.Replace(s)
+}
+
+// escapeDefault escapes the default value of a ${name:-default} expansion
+// inside double quotes
+func escapeDefault(s string) string {
+	return defaultEscaper.Replace(s)
+}
+
+// shellName turns a variable name into a valid shell variable name
+func shellName(name string) string {
+	var builder strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z'):
+			builder.WriteRune(r)
+		case '0' <= r && r <= '9':
+			if i == 0 {
+				builder.WriteRune('_')
+			}
+			builder.WriteRune(r)
+		default:
+			builder.WriteRune('_')
+		}
+	}
+	return builder.String()
+}
+
+// jqPath returns the jq filter of a dotted path, e.g. .access_token or .["first-name"]
+func jqPath(path string) string {
+	var builder strings.Builder
+	for _, part := range strings.Split(path, ".") {
+		if part != "" && shellName(part) == part && (part[0] < '0' || part[0] > '9') {
+			builder.WriteString("." + part)
+		} else {
+			builder.WriteString(fmt.Sprintf(".[%q]", part))
+		}
+	}
+	return builder.String()
+}
+
+// declarationOrder sorts variables by name, declaring the variables a value
+// references before it, e.g. region before https://{{region}}.example.com
+func declarationOrder(vars map[string]string) []string {
+	order := make([]string, 0, len(vars))
+	visited := make(map[string]bool, len(vars))
+
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		// Marked before its references, so that circular references end
+		visited[name] = true
+		for _, match := range placeholderPattern.FindAllStringSubmatch(vars[name], -1) {
+			if _, ok := vars[match[1]]; ok {
+				visit(match[1])
+			}
+		}
+		order = append(order, name)
+	}
+
+	for _, name := range sortedKeys(vars) {
+		visit(name)
+	}
+	return order
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// NewFormatter creates a new Formatter instance
+func NewFormatter() *Formatter {
+	return &Formatter{}
+}
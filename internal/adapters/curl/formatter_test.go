@@ -0,0 +1,273 @@
+package curl
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/application/formatter"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+var _ formatter.HTTPFormatter = (*Formatter)(nil)
+
+func TestFormatter_FormatHTTPRequest(t *testing.T) {
+	formatter := NewFormatter()
+
+	tests := []struct {
+		name     string
+		request  models.HTTPRequest
+		expected []string
+	}{
+		{
+			name: "GET request with headers",
+			request: models.HTTPRequest{
+				Name:        "Get Pets",
+				Method:      "GET",
+				Path:        "/pets?limit={{limit}}",
+				Headers:     map[string]string{"X-Trace": "a\"b", "Accept": "application/json"},
+				Description: "List the pets",
+				Vars:        map[string]string{"limit": "10"},
+			},
+			expected: []string{
+				"# Get Pets\n# List the pets\n",
+				"curl --fail --silent --show-error \\\n  --request GET \\\n",
+				"  --header \"Accept: application/json\" \\\n  --header \"X-Trace: a\\\"b\" \\\n",
+				"\"${baseUrl:-}/pets?limit=${limit:-10}\"\n",
+			},
+		},
+		{
+			name: "HEAD request",
+			request: models.HTTPRequest{
+				Name:   "Check Pets",
+				Method: "HEAD",
+				Path:   "https://api.example.com/pets",
+			},
+			expected: []string{
+				"  --head \\\n",
+				"\"https://api.example.com/pets\"\n",
+			},
+		},
+		{
+			name: "body without placeholders is quoted",
+			request: models.HTTPRequest{
+				Name:   "Create Pet",
+				Method: "POST",
+				Path:   "/pets",
+				Body:   "{\"name\": \"$HOME `id`\"}",
+			},
+			expected: []string{
+				"  --data-raw \"$(cat <<'BODY'\n{\"name\": \"$HOME `id`\"}\nBODY\n)\" \\\n",
+			},
+		},
+		{
+			name: "body with placeholders is escaped",
+			request: models.HTTPRequest{
+				Name:   "Create Pet",
+				Method: "POST",
+				Path:   "/pets",
+				Body:   "{\"owner\": \"{{owner}}\", \"price\": \"$5\"}\nBODY",
+				Vars:   map[string]string{"owner": "a}b"},
+			},
+			expected: []string{
+				"\"$(cat <<BODY2\n{\"owner\": \"${owner:-a\\}b}\", \"price\": \"\\$5\"}\nBODY\nBODY2\n)\"",
+			},
+		},
+		{
+			name: "multipart form",
+			request: models.HTTPRequest{
+				Name:    "Upload Photo",
+				Method:  "POST",
+				Path:    "/pets/{{petId}}/photos",
+				Headers: map[string]string{"Content-Type": "multipart/form-data; boundary=WebAppBoundary"},
+				FormParts: []models.FormPart{
+					{Name: "caption", Value: "{{caption}}"},
+					{Name: "photo", File: "./photo.png", FileName: "photo.png", ContentType: "image/png"},
+				},
+				Vars: map[string]string{"petId": "1", "caption": "Rex"},
+			},
+			expected: []string{
+				"  --request POST \\\n  --form-string \"caption=${caption:-Rex}\" \\\n  --form \"photo=@./photo.png;type=image/png\" \\\n",
+			},
+		},
+		{
+			name: "basic authentication",
+			request: models.HTTPRequest{
+				Name:    "List Users",
+				Method:  "GET",
+				Path:    "/users",
+				Headers: map[string]string{"Authorization": "Basic {{user}} {{password}}", "Accept": "application/json"},
+				Auth:    &models.HTTPAuth{Type: models.AuthBasic, Username: "{{user}}", Password: "{{password}}"},
+			},
+			expected: []string{
+				"  --request GET \\\n  --user \"${user:-}:${password:-}\" \\\n  --header \"Accept: application/json\" \\\n  --write-out",
+			},
+		},
+		{
+			name: "captured response values",
+			request: models.HTTPRequest{
+				Name:     "Get Token",
+				Method:   "POST",
+				Path:     "/token",
+				Body:     "grant_type=client_credentials",
+				Captures: []models.ResponseCapture{{Variable: "authToken", Path: "access_token"}},
+			},
+			expected: []string{
+				"response=$(curl --fail",
+				"<<'BODY'\ngrant_type=client_credentials\nBODY\n)\" \\\n",
+				"\"${baseUrl:-}/token\")\n",
+				"printf '%s\\n' \"$response\"\n",
+				"authToken=$(printf '%s' \"$response\" | jq -r '.access_token')\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := formatter.FormatHTTPRequest(tt.request)
+			for _, expected := range tt.expected {
+				if !strings.Contains(result, expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, result)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatter_FormatHTTPFile(t *testing.T) {
+	file := &models.HTTPFile{
+		GlobalVars: map[string]string{
+			"baseUrl": "https://{{region}}.example.com",
+			"region":  "eu",
+			"token":   "a$b",
+		},
+		VarComments:  map[string]string{"region": "eu or us"},
+		ResponseVars: map[string]models.ResponseRef{"petId": {Request: "createPet", Path: "id"}},
+		Requests: []models.HTTPRequest{
+			{Name: "Create Pet", ID: "createPet", Method: "POST", Path: "/pets", Headers: map[string]string{"Authorization": "Bearer {{token}}"}},
+			{Name: "Get Pet", ID: "getPet", Method: "GET", Path: "/pets/{{petId}}", Vars: map[string]string{"petId": "1"}},
+		},
+	}
+
+	result := NewFormatter().FormatHTTPFile(file)
+
+	expected := []string{
+		"#!/usr/bin/env bash\nset -euo pipefail\n",
+		"\n# region: eu or us\nregion=\"${region:-eu}\"\nbaseUrl=\"${baseUrl:-https://${region}.example.com}\"\ntoken=\"${token:-a\\$b}\"\n",
+		"--header \"Authorization: Bearer ${token}\"",
+		"petId=$(printf '%s' \"$response\" | jq -r '.id')\n",
+		"\"${baseUrl}/pets/${petId:-1}\"\n",
+	}
+	for _, want := range expected {
+		if !strings.Contains(result, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, result)
+		}
+	}
+}
+
+func TestShellName(t *testing.T) {
+	tests := map[string]string{
+		"baseUrl":    "baseUrl",
+		"api-key":    "api_key",
+		"2fa":        "_2fa",
+		"user.name":  "user_name",
+		"snake_case": "snake_case",
+	}
+	for name, want := range tests {
+		if got := shellName(name); got != want {
+			t.Errorf("shellName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestJQPath(t *testing.T) {
+	tests := map[string]string{
+		"id":              ".id",
+		"data.token":      ".data.token",
+		"first-name":      `.["first-name"]`,
+		"items.0":         `.items.["0"]`,
+		"token_endpoint":  ".token_endpoint",
+		"meta.next-token": `.meta.["next-token"]`,
+	}
+	for path, want := range tests {
+		if got := jqPath(path); got != want {
+			t.Errorf("jqPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+// TestFormatter_Script runs a generated script against a test server, the
+// token of the first request must be sent by the second one
+func TestFormatter_Script(t *testing.T) {
+	for _, tool := range []string{"bash", "curl", "jq"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s is not installed", tool)
+		}
+	}
+
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, fmt.Sprintf("%s %s %s %s", r.Method, r.URL.RequestURI(), r.Header.Get("Authorization"), body))
+		if r.URL.Path == "/token" {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"access_token": "s3cr3t"}`)
+		}
+	}))
+	defer server.Close()
+
+	file := &models.HTTPFile{
+		GlobalVars: map[string]string{"baseUrl": "https://api.example.com"},
+		Requests: []models.HTTPRequest{
+			{
+				Name:     "Get Token",
+				Method:   "POST",
+				Path:     "/token",
+				Body:     `{"quote": "it's $5 ` + "`now`" + `"}`,
+				Captures: []models.ResponseCapture{{Variable: "authToken", Path: "access_token"}},
+			},
+			{
+				Name:    "List Pets",
+				Method:  "GET",
+				Path:    "/pets?name={{name}}",
+				Headers: map[string]string{"Authorization": "Bearer {{authToken}}"},
+				Vars:    map[string]string{"name": "rex"},
+				Body:    `{"token": "{{authToken}}", "price": "$5"}`,
+			},
+		},
+	}
+
+	script := filepath.Join(t.TempDir(), "pets.sh")
+	if err := os.WriteFile(script, []byte(NewFormatter().FormatHTTPFile(file)), 0755); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+
+	cmd := exec.Command("bash", script)
+	cmd.Env = append(os.Environ(), "baseUrl="+server.URL)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Script failed: %v\n%s", err, output)
+	}
+
+	expected := []string{
+		"POST /token  {\"quote\": \"it's $5 `now`\"}",
+		"GET /pets?name=rex Bearer s3cr3t {\"token\": \"s3cr3t\", \"price\": \"$5\"}",
+	}
+	if len(received) != len(expected) {
+		t.Fatalf("Expected %d requests, got %d: %q", len(expected), len(received), received)
+	}
+	for i, want := range expected {
+		if received[i] != want {
+			t.Errorf("Request %d = %q, want %q", i, received[i], want)
+		}
+	}
+	if !strings.Contains(string(output), `{"access_token": "s3cr3t"}`) {
+		t.Errorf("Expected the response in the output, got:\n%s", output)
+	}
+}
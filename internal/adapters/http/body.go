@@ -0,0 +1,383 @@
+package http
+
+import (
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// multipartBoundary separates the parts of generated multipart/form-data bodies
+const multipartBoundary = "WebAppBoundary"
+
+// bodyFormat is the way a request body is written, chosen from its media type
+type bodyFormat int
+
+const (
+	bodyJSON      bodyFormat = iota // indented JSON
+	bodyForm                        // application/x-www-form-urlencoded pairs
+	bodyMultipart                   // multipart/form-data parts, files read with < ./path
+	bodyXML                         // XML following the xml metadata of the schema
+	bodyText                        // plain text
+	bodyFile                        // any other media type, read from a file
+)
+
+// fileExtensions maps the media types of files to the extension of the
+// generated file names; other media types get .bin
+var fileExtensions = map[string]string{
+	"application/json": ".json",
+	"application/pdf":  ".pdf",
+	"application/xml":  ".xml",
+	"application/zip":  ".zip",
+	"image/gif":        ".gif",
+	"image/jpeg":       ".jpg",
+	"image/png":        ".png",
+	"image/svg+xml":    ".svg",
+	"image/webp":       ".webp",
+	"text/csv":         ".csv",
+	"text/plain":       ".txt",
+}
+
+// bodyMedia is the media type a request body is sent as
+type bodyMedia struct {
+	contentType string
+	models.MediaTypeObj
+}
+
+// format returns the way the body is written
+func (m *bodyMedia) format() bodyFormat {
+	return formatOf(m.contentType)
+}
+
+// formatOf returns the way a body of a media type is written
+func formatOf(contentType string) bodyFormat {
+	mediaType := mediaTypeOf(contentType)
+	switch {
+	case strings.Contains(mediaType, "json"):
+		return bodyJSON
+	case mediaType == "application/x-www-form-urlencoded":
+		return bodyForm
+	case mediaType == "multipart/form-data":
+		return bodyMultipart
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return bodyXML
+	case strings.HasPrefix(mediaType, "text/"):
+		return bodyText
+	}
+	return bodyFile
+}
+
+// mediaTypeOf returns a media type without its parameters, in lower case
+func mediaTypeOf(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// requestBodyMedia finds the media type the operation's request body is sent
+// as, with its schema and examples. JSON is preferred, then forms, XML, text
+// and other media types. A Swagger v2 body parameter is sent as the first media
+// type the operation consumes, formData parameters as a form.
+func (g *Generator) requestBodyMedia(op models.OperationInfo) *bodyMedia {
+	// Look for body parameters
+	for _, param := range op.Parameters {
+		if param.In == "body" && param.Schema != nil {
+			contentType := "application/json"
+			if consumes := operationConsumes(op); len(consumes) > 0 {
+				contentType = consumes[0]
+			}
+			return &bodyMedia{contentType: contentType, MediaTypeObj: models.MediaTypeObj{Schema: param.Schema}}
+		}
+	}
+
+	if schema := g.formDataSchema(op); schema != nil {
+		return &bodyMedia{contentType: formContentType(op, schema), MediaTypeObj: models.MediaTypeObj{Schema: schema}}
+	}
+
+	// Check for request body (OpenAPI v3)
+	body := g.resolveRequestBody(op.Operation.RequestBody)
+	if body == nil || len(body.Content) == 0 {
+		return nil
+	}
+
+	contentTypes := make([]string, 0, len(body.Content))
+	for contentType, mediaType := range body.Content {
+		// JSON bodies are generated from their schema or examples
+		if formatOf(contentType) == bodyJSON && mediaType.Schema == nil && mediaType.Example == nil && len(mediaType.Examples) == 0 {
+			continue
+		}
+		contentTypes = append(contentTypes, contentType)
+	}
+	if len(contentTypes) == 0 {
+		return nil
+	}
+	sort.SliceStable(contentTypes, func(i, j int) bool {
+		if fi, fj := formatOf(contentTypes[i]), formatOf(contentTypes[j]); fi != fj {
+			return fi < fj
+		}
+		return contentTypes[i] < contentTypes[j]
+	})
+
+	return &bodyMedia{contentType: contentTypes[0], MediaTypeObj: body.Content[contentTypes[0]]}
+}
+
+// formDataSchema builds an object schema from the Swagger v2 formData
+// parameters of an operation, nil when it has none. File parameters become
+// binary strings.
+func (g *Generator) formDataSchema(op models.OperationInfo) *models.SchemaObj {
+	schema := &models.SchemaObj{Type: "object", Properties: make(map[string]models.SchemaObj)}
+	for _, param := range op.Parameters {
+		if param.In != "formData" {
+			continue
+		}
+
+		prop := models.SchemaObj{Type: "string"}
+		if param.Type == "file" {
+			prop.Format = "binary"
+		} else if s := parameterSchema(param); s != nil {
+			prop = *s
+		}
+		if example := g.parameterExample(param); example != nil {
+			prop.Example = example
+		}
+
+		if _, exists := schema.Properties[param.Name]; !exists {
+			schema.PropertyOrder = append(schema.PropertyOrder, param.Name)
+		}
+		schema.Properties[param.Name] = prop
+		if param.Required {
+			schema.Required = append(schema.Required, param.Name)
+		}
+	}
+
+	if len(schema.Properties) == 0 {
+		return nil
+	}
+	return schema
+}
+
+// formContentType returns the media type of a Swagger v2 form: the first
+// form media type the operation consumes, multipart/form-data for files
+func formContentType(op models.OperationInfo, schema *models.SchemaObj) string {
+	hasFile := false
+	for _, prop := range schema.Properties {
+		hasFile = hasFile || isBinary(&prop)
+	}
+
+	for _, contentType := range operationConsumes(op) {
+		switch formatOf(contentType) {
+		case bodyMultipart:
+			return contentType
+		case bodyForm:
+			if !hasFile {
+				return contentType
+			}
+		}
+	}
+
+	if hasFile {
+		return "multipart/form-data"
+	}
+	return "application/x-www-form-urlencoded"
+}
+
+// setRequestBody generates the body of a request, from the first example the
+// document gives for it or from its schema, and sets its Content-Type header
+func (g *Generator) setRequestBody(op models.OperationInfo, request *models.HTTPRequest) {
+	media := g.requestBodyMedia(op)
+	if media == nil {
+		return
+	}
+
+	contentType := media.contentType
+	if media.format() == bodyMultipart {
+		contentType = mediaTypeOf(contentType) + "; boundary=" + multipartBoundary
+	}
+	request.Headers["Content-Type"] = contentType
+
+	var value interface{}
+	if examples := g.mediaExamples(&media.MediaTypeObj); len(examples) > 0 {
+		value = g.exampleValue(examples[0].Value, media.Schema)
+	} else if media.Schema != nil {
+		value = g.schemaExample(media.Schema, make(map[string]bool))
+	}
+	g.setBody(request, media, value)
+}
+
+// setBody writes a body value into a request in the format of its media type
+func (g *Generator) setBody(request *models.HTTPRequest, media *bodyMedia, value interface{}) {
+	request.Body, request.BodyFile, request.FormParts = "", "", nil
+
+	switch media.format() {
+	case bodyJSON:
+		if value != nil {
+			request.Body = g.formatExample(value)
+		}
+	case bodyForm:
+		request.Body = formBody(value)
+	case bodyMultipart:
+		request.FormParts = g.multipartBody(media, value)
+	case bodyXML:
+		request.Body = g.xmlBody(media.Schema, value)
+	case bodyText:
+		request.Body = g.textBody(value)
+	default:
+		request.BodyFile = "./" + fileName("file", media.contentType, 0)
+	}
+}
+
+// formBody writes the properties of an object as an
+// application/x-www-form-urlencoded body. Arrays repeat their property,
+// objects are written as JSON.
+func formBody(value interface{}) string {
+	object, _ := value.(jsonObject)
+
+	var pairs []string
+	for _, field := range object {
+		values := []interface{}{field.Value}
+		if items, ok := field.Value.([]interface{}); ok {
+			values = items
+		}
+		for _, v := range values {
+			pairs = append(pairs, escapeQuery(field.Name, false)+"="+escapeQuery(formValue(v), false))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// formValue converts a form field value to its textual form, objects as JSON
+func formValue(value interface{}) string {
+	if object, ok := value.(jsonObject); ok {
+		data, err := object.MarshalJSON()
+		if err == nil {
+			return string(data)
+		}
+	}
+	return queryString(value)
+}
+
+// multipartBody writes the properties of an object as multipart/form-data
+// parts. Binary properties are files read from ./name, objects and arrays
+// are JSON parts, the other values text parts.
+func (g *Generator) multipartBody(media *bodyMedia, value interface{}) []models.FormPart {
+	schema := g.exampleSchema(media.Schema)
+	object, _ := value.(jsonObject)
+
+	var parts []models.FormPart
+	for _, field := range object {
+		var prop *models.SchemaObj
+		if schema != nil {
+			if p, ok := schema.Properties[field.Name]; ok {
+				prop = g.exampleSchema(&p)
+			}
+		}
+		contentType, _, _ := strings.Cut(media.Encoding[field.Name].ContentType, ",")
+		contentType = strings.TrimSpace(contentType)
+
+		switch {
+		case isBinary(prop):
+			parts = append(parts, filePart(field.Name, contentType, 0))
+		case prop != nil && prop.Type == "array" && isBinary(g.exampleSchema(prop.Items)):
+			items, _ := field.Value.([]interface{})
+			for i := range items {
+				parts = append(parts, filePart(field.Name, contentType, i+1))
+			}
+		default:
+			text := formValue(field.Value)
+			if _, isArray := field.Value.([]interface{}); isArray || isObject(field.Value) {
+				text = g.formatExample(field.Value)
+				if contentType == "" {
+					contentType = "application/json"
+				}
+			}
+			parts = append(parts, models.FormPart{Name: field.Name, Value: text, ContentType: contentType})
+		}
+	}
+	return parts
+}
+
+// filePart returns a multipart part read from a file named after the field.
+// index numbers the files of an array, from 1; 0 for a single file.
+func filePart(name, contentType string, index int) models.FormPart {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	file := fileName(name, contentType, index)
+	return models.FormPart{Name: name, File: "./" + file, FileName: file, ContentType: contentType}
+}
+
+// requestBody returns the body of a request as written in .http files, with
+// the parts of multipart/form-data bodies separated by the boundary of its
+// Content-Type and the files of the parts read with < ./path
+func requestBody(req models.HTTPRequest) string {
+	if len(req.FormParts) == 0 {
+		return req.Body
+	}
+
+	boundary := multipartBoundary
+	for name, value := range req.Headers {
+		if strings.EqualFold(name, "Content-Type") {
+			if _, params, err := mime.ParseMediaType(value); err == nil && params["boundary"] != "" {
+				boundary = params["boundary"]
+			}
+		}
+	}
+
+	var b strings.Builder
+	for _, part := range req.FormParts {
+		disposition := "form-data; name=" + strconv.Quote(part.Name)
+		if part.FileName != "" {
+			disposition += "; filename=" + strconv.Quote(part.FileName)
+		}
+		content := part.Value
+		if part.File != "" {
+			content = "< " + part.File
+		}
+
+		b.WriteString("--" + boundary + "\n")
+		b.WriteString("Content-Disposition: " + disposition + "\n")
+		if part.ContentType != "" {
+			b.WriteString("Content-Type: " + part.ContentType + "\n")
+		}
+		b.WriteString("\n" + content + "\n")
+	}
+	b.WriteString("--" + boundary + "--")
+	return b.String()
+}
+
+// fileName names a file of a media type after a field, e.g. photo.png.
+// index numbers the files of an array, from 1; 0 for a single file.
+func fileName(name, contentType string, index int) string {
+	if index > 0 {
+		name = fmt.Sprintf("%s%d", name, index)
+	}
+	extension, ok := fileExtensions[mediaTypeOf(contentType)]
+	if !ok {
+		extension = ".bin"
+	}
+	return name + extension
+}
+
+// isBinary reports whether a schema describes the content of a file
+func isBinary(schema *models.SchemaObj) bool {
+	return schema != nil && schema.Type == "string" && schema.Format == "binary"
+}
+
+// isObject reports whether a value is a generated or decoded JSON object
+func isObject(value interface{}) bool {
+	switch value.(type) {
+	case jsonObject, map[string]interface{}:
+		return true
+	}
+	return false
+}
+
+// textBody writes a value as a text/plain body, objects and arrays as JSON
+func (g *Generator) textBody(value interface{}) string {
+	if _, isArray := value.([]interface{}); isArray || isObject(value) {
+		return g.formatExample(value)
+	}
+	return queryString(value)
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"os"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/adapters/swagger"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+func TestFormatOf(t *testing.T) {
+	tests := []struct {
+		contentType string
+		expected    bodyFormat
+	}{
+		{"application/json", bodyJSON},
+		{"application/vnd.api+json; charset=utf-8", bodyJSON},
+		{"application/x-www-form-urlencoded", bodyForm},
+		{"Multipart/Form-Data; boundary=x", bodyMultipart},
+		{"application/xml", bodyXML},
+		{"application/atom+xml", bodyXML},
+		{"text/plain", bodyText},
+		{"application/octet-stream", bodyFile},
+		{"image/png", bodyFile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			if got := formatOf(tt.contentType); got != tt.expected {
+				t.Errorf("formatOf(%q) = %d, want %d", tt.contentType, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormBody(t *testing.T) {
+	value := jsonObject{
+		{Name: "name", Value: "Rex & Co"},
+		{Name: "tags", Value: []interface{}{"a", "b"}},
+		{Name: "owner", Value: jsonObject{{Name: "id", Value: float64(1)}}},
+	}
+
+	expected := "name=Rex%20%26%20Co&tags=a&tags=b&owner=%7B%22id%22%3A1%7D"
+	if got := formBody(value); got != expected {
+		t.Errorf("formBody() = %s, want %s", got, expected)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		name, contentType string
+		index             int
+		expected          string
+	}{
+		{"photo", "image/png", 0, "photo.png"},
+		{"photo", "image/jpeg", 2, "photo2.jpg"},
+		{"file", "application/octet-stream", 0, "file.bin"},
+		{"notes", "text/plain; charset=utf-8", 0, "notes.txt"},
+	}
+
+	for _, tt := range tests {
+		if got := fileName(tt.name, tt.contentType, tt.index); got != tt.expected {
+			t.Errorf("fileName(%q, %q, %d) = %s, want %s", tt.name, tt.contentType, tt.index, got, tt.expected)
+		}
+	}
+}
+
+func TestGenerator_RequestBodyMedia(t *testing.T) {
+	g := New(nil)
+	schema := &models.SchemaObj{Type: "object"}
+
+	// JSON is preferred over the other media types
+	op := models.OperationInfo{Operation: &models.Operation{RequestBody: &models.RequestBody{Content: map[string]models.MediaTypeObj{
+		"application/xml":  {Schema: schema},
+		"application/json": {Schema: schema},
+		"text/plain":       {Schema: schema},
+	}}}}
+	if media := g.requestBodyMedia(op); media == nil || media.contentType != "application/json" {
+		t.Errorf("Expected application/json, got %+v", media)
+	}
+
+	// A Swagger v2 file parameter makes a multipart form
+	op = models.OperationInfo{
+		Operation: &models.Operation{},
+		Consumes:  []string{"application/x-www-form-urlencoded", "multipart/form-data"},
+		Parameters: []models.Parameter{
+			{Name: "caption", In: "formData", Type: "string"},
+			{Name: "photo", In: "formData", Type: "file", Required: true},
+		},
+	}
+	media := g.requestBodyMedia(op)
+	if media == nil || media.contentType != "multipart/form-data" {
+		t.Fatalf("Expected multipart/form-data, got %+v", media)
+	}
+	if photo := media.Schema.Properties["photo"]; !isBinary(&photo) {
+		t.Errorf("Expected the file parameter to be binary, got %+v", photo)
+	}
+}
+
+func TestGenerator_XMLBody(t *testing.T) {
+	data, err := os.ReadFile("../../../test/samples/forms.json")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	parser := swagger.New()
+	doc, err := parser.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse swagger: %v", err)
+	}
+
+	files, err := NewWithOptions(parser, Options{}).Generate(doc, parser.GetBaseURL(doc))
+	if err != nil {
+		t.Fatalf("Failed to generate HTTP files: %v", err)
+	}
+
+	var addPet *models.HTTPRequest
+	for i, req := range files["pets"].Requests {
+		if req.ID == "addPet" {
+			addPet = &files["pets"].Requests[i]
+		}
+	}
+	if addPet == nil {
+		t.Fatalf("Expected an addPet request")
+	}
+
+	// The first media type consumed is used, with the xml names of the schema
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<Pet>
+  <id>0</id>
+  <name>doggie</name>
+  <photoUrls>
+    <photoUrl>string</photoUrl>
+  </photoUrls>
+</Pet>`
+	if addPet.Headers["Content-Type"] != "application/xml" || addPet.Body != expected {
+		t.Errorf("Unexpected addPet body (%s):\n%s\nwant:\n%s", addPet.Headers["Content-Type"], addPet.Body, expected)
+	}
+
+	// A literal XML example is kept as it is
+	literal := `<Pet><name>Rex</name></Pet>`
+	if got := New(nil).xmlBody(nil, literal); got != literal {
+		t.Errorf("xmlBody() = %s, want %s", got, literal)
+	}
+}
@@ -0,0 +1,287 @@
+package http
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// pathParamPattern matches the parameters of a path template, e.g. {petId}
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// requestID returns the name other requests use to reference the response of
+// an operation's request: its operationId, or its method and path when it has
+// none, e.g. getPetsPetId for GET /pets/{petId}
+func requestID(op models.OperationInfo) string {
+	if id := identifier(op.Operation.OperationID); id != "" {
+		return id
+	}
+	return identifier(strings.ToLower(op.Method) + " " + op.Path)
+}
+
+// identifier turns a string into a camelCase identifier made of letters,
+// digits and underscores, starting with a letter or underscore
+func identifier(s string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range s {
+		switch {
+		case r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9'):
+			if b.Len() == 0 && '0' <= r && r <= '9' {
+				b.WriteRune('_')
+			}
+			if upper && b.Len() > 0 {
+				r = unicode.ToUpper(r)
+			}
+			b.WriteRune(r)
+			upper = false
+		default:
+			upper = true
+		}
+	}
+	return b.String()
+}
+
+// uniqueIDs makes the IDs of the requests of a file unique by numbering the
+// repeated ones, such as the variants of a request body
+func uniqueIDs(requests []models.HTTPRequest) {
+	used := make(map[string]bool)
+	for i := range requests {
+		id := requests[i].ID
+		if id == "" {
+			continue
+		}
+		for n := 2; used[id]; n++ {
+			id = fmt.Sprintf("%s%d", requests[i].ID, n)
+		}
+		used[id] = true
+		requests[i].ID = id
+	}
+}
+
+// chainVars links the path parameters of the operations to the values returned
+// by the operations creating them, so that CRUD flows run end to end:
+// {petId} in GET /pets/{petId} is read from the id returned by createPet when
+// POST /pets returns one. ids holds the request ID of each operation.
+// Parameters that would be linked to different values are left alone.
+func (g *Generator) chainVars(ops []models.OperationInfo, ids []string) (map[string]models.ResponseRef, map[string]string) {
+	vars := make(map[string]models.ResponseRef)
+	comments := make(map[string]string)
+	conflicts := make(map[string]bool)
+
+	for _, op := range ops {
+		for _, match := range pathParamPattern.FindAllStringSubmatchIndex(op.Path, -1) {
+			param := op.Path[match[2]:match[3]]
+			collection := strings.TrimSuffix(op.Path[:match[0]], "/")
+
+			ref, ok := g.chainRef(ops, ids, collection, param)
+			if !ok || conflicts[param] {
+				continue
+			}
+			if existing, ok := vars[param]; ok && existing != ref {
+				conflicts[param] = true
+				delete(vars, param)
+				delete(comments, param)
+				continue
+			}
+			vars[param] = ref
+			comments[param] = chainComment(ref)
+		}
+	}
+
+	return vars, comments
+}
+
+// chainComment describes the value of a chained variable
+func chainComment(ref models.ResponseRef) string {
+	return fmt.Sprintf("%s returned by %s", ref.Path, ref.Request)
+}
+
+// addChainVars sets the chained path parameters of the operations of a file as
+// response variables of that file. The comments shared by all files are copied first.
+func (g *Generator) addChainVars(file *models.HTTPFile, ops []models.OperationInfo, ids []string) {
+	vars, comments := g.chainVars(ops, ids)
+	if len(vars) == 0 {
+		return
+	}
+
+	varComments := make(map[string]string, len(file.VarComments)+len(comments))
+	for name, comment := range file.VarComments {
+		varComments[name] = comment
+	}
+	for name, comment := range comments {
+		varComments[name] = comment
+	}
+
+	file.ResponseVars = vars
+	file.VarComments = varComments
+}
+
+// fileOps holds the operations of a file and the IDs of their requests
+type fileOps struct {
+	ops []models.OperationInfo
+	ids []string
+}
+
+// shareChainVars links the path parameters of the operations of a file to the
+// values returned by the operations of other files creating them, when no
+// operation of the file creates them: {petId} in the visits file reads the id
+// returned by createPet in the pets file. The creating request captures the
+// value and the file lists it as a shared variable, with the example value
+// of the parameter as placeholder. Parameters that would be linked to
+// different values, or that the creating file uses for other values, are
+// left alone.
+func (g *Generator) shareChainVars(files map[string]*models.HTTPFile, chained map[string]fileOps) {
+	tags := make([]string, 0, len(chained))
+	for tag := range chained {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	type sharedRef struct {
+		tag string
+		ref models.ResponseRef
+	}
+	for _, tag := range tags {
+		file, own := files[tag], chained[tag]
+		refs := make(map[string]sharedRef)
+		conflicts := make(map[string]bool)
+
+		for _, op := range own.ops {
+			for _, match := range pathParamPattern.FindAllStringSubmatchIndex(op.Path, -1) {
+				param := op.Path[match[2]:match[3]]
+				collection := strings.TrimSuffix(op.Path[:match[0]], "/")
+				if _, linked := file.ResponseVars[param]; linked || conflicts[param] {
+					continue
+				}
+				if _, ok := g.chainRef(own.ops, own.ids, collection, param); ok {
+					continue
+				}
+
+				for _, other := range tags {
+					if other == tag {
+						continue
+					}
+					ref, ok := g.chainRef(chained[other].ops, chained[other].ids, collection, param)
+					if !ok {
+						continue
+					}
+					if existing, seen := refs[param]; seen && existing != (sharedRef{other, ref}) {
+						conflicts[param] = true
+						delete(refs, param)
+					} else {
+						refs[param] = sharedRef{other, ref}
+					}
+					break
+				}
+			}
+		}
+
+		params := make([]string, 0, len(refs))
+		for param := range refs {
+			params = append(params, param)
+		}
+		sort.Strings(params)
+		for _, param := range params {
+			shared := refs[param]
+			value, ok := exampleVar(file, param)
+			if !ok || !captureShared(files[shared.tag], param, shared.ref) {
+				continue
+			}
+			if file.SharedVars == nil {
+				file.SharedVars = make(map[string]string)
+			}
+			file.SharedVars[param] = value
+		}
+	}
+}
+
+// exampleVar returns the value of the first request variable of a file with a name
+func exampleVar(file *models.HTTPFile, name string) (string, bool) {
+	for _, req := range file.Requests {
+		if value, ok := req.Vars[name]; ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// captureShared makes the request of a file referenced by ref capture the
+// value of a variable for the other files. It reports false when the file
+// uses the variable for another value.
+func captureShared(file *models.HTTPFile, name string, ref models.ResponseRef) bool {
+	if _, global := file.GlobalVars[name]; global {
+		return false
+	}
+	if existing, linked := file.ResponseVars[name]; linked && existing != ref {
+		return false
+	} else if !linked {
+		if _, used := exampleVar(file, name); used {
+			return false
+		}
+	}
+
+	for i := range file.Requests {
+		req := &file.Requests[i]
+		for _, capture := range req.Captures {
+			if capture.Variable == name {
+				return req.ID == ref.Request && capture.Path == ref.Path
+			}
+		}
+	}
+	for i := range file.Requests {
+		if req := &file.Requests[i]; req.ID == ref.Request {
+			req.Captures = append(req.Captures, models.ResponseCapture{Variable: name, Path: ref.Path})
+			return true
+		}
+	}
+	return false
+}
+
+// chainRef returns a reference to the field of the response of the POST
+// operation on collection that identifies the created resource
+func (g *Generator) chainRef(ops []models.OperationInfo, ids []string, collection, param string) (models.ResponseRef, bool) {
+	for i, op := range ops {
+		if !strings.EqualFold(op.Method, "POST") || op.Path != collection {
+			continue
+		}
+
+		_, response := g.successResponse(op)
+		if response == nil {
+			return models.ResponseRef{}, false
+		}
+		schema := g.responseSchema(response)
+		if schema == nil {
+			return models.ResponseRef{}, false
+		}
+
+		field := createdField(schema, param)
+		if field == "" {
+			return models.ResponseRef{}, false
+		}
+		return models.ResponseRef{Request: ids[i], Path: field}, true
+	}
+	return models.ResponseRef{}, false
+}
+
+// createdField finds the property of a response schema holding the value of a
+// path parameter: the property of the same name, or id for parameters named
+// after their resource such as petId or pet_id
+func createdField(schema *models.SchemaObj, param string) string {
+	if _, ok := schema.Properties[param]; ok {
+		return param
+	}
+
+	if strings.EqualFold(param, "id") || strings.HasSuffix(param, "Id") || strings.HasSuffix(param, "ID") || strings.HasSuffix(param, "_id") {
+		for _, name := range schema.PropertyNames() {
+			if strings.ToLower(name) == "id" {
+				return name
+			}
+		}
+	}
+	return ""
+}
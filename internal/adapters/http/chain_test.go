@@ -0,0 +1,237 @@
+package http
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/adapters/swagger"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       models.OperationInfo
+		expected string
+	}{
+		{
+			name:     "operationId",
+			op:       models.OperationInfo{Method: "POST", Path: "/pets", Operation: &models.Operation{OperationID: "createPet"}},
+			expected: "createPet",
+		},
+		{
+			name:     "operationId with separators",
+			op:       models.OperationInfo{Method: "GET", Path: "/pets", Operation: &models.Operation{OperationID: "pets.list-all"}},
+			expected: "petsListAll",
+		},
+		{
+			name:     "method and path",
+			op:       models.OperationInfo{Method: "DELETE", Path: "/pets/{petId}", Operation: &models.Operation{}},
+			expected: "deletePetsPetId",
+		},
+		{
+			name:     "leading digit",
+			op:       models.OperationInfo{Method: "GET", Path: "/", Operation: &models.Operation{OperationID: "2fa"}},
+			expected: "_2fa",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requestID(tt.op); got != tt.expected {
+				t.Errorf("requestID() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUniqueIDs(t *testing.T) {
+	requests := []models.HTTPRequest{
+		{ID: "getPet"},
+		{ID: "getPet"},
+		{ID: "getPet2"},
+		{},
+		{ID: "getPet"},
+	}
+	uniqueIDs(requests)
+
+	var ids []string
+	for _, request := range requests {
+		ids = append(ids, request.ID)
+	}
+	expected := []string{"getPet", "getPet2", "getPet22", "", "getPet3"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected IDs %v, got %v", expected, ids)
+	}
+}
+
+func TestScopeRequestVars(t *testing.T) {
+	file := &models.HTTPFile{
+		GlobalVars:   map[string]string{"baseUrl": "https://api.example.com"},
+		ResponseVars: map[string]models.ResponseRef{"petId": {Request: "createPet", Path: "id"}},
+		Requests: []models.HTTPRequest{
+			{ID: "getOwner", Path: "/owners/{{ownerId}}", Vars: map[string]string{"ownerId": "123"}},
+			{ID: "listOwners", Path: "/owners?ownerId={{ownerId}}", Vars: map[string]string{"ownerId": "123"}},
+			{ID: "getPet", Path: "/pets/{{petId}}", Vars: map[string]string{"petId": "123"}},
+			{ID: "findOwner", Path: "/owners/{{ownerId}}/find", Vars: map[string]string{"ownerId": "example_string"}},
+			{ID: "findOwner", Path: "/owners/{{ownerId}}/find?ownerId={{findOwnerOwnerId}}", Vars: map[string]string{"ownerId": "true", "findOwnerOwnerId": "1"}},
+			{ID: "updatePet", Path: "{{baseUrl}}/pets/{{petId}}", Vars: map[string]string{"petId": "example_string", "baseUrl": "http://localhost"}},
+			{ID: "getImage", Path: "{{baseUrl}}/images", Vars: map[string]string{"baseUrl": "https://api.example.com"}},
+		},
+	}
+	shared := file.Requests[4].Vars
+	scopeRequestVars(file)
+
+	expected := []models.HTTPRequest{
+		{ID: "getOwner", Path: "/owners/{{ownerId}}", Vars: map[string]string{"ownerId": "123"}},
+		{ID: "listOwners", Path: "/owners?ownerId={{ownerId}}", Vars: map[string]string{"ownerId": "123"}},
+		{ID: "getPet", Path: "/pets/{{petId}}", Vars: map[string]string{"petId": "123"}},
+		{ID: "findOwner", Path: "/owners/{{findOwnerOwnerId}}/find", Vars: map[string]string{"findOwnerOwnerId": "example_string"}},
+		{ID: "findOwner", Path: "/owners/{{findOwnerOwnerId2}}/find?ownerId={{findOwnerFindOwnerOwnerId}}", Vars: map[string]string{"findOwnerOwnerId2": "true", "findOwnerFindOwnerOwnerId": "1"}},
+		{ID: "updatePet", Path: "{{updatePetBaseUrl}}/pets/{{petId}}", Vars: map[string]string{"petId": "example_string", "updatePetBaseUrl": "http://localhost"}},
+		{ID: "getImage", Path: "{{baseUrl}}/images", Vars: map[string]string{"baseUrl": "https://api.example.com"}},
+	}
+	if !reflect.DeepEqual(file.Requests, expected) {
+		t.Errorf("Expected requests %+v, got %+v", expected, file.Requests)
+	}
+	if len(shared) != 2 || shared["ownerId"] != "true" || shared["findOwnerOwnerId"] != "1" {
+		t.Errorf("Expected the variables of the request to be copied, got %v", shared)
+	}
+}
+
+func TestGenerator_ChainVars(t *testing.T) {
+	created := func(properties ...string) map[string]models.Response {
+		schema := &models.SchemaObj{Type: "object", Properties: map[string]models.SchemaObj{}}
+		for _, name := range properties {
+			schema.Properties[name] = models.SchemaObj{Type: "string"}
+		}
+		return map[string]models.Response{
+			"400": {Description: "Invalid"},
+			"201": {Description: "Created", Schema: schema},
+		}
+	}
+	op := func(method, path string, responses map[string]models.Response) models.OperationInfo {
+		return models.OperationInfo{Method: method, Path: path, Operation: &models.Operation{Responses: responses}}
+	}
+
+	tests := []struct {
+		name     string
+		ops      []models.OperationInfo
+		expected map[string]models.ResponseRef
+	}{
+		{
+			name: "id of the created resource",
+			ops: []models.OperationInfo{
+				op("POST", "/pets", created("id", "name")),
+				op("GET", "/pets/{petId}", nil),
+			},
+			expected: map[string]models.ResponseRef{"petId": {Request: "op0", Path: "id"}},
+		},
+		{
+			name: "property named after the parameter",
+			ops: []models.OperationInfo{
+				op("GET", "/users/{user_id}/keys/{key}", nil),
+				op("POST", "/users/{user_id}/keys", created("id", "key")),
+			},
+			expected: map[string]models.ResponseRef{"key": {Request: "op1", Path: "key"}},
+		},
+		{
+			name: "no matching property",
+			ops: []models.OperationInfo{
+				op("POST", "/pets", created("name")),
+				op("GET", "/pets/{petId}", nil),
+			},
+			expected: map[string]models.ResponseRef{},
+		},
+		{
+			name: "parameter not named after an id",
+			ops: []models.OperationInfo{
+				op("POST", "/files", created("id")),
+				op("GET", "/files/{path}", nil),
+			},
+			expected: map[string]models.ResponseRef{},
+		},
+		{
+			name: "no response schema",
+			ops: []models.OperationInfo{
+				op("POST", "/pets", map[string]models.Response{"201": {Description: "Created"}}),
+				op("GET", "/pets/{petId}", nil),
+			},
+			expected: map[string]models.ResponseRef{},
+		},
+		{
+			name: "conflicting values",
+			ops: []models.OperationInfo{
+				op("POST", "/pets", created("id")),
+				op("POST", "/owners", created("id")),
+				op("GET", "/pets/{id}", nil),
+				op("GET", "/owners/{id}", nil),
+			},
+			expected: map[string]models.ResponseRef{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := New(swagger.New())
+			ids := make([]string, len(tt.ops))
+			for i := range ids {
+				ids[i] = "op" + string(rune('0'+i))
+			}
+
+			vars, _ := generator.chainVars(tt.ops, ids)
+			if !reflect.DeepEqual(vars, tt.expected) {
+				t.Errorf("Expected vars %v, got %v", tt.expected, vars)
+			}
+		})
+	}
+}
+
+func TestGenerator_ShareChainVars(t *testing.T) {
+	data, err := os.ReadFile("../../../test/samples/chaining.yaml")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	parser := swagger.New()
+	doc, err := parser.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse swagger: %v", err)
+	}
+	files, err := New(parser).Generate(doc, parser.GetBaseURL(doc))
+	if err != nil {
+		t.Fatalf("Failed to generate HTTP files: %v", err)
+	}
+
+	// createPet of the pets file captures the petId of the visits file
+	var captured bool
+	for _, req := range files["pets"].Requests {
+		for _, capture := range req.Captures {
+			captured = captured || (req.ID == "createPet" && capture == models.ResponseCapture{Variable: "petId", Path: "id"})
+		}
+	}
+	if !captured {
+		t.Errorf("Expected createPet to capture petId, got %+v", files["pets"].Requests)
+	}
+	if value := files["visits"].SharedVars["petId"]; value != "0" {
+		t.Errorf("Expected the visits file to share petId with placeholder 0, got %v", files["visits"].SharedVars)
+	}
+
+	// Clients sharing global variables read it from the capture, the others
+	// from the placeholder
+	for _, dialect := range []string{DialectJetBrains, DialectHTTPYac, DialectKulala} {
+		pets := NewDialectFormatter(dialect).FormatHTTPFile(files["pets"])
+		visits := NewDialectFormatter(dialect).FormatHTTPFile(files["visits"])
+		if !strings.Contains(pets, `client.global.set("petId", response.body.id);`) || strings.Contains(visits, "@petId = 0") {
+			t.Errorf("Expected %s to capture petId in pets.http and read it in visits.http:\n%s\n%s", dialect, pets, visits)
+		}
+		if !strings.Contains(visits, `if (client.global.get("petId") == null) client.global.set("petId", "0");`) {
+			t.Errorf("Expected %s to default petId until pets.http captures it:\n%s", dialect, visits)
+		}
+	}
+	visits := NewDialectFormatter(DialectRESTClient).FormatHTTPFile(files["visits"])
+	if strings.Count(visits, "@petId = 0\n") != 1 || strings.Index(visits, "@petId = 0") > strings.Index(visits, "###") {
+		t.Errorf("Expected rest-client to define petId once as a file variable:\n%s", visits)
+	}
+}
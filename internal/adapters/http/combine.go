@@ -0,0 +1,81 @@
+package http
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// CombineFiles merges the files of every tag into a single file, ordered by
+// tag. File variables of the last tag win for duplicates, the base URL is
+// that of the first tag and response variables are those of the first tag
+// referencing a response. HTTP clients share the variables of the combined
+// file, so request variables that the requests of different tags define
+// with different values are scoped as in the file of a tag.
+//
+// Operations with several tags appear once per tag. Their request IDs are
+// numbered as in the file of a tag, e.g. getPet2, and the response variables
+// of a tag reference its own requests.
+func CombineFiles(files map[string]*models.HTTPFile) *models.HTTPFile {
+	combined := &models.HTTPFile{
+		GlobalVars:   make(map[string]string),
+		VarComments:  make(map[string]string),
+		ResponseVars: make(map[string]models.ResponseRef),
+		Requests:     []models.HTTPRequest{},
+	}
+
+	tags := make([]string, 0, len(files))
+	for tag := range files {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	if len(tags) > 0 {
+		combined.BaseURL = files[tags[0]].BaseURL
+	}
+
+	used := make(map[string]bool)
+	for _, tag := range tags {
+		file := files[tag]
+
+		renamed := make(map[string]string)
+		for _, req := range file.Requests {
+			if req.ID != "" {
+				id := req.ID
+				for n := 2; used[id]; n++ {
+					id = fmt.Sprintf("%s%d", req.ID, n)
+				}
+				used[id] = true
+				if id != req.ID {
+					renamed[req.ID] = id
+					req.ID = id
+				}
+			}
+			combined.Requests = append(combined.Requests, req)
+		}
+
+		for name, value := range file.GlobalVars {
+			combined.GlobalVars[name] = value
+		}
+		for name, comment := range file.VarComments {
+			if _, exists := combined.VarComments[name]; !exists {
+				combined.VarComments[name] = comment
+			}
+		}
+		for name, ref := range file.ResponseVars {
+			if _, exists := combined.ResponseVars[name]; exists {
+				continue
+			}
+			if id, ok := renamed[ref.Request]; ok {
+				if combined.VarComments[name] == chainComment(ref) {
+					combined.VarComments[name] = chainComment(models.ResponseRef{Request: id, Path: ref.Path})
+				}
+				ref.Request = id
+			}
+			combined.ResponseVars[name] = ref
+		}
+	}
+
+	scopeRequestVars(combined)
+	return combined
+}
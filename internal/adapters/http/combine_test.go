@@ -0,0 +1,124 @@
+package http
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+func TestCombineFiles(t *testing.T) {
+	files := map[string]*models.HTTPFile{
+		"pets": {
+			BaseURL:      "http://api1.example.com",
+			GlobalVars:   map[string]string{"baseUrl": "http://api1.example.com", "apiKey": "key1"},
+			VarComments:  map[string]string{"petId": "id returned by createPet"},
+			ResponseVars: map[string]models.ResponseRef{"petId": {Request: "createPet", Path: "id"}},
+			Requests: []models.HTTPRequest{
+				{ID: "createPet", Method: "POST", Path: "{{baseUrl}}/pets"},
+				{ID: "getPet", Method: "GET", Path: "{{baseUrl}}/pets/{{petId}}", Vars: map[string]string{"petId": "0"}},
+				{ID: "getTag", Method: "GET", Path: "{{baseUrl}}/tags/{{id}}", Vars: map[string]string{"id": "0"}},
+			},
+		},
+		"users": {
+			BaseURL:    "http://api2.example.com",
+			GlobalVars: map[string]string{"baseUrl": "http://api2.example.com", "username": "user1"},
+			Requests: []models.HTTPRequest{
+				{
+					ID:      "getUser",
+					Method:  "PUT",
+					Path:    "{{baseUrl}}/users/{{id}}?petId={{petId}}",
+					Headers: map[string]string{"X-User": "{{id}}"},
+					Body:    `{"id": "{{id}}"}`,
+					Vars:    map[string]string{"id": "3fa85f64-5717-4562-b3fc-2c963f66afa6", "petId": "7"},
+				},
+			},
+		},
+	}
+
+	combined := CombineFiles(files)
+
+	// File variables of the last tag win, the base URL is that of the first
+	expectedGlobals := map[string]string{"baseUrl": "http://api2.example.com", "apiKey": "key1", "username": "user1"}
+	if !reflect.DeepEqual(combined.GlobalVars, expectedGlobals) {
+		t.Errorf("Expected global variables %v, got %v", expectedGlobals, combined.GlobalVars)
+	}
+	if combined.BaseURL != "http://api1.example.com" {
+		t.Errorf("Expected the base URL of the first tag, got %s", combined.BaseURL)
+	}
+	if ref := combined.ResponseVars["petId"]; ref.Request != "createPet" {
+		t.Errorf("Expected petId to reference createPet, got %+v", ref)
+	}
+
+	if len(combined.Requests) != 4 {
+		t.Fatalf("Expected 4 requests, got %d", len(combined.Requests))
+	}
+	if get := combined.Requests[1]; get.Path != "{{baseUrl}}/pets/{{petId}}" {
+		t.Errorf("Expected getPet to use the response variable, got %s", get.Path)
+	}
+	if tag := combined.Requests[2]; tag.Path != "{{baseUrl}}/tags/{{id}}" || tag.Vars["id"] != "0" {
+		t.Errorf("Expected getTag to keep its variable, got %+v", tag)
+	}
+
+	// The other tag's values are scoped, in the URL, headers and body, and
+	// it reads the responses referenced by the first tag
+	user := combined.Requests[3]
+	expected := models.HTTPRequest{
+		ID:      "getUser",
+		Method:  "PUT",
+		Path:    "{{baseUrl}}/users/{{getUserId}}?petId={{petId}}",
+		Headers: map[string]string{"X-User": "{{getUserId}}"},
+		Body:    `{"id": "{{getUserId}}"}`,
+		Vars:    map[string]string{"getUserId": "3fa85f64-5717-4562-b3fc-2c963f66afa6", "petId": "7"},
+	}
+	if !reflect.DeepEqual(user, expected) {
+		t.Errorf("Expected request %+v, got %+v", expected, user)
+	}
+
+	// The files of the tags are left as they are
+	if files["users"].Requests[0].Headers["X-User"] != "{{id}}" || files["users"].Requests[0].Vars["id"] == "" {
+		t.Errorf("Expected the users file to be unchanged, got %+v", files["users"].Requests[0])
+	}
+}
+
+func TestCombineFiles_OperationsWithSeveralTags(t *testing.T) {
+	files := map[string]*models.HTTPFile{
+		"adoptions": {
+			Requests: []models.HTTPRequest{
+				{ID: "createPet", Method: "POST", Path: "/pets"},
+				{ID: "getC", Method: "GET", Path: "/c"},
+			},
+		},
+		"pets": {
+			VarComments:  map[string]string{"petId": "id returned by createPet"},
+			ResponseVars: map[string]models.ResponseRef{"petId": {Request: "createPet", Path: "id"}},
+			Requests: []models.HTTPRequest{
+				{ID: "createPet", Method: "POST", Path: "/pets"},
+				{ID: "getC", Method: "GET", Path: "/c"},
+				{ID: "getPet", Method: "GET", Path: "/pets/{{petId}}"},
+			},
+		},
+	}
+
+	combined := CombineFiles(files)
+
+	var ids []string
+	for _, req := range combined.Requests {
+		ids = append(ids, req.ID)
+	}
+	expected := []string{"createPet", "getC", "createPet2", "getC2", "getPet"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected IDs %v, got %v", expected, ids)
+	}
+
+	// The response variable of a tag references its own request
+	if ref := combined.ResponseVars["petId"]; ref != (models.ResponseRef{Request: "createPet2", Path: "id"}) {
+		t.Errorf("Expected petId to reference createPet2, got %+v", ref)
+	}
+	if comment := combined.VarComments["petId"]; comment != "id returned by createPet2" {
+		t.Errorf("Unexpected comment %q", comment)
+	}
+	if files["pets"].Requests[0].ID != "createPet" {
+		t.Errorf("Expected the pets file to be unchanged")
+	}
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// Dialects accepted by NewDialectFormatter
+const (
+	DialectRESTClient = "rest-client"
+	DialectJetBrains  = "jetbrains"
+	DialectHTTPYac    = "httpyac"
+	DialectKulala     = "kulala"
+)
+
+// Assertion syntaxes of the dialects
+const (
+	assertNone  = ""        // expected responses are not asserted
+	assertTests = "tests"   // client.test calls in the response handler
+	assertLines = "httpyac" // httpyac ?? lines
+)
+
+// dialect describes the .http syntax understood by an HTTP client. All of them
+// share the ### request separators, @name = value file variables, {{name}}
+// references and # @name request names; they differ in how values flow from
+// a response to the following requests and in how responses are asserted.
+type dialect struct {
+	// scripts runs JavaScript response handlers (> {% ... %}) that can store
+	// captured values with client.global.set
+	scripts bool
+
+	// references reads chained values from named requests with
+	// {{name.response.body.$.path}} file variables instead of capturing them
+	references bool
+
+	// assertions is the syntax asserting the expected response of a request
+	assertions string
+}
+
+// dialects lists the supported HTTP clients by name
+var dialects = map[string]dialect{
+	// VS Code REST Client runs no scripts, it references named requests
+	DialectRESTClient: {scripts: false, references: true, assertions: assertNone},
+	// JetBrains HTTP Client, the default
+	DialectJetBrains: {scripts: true, references: false, assertions: assertTests},
+	// httpyac runs JetBrains handlers and has its own assertion lines
+	DialectHTTPYac: {scripts: true, references: false, assertions: assertLines},
+	// kulala.nvim references named requests and implements the JetBrains scripting API
+	DialectKulala: {scripts: true, references: true, assertions: assertTests},
+}
+
+// ValidDialect reports whether dialect names a supported HTTP client
+func ValidDialect(name string) bool {
+	_, ok := dialects[name]
+	return ok
+}
+
+// DialectAssertions reports whether a dialect can assert the expected responses of requests
+func DialectAssertions(name string) bool {
+	return dialects[name].assertions != assertNone
+}
+
+// fileVars returns the file variables of a file and the captures that the
+// response handlers of its requests perform, keyed by request ID. Dialects
+// referencing named requests read the response variables from them, and so
+// do dialects without scripts for the values captured by requests. Those
+// cannot read values captured in other files and define their placeholders.
+func (d dialect) fileVars(file *models.HTTPFile) (map[string]string, map[string][]models.ResponseCapture) {
+	vars := make(map[string]string, len(file.GlobalVars)+len(file.ResponseVars))
+	for name, value := range file.GlobalVars {
+		vars[name] = value
+	}
+	captures := make(map[string][]models.ResponseCapture)
+
+	names := make([]string, 0, len(file.ResponseVars))
+	for name := range file.ResponseVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		ref := file.ResponseVars[name]
+		if !d.references {
+			captures[ref.Request] = append(captures[ref.Request], models.ResponseCapture{Variable: name, Path: ref.Path})
+			continue
+		}
+		vars[name] = requestReference(ref)
+	}
+
+	// Without scripts, the first request capturing a value provides it
+	if !d.scripts {
+		captured := make(map[string]bool)
+		for _, req := range file.Requests {
+			for _, capture := range req.Captures {
+				if req.ID == "" || captured[capture.Variable] {
+					continue
+				}
+				captured[capture.Variable] = true
+				vars[capture.Variable] = requestReference(models.ResponseRef{Request: req.ID, Path: capture.Path})
+			}
+		}
+		for name, value := range file.SharedVars {
+			if _, defined := vars[name]; !defined {
+				vars[name] = value
+			}
+		}
+	}
+
+	return vars, captures
+}
+
+// requestReference references a value of the JSON response body of a named
+// request, e.g. {{createPet.response.body.$.id}}
+func requestReference(ref models.ResponseRef) string {
+	return fmt.Sprintf("{{%s.response.body.$.%s}}", ref.Request, ref.Path)
+}
+
+// scriptPath returns the JavaScript expression of a dotted path in the
+// response body, e.g. response.body.id or response.body["first-name"]
+func scriptPath(path string) string {
+	var builder strings.Builder
+	builder.WriteString("response.body")
+	for _, part := range strings.Split(path, ".") {
+		builder.WriteString(jsProperty(part))
+	}
+	return builder.String()
+}
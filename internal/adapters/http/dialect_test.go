@@ -0,0 +1,147 @@
+package http
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/application/formatter"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// Formatter implements the formatter interface of the application layer
+var _ formatter.HTTPFormatter = (*Formatter)(nil)
+
+func TestValidDialect(t *testing.T) {
+	for _, name := range []string{DialectRESTClient, DialectJetBrains, DialectHTTPYac, DialectKulala} {
+		if !ValidDialect(name) {
+			t.Errorf("Expected %s to be a valid dialect", name)
+		}
+	}
+	if ValidDialect("postman") {
+		t.Error("Expected postman not to be a valid dialect")
+	}
+	if DialectAssertions(DialectRESTClient) || !DialectAssertions(DialectHTTPYac) {
+		t.Error("Expected assertions for httpyac only")
+	}
+}
+
+func TestFormatter_Dialects(t *testing.T) {
+	file := &models.HTTPFile{
+		GlobalVars:   map[string]string{"baseUrl": "https://api.example.com", "authToken": "your_auth_token"},
+		VarComments:  map[string]string{"petId": "id returned by createPet"},
+		ResponseVars: map[string]models.ResponseRef{"petId": {Request: "createPet", Path: "id"}},
+		Requests: []models.HTTPRequest{
+			{
+				Name:     "Get token",
+				ID:       "getToken",
+				Method:   "POST",
+				Path:     "https://auth.example.com/token",
+				Captures: []models.ResponseCapture{{Variable: "authToken", Path: "access_token"}},
+			},
+			{
+				Name:     "Get another token",
+				ID:       "getOtherToken",
+				Method:   "POST",
+				Path:     "https://auth.example.com/token",
+				Captures: []models.ResponseCapture{{Variable: "authToken", Path: "token.value"}},
+			},
+			{Name: "Create pet", ID: "createPet", Method: "POST", Path: "/pets"},
+			{Name: "Get pet", ID: "getPet", Method: "GET", Path: "/pets/{{petId}}"},
+		},
+	}
+
+	tests := []struct {
+		dialect     string
+		contains    []string
+		notContains []string
+	}{
+		{
+			dialect: DialectRESTClient,
+			contains: []string{
+				"@authToken = {{getToken.response.body.$.access_token}}\n",
+				"# petId: id returned by createPet\n@petId = {{createPet.response.body.$.id}}\n",
+			},
+			notContains: []string{"> {%", "getOtherToken.response"},
+		},
+		{
+			dialect: DialectJetBrains,
+			contains: []string{
+				"@authToken = your_auth_token\n",
+				"    client.global.set(\"authToken\", response.body.access_token);\n",
+				"    client.global.set(\"authToken\", response.body.token.value);\n",
+				"POST {{baseUrl}}/pets\n\n> {%\n    client.global.set(\"petId\", response.body.id);\n%}\n",
+			},
+			notContains: []string{"@petId", ".response.body.$"},
+		},
+		{
+			dialect: DialectKulala,
+			contains: []string{
+				"@authToken = your_auth_token\n",
+				"    client.global.set(\"authToken\", response.body.access_token);\n",
+				"@petId = {{createPet.response.body.$.id}}\n",
+			},
+			notContains: []string{"client.global.set(\"petId\""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			content := NewDialectFormatter(tt.dialect).FormatHTTPFile(file)
+			for _, want := range tt.contains {
+				if !strings.Contains(content, want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, content)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(content, unwanted) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", unwanted, content)
+				}
+			}
+		})
+	}
+
+	// Formatting must not change the requests of the file
+	if len(file.Requests[2].Captures) != 0 {
+		t.Errorf("Expected the file requests to be left unchanged")
+	}
+}
+
+func TestFormatter_SharedVars(t *testing.T) {
+	file := &models.HTTPFile{
+		GlobalVars: map[string]string{"baseUrl": "https://api.example.com"},
+		SharedVars: map[string]string{"authToken": "your_auth_token"},
+		Requests: []models.HTTPRequest{{
+			Name:    "Get account",
+			ID:      "getAccount",
+			Method:  "GET",
+			Path:    "{{baseUrl}}/me",
+			Headers: map[string]string{"Authorization": "Bearer {{authToken}}"},
+		}},
+	}
+
+	// Without scripts the token captured by the auth file cannot be read
+	if content := NewDialectFormatter(DialectRESTClient).FormatHTTPFile(file); !strings.Contains(content, "@authToken = your_auth_token\n") {
+		t.Errorf("Expected a placeholder for the shared token, got:\n%s", content)
+	}
+	for _, dialect := range []string{DialectJetBrains, DialectHTTPYac, DialectKulala} {
+		if content := NewDialectFormatter(dialect).FormatHTTPFile(file); strings.Contains(content, "@authToken") {
+			t.Errorf("Expected %s to read the captured token, got:\n%s", dialect, content)
+		}
+	}
+}
+
+func TestScriptPath(t *testing.T) {
+	tests := map[string]string{
+		"access_token":    "response.body.access_token",
+		"data.id":         "response.body.data.id",
+		"first-name":      `response.body["first-name"]`,
+		"items.0":         `response.body.items["0"]`,
+		"token_endpoint":  "response.body.token_endpoint",
+		"links.self.href": "response.body.links.self.href",
+	}
+	for path, expected := range tests {
+		if got := scriptPath(path); got != expected {
+			t.Errorf("scriptPath(%q) = %q, want %q", path, got, expected)
+		}
+	}
+}
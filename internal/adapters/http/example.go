@@ -0,0 +1,390 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// jsonField is a single property of a generated JSON object
+type jsonField struct {
+	Name  string
+	Value interface{}
+}
+
+// jsonObject is a JSON object that keeps its properties in insertion order
+type jsonObject []jsonField
+
+// MarshalJSON implements json.Marshaler, writing properties in order
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(field.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// generateSchemaExample generates an example JSON document for a schema
+func (g *Generator) generateSchemaExample(schema *models.SchemaObj) string {
+	return g.formatExample(g.schemaExample(schema, make(map[string]bool)))
+}
+
+// formatExample renders an example value as indented JSON
+func (g *Generator) formatExample(example interface{}) string {
+	data, err := json.MarshalIndent(example, "", "  ")
+	if err != nil {
+		g.fail(err)
+		return "{}"
+	}
+	return string(data)
+}
+
+// schemaExample builds an example value for a schema.
+// visiting holds the references being expanded so recursive schemas terminate.
+func (g *Generator) schemaExample(schema *models.SchemaObj, visiting map[string]bool) interface{} {
+	if schema == nil {
+		return nil
+	}
+
+	if schema.Ref != "" {
+		return g.refExample(schema, visiting)
+	}
+
+	return g.resolvedExample(schema, "", visiting)
+}
+
+// resolvedExample builds an example value for a schema that has no $ref.
+// ref is the reference the schema was reached through, if any, and names the
+// schema when a discriminator value has to be derived from it.
+func (g *Generator) resolvedExample(schema *models.SchemaObj, ref string, visiting map[string]bool) interface{} {
+	// The example of the document wins, whatever the type of the schema
+	if schema.Example != nil {
+		return g.exampleValue(schema.Example, schema)
+	}
+
+	// Composition keywords
+	if len(schema.AllOf) > 0 {
+		return g.resolvedExample(g.mergeAllOf(schema, ref, visiting), ref, visiting)
+	}
+	if len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 {
+		return g.branchExample(schema, g.pickBranch(schema), visiting)
+	}
+	if schema.Not != nil && schema.Type == "" && len(schema.Properties) == 0 {
+		return notExample(schema.Not)
+	}
+
+	// Handle primitive types
+	switch schema.Type {
+	case "string", "integer", "number", "boolean":
+		return primitiveExample(schema)
+	case "array":
+		if schema.Items == nil {
+			return []interface{}{}
+		}
+		items := make([]interface{}, max(schema.MinItems, 1))
+		for i := range items {
+			items[i] = g.schemaExample(schema.Items, visiting)
+		}
+		return items
+	case "":
+		if len(schema.Enum) > 0 {
+			return primitiveExample(schema)
+		}
+	}
+	return g.objectExample(schema, visiting)
+}
+
+// refExample resolves a schema reference and builds an example for the target.
+// A reference that is already being expanded yields an empty value instead of recursing.
+func (g *Generator) refExample(schema *models.SchemaObj, visiting map[string]bool) interface{} {
+	if g.resolver == nil {
+		return jsonObject{}
+	}
+
+	resolved, err := g.resolver.ResolveSchema(schema)
+	if err != nil {
+		g.fail(err)
+		return jsonObject{}
+	}
+
+	ref := schema.Ref
+	if visiting[ref] {
+		return emptyExample(resolved)
+	}
+
+	visiting[ref] = true
+	defer delete(visiting, ref)
+
+	return g.resolvedExample(resolved, ref, visiting)
+}
+
+// mergeAllOf flattens the allOf subschemas of a schema into a single object schema.
+// Properties declared later take precedence, and a discriminator inherited from a
+// parent schema gets the value naming the schema reached through ref.
+func (g *Generator) mergeAllOf(schema *models.SchemaObj, ref string, visiting map[string]bool) *models.SchemaObj {
+	merged := *schema
+	merged.AllOf = nil
+	merged.Properties = make(map[string]models.SchemaObj)
+	merged.PropertyOrder = nil
+	merged.Required = nil
+
+	var inherited []*models.Discriminator
+	for i := range schema.AllOf {
+		part, partRef := g.resolveBranch(&schema.AllOf[i], visiting)
+		if part == nil {
+			continue
+		}
+
+		if len(part.AllOf) > 0 {
+			if partRef != "" {
+				visiting[partRef] = true
+			}
+			part = g.mergeAllOf(part, partRef, visiting)
+			if partRef != "" {
+				delete(visiting, partRef)
+			}
+		}
+
+		if merged.Type == "" {
+			merged.Type = part.Type
+		}
+		for _, name := range part.PropertyNames() {
+			mergeProperty(&merged, name, part.Properties[name])
+		}
+		merged.Required = append(merged.Required, part.Required...)
+
+		// A composition is carried over, a parent discriminator names this schema
+		if len(part.OneOf) > 0 || len(part.AnyOf) > 0 {
+			if len(merged.OneOf) == 0 && len(merged.AnyOf) == 0 {
+				merged.OneOf, merged.AnyOf, merged.Discriminator = part.OneOf, part.AnyOf, part.Discriminator
+			}
+		} else if part.Discriminator != nil {
+			inherited = append(inherited, part.Discriminator)
+		}
+	}
+
+	for _, name := range schema.PropertyNames() {
+		mergeProperty(&merged, name, schema.Properties[name])
+	}
+	merged.Required = append(merged.Required, schema.Required...)
+
+	for _, disc := range inherited {
+		setDiscriminator(&merged, disc.PropertyName, discriminatorValue(disc, ref))
+	}
+
+	return &merged
+}
+
+// mergeProperty sets a property of a merged schema, keeping the position of
+// a property that is redefined
+func mergeProperty(schema *models.SchemaObj, name string, prop models.SchemaObj) {
+	if _, exists := schema.Properties[name]; !exists {
+		schema.PropertyOrder = append(schema.PropertyOrder, name)
+	}
+	schema.Properties[name] = prop
+}
+
+// resolveBranch resolves a subschema of allOf, oneOf or anyOf.
+// It returns nil for references that are already being expanded.
+func (g *Generator) resolveBranch(branch *models.SchemaObj, visiting map[string]bool) (*models.SchemaObj, string) {
+	if branch.Ref == "" {
+		return branch, ""
+	}
+	if g.resolver == nil || visiting[branch.Ref] {
+		return nil, ""
+	}
+
+	resolved, err := g.resolver.ResolveSchema(branch)
+	if err != nil {
+		g.fail(err)
+		return nil, ""
+	}
+	return resolved, branch.Ref
+}
+
+// compositionBranches returns the oneOf branches of a schema, or its anyOf branches
+func compositionBranches(schema *models.SchemaObj) []models.SchemaObj {
+	if len(schema.OneOf) > 0 {
+		return schema.OneOf
+	}
+	return schema.AnyOf
+}
+
+// pickBranch chooses the oneOf/anyOf branch to generate, honouring the
+// preferred branches from the options and falling back to the first one
+func (g *Generator) pickBranch(schema *models.SchemaObj) int {
+	branches := compositionBranches(schema)
+	for _, want := range g.options.Branches {
+		for i, branch := range branches {
+			if branchMatches(&branch, want, schema.Discriminator) {
+				return i
+			}
+		}
+	}
+	return 0
+}
+
+// branchMatches reports whether a branch is named by want, either through its
+// schema name, its title or a discriminator mapping value
+func branchMatches(branch *models.SchemaObj, want string, disc *models.Discriminator) bool {
+	if branch.Ref != "" && strings.EqualFold(refName(branch.Ref), want) {
+		return true
+	}
+	if branch.Title != "" && strings.EqualFold(branch.Title, want) {
+		return true
+	}
+	if disc != nil && branch.Ref != "" {
+		for value, target := range disc.Mapping {
+			if strings.EqualFold(value, want) && (target == branch.Ref || target == refName(branch.Ref)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// branchExample builds an example for one oneOf/anyOf branch of a schema.
+// Properties declared next to the composition are included, and the
+// discriminator property is set to the value selecting the branch.
+func (g *Generator) branchExample(schema *models.SchemaObj, index int, visiting map[string]bool) interface{} {
+	branches := compositionBranches(schema)
+	branch := branches[index]
+
+	example := g.schemaExample(&branch, visiting)
+
+	object, isObject := example.(jsonObject)
+	if !isObject {
+		return example
+	}
+
+	if len(schema.Properties) > 0 {
+		shared := g.objectExample(schema, visiting).(jsonObject)
+		for _, field := range shared {
+			if !object.has(field.Name) {
+				object = append(object, field)
+			}
+		}
+	}
+
+	if schema.Discriminator != nil && schema.Discriminator.PropertyName != "" {
+		value := discriminatorValue(schema.Discriminator, branch.Ref)
+		if value == "" {
+			value = branch.Title
+		}
+		if value != "" {
+			object = object.set(schema.Discriminator.PropertyName, value)
+		}
+	}
+
+	return object
+}
+
+// discriminatorValue returns the discriminator value that selects the schema
+// at ref, using the mapping when present and the schema name otherwise
+func discriminatorValue(disc *models.Discriminator, ref string) string {
+	if ref == "" {
+		return ""
+	}
+
+	values := make([]string, 0, len(disc.Mapping))
+	for value := range disc.Mapping {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	for _, value := range values {
+		target := disc.Mapping[value]
+		if target == ref || target == refName(ref) {
+			return value
+		}
+	}
+	return refName(ref)
+}
+
+// setDiscriminator fixes the example of the discriminator property of a schema
+func setDiscriminator(schema *models.SchemaObj, property, value string) {
+	if property == "" || value == "" {
+		return
+	}
+
+	prop, ok := schema.Properties[property]
+	if !ok {
+		prop = models.SchemaObj{Type: "string"}
+	}
+	prop.Enum = nil
+	prop.Example = value
+	schema.Properties[property] = prop
+}
+
+// notExample returns a value that does not match the type excluded by a "not" schema
+func notExample(not *models.SchemaObj) interface{} {
+	if not.Type == "string" {
+		return 0
+	}
+	return "string"
+}
+
+// refName returns the name of the schema a reference points to, its last pointer token
+func refName(ref string) string {
+	name := ref[strings.LastIndex(ref, "/")+1:]
+	name = strings.ReplaceAll(name, "~1", "/")
+	return strings.ReplaceAll(name, "~0", "~")
+}
+
+// objectExample builds an example object from the schema properties,
+// in the order they are declared
+func (g *Generator) objectExample(schema *models.SchemaObj, visiting map[string]bool) interface{} {
+	object := jsonObject{}
+	for _, name := range schema.PropertyNames() {
+		prop := schema.Properties[name]
+		object = append(object, jsonField{Name: name, Value: g.fakeValue(name, &prop, g.schemaExample(&prop, visiting))})
+	}
+	return object
+}
+
+// has reports whether the object has a property with the given name
+func (o jsonObject) has(name string) bool {
+	for _, field := range o {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// set replaces the value of a property, appending it when missing
+func (o jsonObject) set(name string, value interface{}) jsonObject {
+	for i, field := range o {
+		if field.Name == name {
+			o[i].Value = value
+			return o
+		}
+	}
+	return append(o, jsonField{Name: name, Value: value})
+}
+
+// emptyExample returns the empty value matching the type of a schema
+func emptyExample(schema *models.SchemaObj) interface{} {
+	if schema != nil && schema.Type == "array" {
+		return []interface{}{}
+	}
+	return jsonObject{}
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"sort"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// mediaExamples returns the examples the document gives for a media type, in
+// declaration order: its example, or its named examples with their $ref
+// resolved. Examples only given by an external value are skipped.
+func (g *Generator) mediaExamples(media *models.MediaTypeObj) models.Examples {
+	if media.Example != nil {
+		return models.Examples{{Example: models.Example{Value: media.Example}}}
+	}
+
+	var examples models.Examples
+	for _, example := range media.Examples {
+		if resolved := g.resolveExample(&example.Example); resolved != nil && resolved.Value != nil {
+			examples = append(examples, models.NamedExample{Name: example.Name, Example: *resolved})
+		}
+	}
+	return examples
+}
+
+// parameterExample returns the example the document gives for a parameter:
+// its example, its first named example or its Swagger v2 x-example
+func (g *Generator) parameterExample(param models.Parameter) interface{} {
+	if param.Example != nil {
+		return param.Example
+	}
+	for _, example := range param.Examples {
+		if resolved := g.resolveExample(&example.Example); resolved != nil && resolved.Value != nil {
+			return resolved.Value
+		}
+	}
+	return param.XExample
+}
+
+// resolveExample follows an example $ref, recording any resolution error
+func (g *Generator) resolveExample(example *models.Example) *models.Example {
+	if example.Ref == "" || g.resolver == nil {
+		return example
+	}
+
+	resolved, err := g.resolver.ResolveExample(example)
+	if err != nil {
+		g.fail(err)
+		return nil
+	}
+	return resolved
+}
+
+// exampleVariants creates one request per named example of the request body.
+// It returns nil when the body has fewer than two named examples.
+func (g *Generator) exampleVariants(op models.OperationInfo, request models.HTTPRequest) []models.HTTPRequest {
+	media := g.requestBodyMedia(op)
+	if media == nil {
+		return nil
+	}
+
+	examples := g.mediaExamples(&media.MediaTypeObj)
+	if len(examples) < 2 {
+		return nil
+	}
+
+	variants := make([]models.HTTPRequest, 0, len(examples))
+	for _, example := range examples {
+		label := example.Summary
+		if label == "" {
+			label = example.Name
+		}
+
+		variant := request
+		variant.Name = request.Name + " (" + label + ")"
+		variant.ID = identifier(request.ID + " " + example.Name)
+		g.setBody(&variant, media, g.exampleValue(example.Value, media.Schema))
+		variants = append(variants, variant)
+	}
+	return variants
+}
+
+// exampleValue prepares a value given by the document for a schema: the
+// properties of its objects follow the order the schema declares them in,
+// other properties follow in sorted order
+func (g *Generator) exampleValue(value interface{}, schema *models.SchemaObj) interface{} {
+	schema = g.exampleSchema(schema)
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		var properties map[string]models.SchemaObj
+		var names []string
+		if schema != nil {
+			properties = schema.Properties
+			for _, name := range schema.PropertyNames() {
+				if _, ok := value[name]; ok {
+					names = append(names, name)
+				}
+			}
+		}
+
+		var rest []string
+		for name := range value {
+			if _, declared := properties[name]; !declared {
+				rest = append(rest, name)
+			}
+		}
+		sort.Strings(rest)
+
+		object := make(jsonObject, 0, len(value))
+		for _, name := range append(names, rest...) {
+			var prop *models.SchemaObj
+			if p, ok := properties[name]; ok {
+				prop = &p
+			}
+			object = append(object, jsonField{Name: name, Value: g.exampleValue(value[name], prop)})
+		}
+		return object
+
+	case []interface{}:
+		var items *models.SchemaObj
+		if schema != nil {
+			items = schema.Items
+		}
+		values := make([]interface{}, len(value))
+		for i, item := range value {
+			values[i] = g.exampleValue(item, items)
+		}
+		return values
+	}
+	return value
+}
+
+// exampleSchema resolves the schema of an example value and merges its allOf
+// subschemas, so that its properties are known. It returns nil when the
+// schema cannot be resolved.
+func (g *Generator) exampleSchema(schema *models.SchemaObj) *models.SchemaObj {
+	if schema == nil {
+		return nil
+	}
+
+	ref := schema.Ref
+	if ref != "" {
+		if g.resolver == nil {
+			return nil
+		}
+		resolved, err := g.resolver.ResolveSchema(schema)
+		if err != nil {
+			return nil
+		}
+		schema = resolved
+	}
+
+	if len(schema.AllOf) > 0 {
+		visiting := make(map[string]bool)
+		if ref != "" {
+			visiting[ref] = true
+		}
+		return g.mergeAllOf(schema, ref, visiting)
+	}
+	return schema
+}
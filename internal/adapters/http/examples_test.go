@@ -0,0 +1,153 @@
+package http
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/adapters/swagger"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// generateExamples generates the requests of the document examples sample
+func generateExamples(t *testing.T, options Options) map[string]*models.HTTPFile {
+	t.Helper()
+
+	data, err := os.ReadFile("../../../test/samples/examples.yaml")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	parser := swagger.New()
+	doc, err := parser.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse swagger: %v", err)
+	}
+
+	files, err := NewWithOptions(parser, options).Generate(doc, parser.GetBaseURL(doc))
+	if err != nil {
+		t.Fatalf("Failed to generate HTTP files: %v", err)
+	}
+	return files
+}
+
+func TestGenerator_DocumentExamples(t *testing.T) {
+	files := generateExamples(t, Options{})
+
+	requests := files["pets"].Requests
+	if len(requests) != 3 {
+		t.Fatalf("Expected 3 pets requests, got %d", len(requests))
+	}
+
+	// The first named example wins, through its $ref
+	expected := `{
+  "name": "Rex",
+  "species": "dog",
+  "vaccinated": true
+}`
+	if body := requests[1].Body; body != expected {
+		t.Errorf("Unexpected createPet body:\n%s\nwant:\n%s", body, expected)
+	}
+
+	expectedVars := map[string]string{"species": "dog", "limit": "25"}
+	if !reflect.DeepEqual(requests[0].Vars, expectedVars) {
+		t.Errorf("Expected listPets variables %v, got %v", expectedVars, requests[0].Vars)
+	}
+	if petID := requests[2].Vars["petId"]; petID != "42" {
+		t.Errorf("Expected the referenced petId example, got %q", petID)
+	}
+
+	// The example of an object schema replaces its generated properties
+	expected = `{
+  "name": "Grace Hopper",
+  "phone": "+1-202-555-0199"
+}`
+	if body := files["owners"].Requests[0].Body; body != expected {
+		t.Errorf("Unexpected createOwner body:\n%s\nwant:\n%s", body, expected)
+	}
+}
+
+func TestGenerator_ExampleVariants(t *testing.T) {
+	files := generateExamples(t, Options{ExampleVariants: true})
+
+	var names, ids []string
+	for _, req := range files["pets"].Requests {
+		names = append(names, req.Name)
+		ids = append(ids, req.ID)
+	}
+
+	// Examples only given by an external value are skipped
+	expectedNames := []string{"List pets", "Register a pet (A vaccinated dog)", "Register a pet (A cat with its owner)", "Update a pet"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("Expected requests %v, got %v", expectedNames, names)
+	}
+	expectedIDs := []string{"listPets", "createPetDog", "createPetCat", "updatePet"}
+	if !reflect.DeepEqual(ids, expectedIDs) {
+		t.Errorf("Expected request IDs %v, got %v", expectedIDs, ids)
+	}
+}
+
+func TestGenerator_ParameterExample(t *testing.T) {
+	g := New(nil)
+
+	tests := []struct {
+		name     string
+		param    models.Parameter
+		expected interface{}
+	}{
+		{"example", models.Parameter{Name: "limit", In: "query", Type: "integer", Example: float64(10), XExample: float64(20)}, float64(10)},
+		{"first named example", models.Parameter{Name: "sort", In: "query", Schema: &models.SchemaObj{Type: "string"}, Examples: models.Examples{
+			{Name: "external", Example: models.Example{ExternalValue: "https://example.com/sort.txt"}},
+			{Name: "byName", Example: models.Example{Value: "name"}},
+			{Name: "byDate", Example: models.Example{Value: "date"}},
+		}}, "name"},
+		{"swagger v2 x-example", models.Parameter{Name: "status", In: "query", Type: "string", XExample: "sold"}, "sold"},
+		{"generated", models.Parameter{Name: "status", In: "query", Type: "string"}, "string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.paramExample(tt.param); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("paramExample() = %#v, want %#v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGenerator_ExampleValue(t *testing.T) {
+	schema := &models.SchemaObj{
+		Type: "object",
+		Properties: map[string]models.SchemaObj{
+			"name": {Type: "string"},
+			"tags": {Type: "array", Items: &models.SchemaObj{
+				Type:          "object",
+				Properties:    map[string]models.SchemaObj{"key": {Type: "string"}, "value": {Type: "string"}},
+				PropertyOrder: []string{"key", "value"},
+			}},
+		},
+		PropertyOrder: []string{"name", "tags"},
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(`{"zeta": 1, "tags": [{"value": "v", "key": "k"}], "alpha": 2, "name": "n"}`), &value); err != nil {
+		t.Fatalf("Failed to decode example: %v", err)
+	}
+
+	// Declared properties come first, in declaration order, the others sorted
+	expected := `{
+  "name": "n",
+  "tags": [
+    {
+      "key": "k",
+      "value": "v"
+    }
+  ],
+  "alpha": 2,
+  "zeta": 1
+}`
+	g := New(nil)
+	if got := g.formatExample(g.exampleValue(value, schema)); got != expected {
+		t.Errorf("Unexpected example:\n%s\nwant:\n%s", got, expected)
+	}
+}
@@ -0,0 +1,354 @@
+package http
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// FakeRule generates plausible values for the properties and parameters
+// named after one of Names. A name matches when it ends with the words of
+// one of Names, ignoring case and separators: "email" matches email,
+// customerEmail and billing_email but not emailVerified.
+type FakeRule struct {
+	Names []string
+	Value func(r *rand.Rand) interface{}
+}
+
+// FakeDictionary lists fake data rules by precedence. The first matching rule
+// whose value fits the schema of the property provides the value, so specific
+// names such as firstName come before generic ones such as name.
+type FakeDictionary []FakeRule
+
+// fakeData generates the values of Options.FakeData from a seeded source,
+// so that the same document and seed always give the same output
+type fakeData struct {
+	rand       *rand.Rand
+	dictionary FakeDictionary
+	params     map[string]interface{} // values of the parameters by location and name
+}
+
+// newFakeData creates the fake data generator of the options, nil when
+// fake data is disabled
+func newFakeData(options Options) *fakeData {
+	if !options.FakeData {
+		return nil
+	}
+	dictionary := options.FakeDictionary
+	if dictionary == nil {
+		dictionary = DefaultFakeDictionary()
+	}
+	return &fakeData{
+		rand:       rand.New(rand.NewSource(options.Seed)),
+		dictionary: dictionary,
+		params:     make(map[string]interface{}),
+	}
+}
+
+// fakeValue replaces a generated primitive value, or the items of an array of
+// primitives, with plausible data for the name of its property or parameter.
+// Values of the document, examples and defaults, are kept.
+func (g *Generator) fakeValue(name string, schema *models.SchemaObj, value interface{}) interface{} {
+	if g.fake == nil || schema == nil {
+		return value
+	}
+	if schema.Ref != "" {
+		if g.resolver == nil {
+			return value
+		}
+		resolved, err := g.resolver.ResolveSchema(schema)
+		if err != nil {
+			return value
+		}
+		schema = resolved
+	}
+	if _, isObject := value.(jsonObject); isObject || schema.Example != nil || schema.Default != nil {
+		return value
+	}
+
+	if items, ok := value.([]interface{}); ok && schema.Items != nil {
+		for i := range items {
+			items[i] = g.fakeValue(name, schema.Items, items[i])
+		}
+		return items
+	}
+
+	if fake, ok := g.fake.value(name, schema); ok {
+		return fake
+	}
+	return value
+}
+
+// fakeParam replaces the generated value of a parameter like fakeValue. The
+// parameters of the same name and location share their value, so that the
+// requests of a file can share their variables.
+func (g *Generator) fakeParam(param models.Parameter, schema *models.SchemaObj, value interface{}) interface{} {
+	if g.fake == nil {
+		return value
+	}
+	key := param.In + " " + param.Name
+	if fake, ok := g.fake.params[key]; ok {
+		return fake
+	}
+	value = g.fakeValue(param.Name, schema, value)
+	g.fake.params[key] = value
+	return value
+}
+
+// value returns a plausible value for a primitive schema: a random enum value,
+// a random value of its format, or the value of the first matching rule
+func (f *fakeData) value(name string, schema *models.SchemaObj) (interface{}, bool) {
+	if len(schema.Enum) > 0 {
+		return schema.Enum[f.rand.Intn(len(schema.Enum))], true
+	}
+	if schema.Pattern != "" {
+		return nil, false
+	}
+	switch schema.Type {
+	case "string", "integer", "number", "":
+	default:
+		return nil, false
+	}
+
+	if format, ok := fakeFormats[schema.Format]; ok && (schema.Type == "string" || schema.Type == "") {
+		return fitValue(format(f.rand), schema)
+	}
+
+	words := nameWords(name)
+	for _, rule := range f.dictionary {
+		if !rule.matches(words) {
+			continue
+		}
+		if value, ok := fitValue(rule.Value(f.rand), schema); ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// matches reports whether the words of a name end with one of the names of the rule
+func (rule FakeRule) matches(words []string) bool {
+	for _, name := range rule.Names {
+		want := strings.ToLower(strings.Join(nameWords(name), ""))
+		for i := range words {
+			if strings.Join(words[i:], "") == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nameWords splits a camelCase, snake_case or kebab-case name into lowercase words
+func nameWords(name string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if len(current) > 0 {
+				words = append(words, string(current))
+				current = nil
+			}
+			continue
+		case unicode.IsUpper(r) && len(current) > 0 &&
+			(!unicode.IsUpper(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			words = append(words, string(current))
+			current = nil
+		}
+		current = append(current, unicode.ToLower(r))
+	}
+	if len(current) > 0 {
+		words = append(words, string(current))
+	}
+	return words
+}
+
+// fitValue checks that a fake value has the type of a schema and meets its
+// bounds and length limits. Integral numbers fit integer schemas.
+func fitValue(value interface{}, schema *models.SchemaObj) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		if schema.Type != "string" && schema.Type != "" {
+			return nil, false
+		}
+		if len(v) < schema.MinLength || (schema.MaxLength > 0 && len(v) > schema.MaxLength) {
+			return nil, false
+		}
+		return v, true
+	case int:
+		return fitNumber(float64(v), schema, true)
+	case float64:
+		return fitNumber(v, schema, v == float64(int64(v)))
+	}
+	return nil, false
+}
+
+// fitNumber checks that a fake number fits an integer or number schema
+func fitNumber(value float64, schema *models.SchemaObj, integral bool) (interface{}, bool) {
+	switch {
+	case schema.Type == "integer" && integral:
+	case schema.Type == "number":
+	default:
+		return nil, false
+	}
+
+	if min, exclusive, ok := schemaBound(schema.Minimum, schema.ExclusiveMinimum); ok && (value < min || (exclusive && value == min)) {
+		return nil, false
+	}
+	if max, exclusive, ok := schemaBound(schema.Maximum, schema.ExclusiveMaximum); ok && (value > max || (exclusive && value == max)) {
+		return nil, false
+	}
+	if integral {
+		return int64(value), true
+	}
+	return value, true
+}
+
+// fakeFormats generates random values of well-known string formats
+var fakeFormats = map[string]func(r *rand.Rand) interface{}{
+	"uuid": func(r *rand.Rand) interface{} {
+		b := make([]byte, 16)
+		r.Read(b)
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+	},
+	"date-time": func(r *rand.Rand) interface{} {
+		return fakeTime(r).Format(time.RFC3339)
+	},
+	"date": func(r *rand.Rand) interface{} {
+		return fakeTime(r).Format("2006-01-02")
+	},
+	"email":     fakeEmail,
+	"idn-email": fakeEmail,
+	"ipv4": func(r *rand.Rand) interface{} {
+		return fmt.Sprintf("192.168.%d.%d", r.Intn(256), 1+r.Intn(254))
+	},
+	"uri": fakeURL,
+	"url": fakeURL,
+}
+
+// fakeTime returns a time of the years 2020 to 2025, to the second
+func fakeTime(r *rand.Rand) time.Time {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	return start.Add(time.Duration(r.Int63n(6*365*24*3600)) * time.Second)
+}
+
+// Word lists of the default dictionary
+var (
+	fakeFirstNames = []string{"Olivia", "Liam", "Emma", "Noah", "Ava", "Lucas", "Mia", "Ethan", "Sofia", "Mateo"}
+	fakeLastNames  = []string{"Smith", "Johnson", "Garcia", "Brown", "Miller", "Davis", "Martinez", "Wilson", "Anderson", "Taylor"}
+	fakeStreets    = []string{"Main Street", "Oak Avenue", "Maple Drive", "Park Road", "Cedar Lane", "Elm Street", "Lake View", "Hill Road"}
+	fakeCities     = []string{"Springfield", "Portland", "Austin", "Denver", "Madison", "Salem", "Boulder", "Savannah"}
+	fakeStates     = []string{"California", "Texas", "Oregon", "Colorado", "Georgia", "Ohio", "Vermont", "Nevada"}
+	fakeCountries  = []string{"United States", "Canada", "Germany", "France", "Spain", "Japan", "Brazil", "Australia"}
+	fakeCodes      = []string{"US", "CA", "DE", "FR", "ES", "JP", "BR", "AU"}
+	fakeCompanies  = []string{"Acme Corp", "Globex", "Initech", "Umbrella Ltd", "Stark Industries", "Wayne Enterprises", "Hooli", "Vandelay Industries"}
+	fakeProducts   = []string{"Wireless Mouse", "Coffee Mug", "Desk Lamp", "Notebook", "Water Bottle", "Backpack", "Headphones", "Running Shoes"}
+	fakeWords      = []string{"alpha", "bravo", "delta", "echo", "lima", "nova", "orbit", "pixel", "quartz", "sierra"}
+	fakeSentences  = []string{
+		"A short description of the item.",
+		"Ships within two business days.",
+		"Handle with care.",
+		"Updated after the last review.",
+		"Available in several colors.",
+	}
+	fakeTitles     = []string{"Quarterly Report", "Getting Started", "Release Notes", "Weekly Update", "Project Plan"}
+	fakeColors     = []string{"red", "green", "blue", "black", "white", "orange", "purple", "yellow"}
+	fakeCurrencies = []string{"USD", "EUR", "GBP", "JPY", "CAD"}
+	fakeFiles      = []string{"report.pdf", "photo.jpg", "notes.txt", "data.csv", "invoice.pdf"}
+)
+
+// pick returns a random element of a word list
+func pick(r *rand.Rand, words []string) interface{} {
+	return words[r.Intn(len(words))]
+}
+
+// fakeEmail returns an email address made of a first and a last name
+func fakeEmail(r *rand.Rand) interface{} {
+	return strings.ToLower(fmt.Sprintf("%s.%s@example.com", pick(r, fakeFirstNames), pick(r, fakeLastNames)))
+}
+
+// fakeURL returns a URL of the example.com domain
+func fakeURL(r *rand.Rand) interface{} {
+	return fmt.Sprintf("https://www.example.com/%s", pick(r, fakeWords))
+}
+
+// DefaultFakeDictionary returns the built-in fake data rules: person and
+// company names, contact details, addresses, prices, quantities and texts
+func DefaultFakeDictionary() FakeDictionary {
+	words := func(list []string) func(r *rand.Rand) interface{} {
+		return func(r *rand.Rand) interface{} { return pick(r, list) }
+	}
+
+	return FakeDictionary{
+		{Names: []string{"firstName", "givenName", "forename"}, Value: words(fakeFirstNames)},
+		{Names: []string{"lastName", "surname", "familyName"}, Value: words(fakeLastNames)},
+		{Names: []string{"username", "login", "nickname", "handle"}, Value: func(r *rand.Rand) interface{} {
+			return fmt.Sprintf("%s%d", strings.ToLower(pick(r, fakeFirstNames).(string)), 10+r.Intn(90))
+		}},
+		{Names: []string{"fileName"}, Value: words(fakeFiles)},
+		{Names: []string{"companyName", "company", "organization", "organisation", "employer"}, Value: words(fakeCompanies)},
+		{Names: []string{"productName", "product", "itemName"}, Value: words(fakeProducts)},
+		{Names: []string{"fullName", "displayName", "contactName", "name"}, Value: func(r *rand.Rand) interface{} {
+			return fmt.Sprintf("%s %s", pick(r, fakeFirstNames), pick(r, fakeLastNames))
+		}},
+		{Names: []string{"email", "emailAddress", "mail"}, Value: fakeEmail},
+		{Names: []string{"phone", "phoneNumber", "mobile", "telephone", "tel", "fax"}, Value: func(r *rand.Rand) interface{} {
+			return fmt.Sprintf("+1-202-555-%04d", r.Intn(10000))
+		}},
+		{Names: []string{"street", "streetAddress", "address", "addressLine1", "line1"}, Value: func(r *rand.Rand) interface{} {
+			return fmt.Sprintf("%d %s", 1+r.Intn(999), pick(r, fakeStreets))
+		}},
+		{Names: []string{"city", "town"}, Value: words(fakeCities)},
+		{Names: []string{"state", "province"}, Value: words(fakeStates)},
+		{Names: []string{"countryCode"}, Value: words(fakeCodes)},
+		{Names: []string{"country"}, Value: words(fakeCountries)},
+		{Names: []string{"zip", "zipCode", "postcode", "postalCode"}, Value: func(r *rand.Rand) interface{} {
+			return fmt.Sprintf("%05d", r.Intn(100000))
+		}},
+		{Names: []string{"price", "amount", "cost", "total", "subtotal", "balance", "fee"}, Value: func(r *rand.Rand) interface{} {
+			return float64(100+r.Intn(99900)) / 100
+		}},
+		{Names: []string{"currency", "currencyCode"}, Value: words(fakeCurrencies)},
+		{Names: []string{"quantity", "qty"}, Value: func(r *rand.Rand) interface{} {
+			return 1 + r.Intn(10)
+		}},
+		{Names: []string{"age"}, Value: func(r *rand.Rand) interface{} {
+			return 18 + r.Intn(63)
+		}},
+		{Names: []string{"birthDate", "dateOfBirth", "dob", "birthday"}, Value: func(r *rand.Rand) interface{} {
+			return time.Date(1950+r.Intn(55), time.Month(1+r.Intn(12)), 1+r.Intn(28), 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+		}},
+		{Names: []string{"description", "summary", "comment", "notes", "note", "bio", "about"}, Value: words(fakeSentences)},
+		{Names: []string{"title", "subject", "headline"}, Value: words(fakeTitles)},
+		{Names: []string{"url", "website", "homepage", "link", "uri"}, Value: fakeURL},
+		{Names: []string{"avatar", "image", "photo", "picture", "imageUrl", "photoUrl"}, Value: func(r *rand.Rand) interface{} {
+			return fmt.Sprintf("https://www.example.com/images/%d.jpg", 1+r.Intn(100))
+		}},
+		{Names: []string{"color", "colour"}, Value: words(fakeColors)},
+		{Names: []string{"latitude", "lat"}, Value: func(r *rand.Rand) interface{} {
+			return float64(r.Intn(180000000)-90000000) / 1e6
+		}},
+		{Names: []string{"longitude", "lng", "lon"}, Value: func(r *rand.Rand) interface{} {
+			return float64(r.Intn(360000000)-180000000) / 1e6
+		}},
+		{Names: []string{"ip", "ipAddress"}, Value: fakeFormats["ipv4"]},
+		{Names: []string{"password"}, Value: func(r *rand.Rand) interface{} {
+			return fmt.Sprintf("S3cret!%04d", r.Intn(10000))
+		}},
+		{Names: []string{"sku"}, Value: func(r *rand.Rand) interface{} {
+			return fmt.Sprintf("SKU-%05d", r.Intn(100000))
+		}},
+		{Names: []string{"tag", "tags", "category", "label"}, Value: words(fakeWords)},
+		{Names: []string{"id"}, Value: func(r *rand.Rand) interface{} {
+			return 1 + r.Intn(10000)
+		}},
+	}
+}
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"math/rand"
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/adapters/swagger"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+func TestNameWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []string
+	}{
+		{"firstName", []string{"first", "name"}},
+		{"billing_email", []string{"billing", "email"}},
+		{"X-Request-ID", []string{"x", "request", "id"}},
+		{"HTTPStatusCode", []string{"http", "status", "code"}},
+		{"addressLine1", []string{"address", "line1"}},
+	}
+
+	for _, tt := range tests {
+		if got := nameWords(tt.name); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("nameWords(%q) = %v, want %v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestFakeRule_Matches(t *testing.T) {
+	rule := FakeRule{Names: []string{"email", "phoneNumber"}}
+
+	for name, expected := range map[string]bool{
+		"email":          true,
+		"customerEmail":  true,
+		"billing_email":  true,
+		"phone_number":   true,
+		"emailVerified":  false,
+		"phone":          false,
+		"customerEmails": false,
+	} {
+		if got := rule.matches(nameWords(name)); got != expected {
+			t.Errorf("matches(%q) = %v, want %v", name, got, expected)
+		}
+	}
+}
+
+func TestFakeData_Value(t *testing.T) {
+	fake := newFakeData(Options{FakeData: true, Seed: 7})
+
+	tests := []struct {
+		name   string
+		schema models.SchemaObj
+		match  string
+	}{
+		{"email", models.SchemaObj{Type: "string"}, `^[a-z]+\.[a-z]+@example\.com$`},
+		{"createdAt", models.SchemaObj{Type: "string", Format: "date-time"}, `^20[0-9]{2}-[0-9]{2}-[0-9]{2}T[0-9:]{8}Z$`},
+		{"id", models.SchemaObj{Type: "string", Format: "uuid"}, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`},
+		{"contactPhone", models.SchemaObj{Type: "string"}, `^\+1-202-555-[0-9]{4}$`},
+		{"price", models.SchemaObj{Type: "number"}, `^[0-9]+(\.[0-9]+)?$`},
+		{"status", models.SchemaObj{Type: "string", Enum: []interface{}{"a", "b"}}, `^[ab]$`},
+	}
+
+	for _, tt := range tests {
+		value, ok := fake.value(tt.name, &tt.schema)
+		if !ok {
+			t.Errorf("Expected a fake value for %s", tt.name)
+			continue
+		}
+		if s := queryString(value); !regexp.MustCompile(tt.match).MatchString(s) {
+			t.Errorf("Fake value of %s = %q, expected to match %s", tt.name, s, tt.match)
+		}
+	}
+
+	for name, schema := range map[string]models.SchemaObj{
+		"unknown":    {Type: "string"},
+		"code":       {Type: "string", Pattern: `^[A-Z]{3}$`},
+		"price":      {Type: "integer", Maximum: floatPtr(0)},
+		"age":        {Type: "integer", Minimum: floatPtr(100)},
+		"city":       {Type: "string", MaxLength: 3},
+		"address":    {Type: "object"},
+		"firstNames": {Type: "array"},
+	} {
+		if value, ok := fake.value(name, &schema); ok {
+			t.Errorf("Expected no fake value for %s, got %v", name, value)
+		}
+	}
+}
+
+func TestGenerator_FakeData(t *testing.T) {
+	schema := &models.SchemaObj{
+		Type: "object",
+		Properties: map[string]models.SchemaObj{
+			"firstName": {Type: "string"},
+			"nickname":  {Type: "string", Example: "Rex"},
+			"city":      {Type: "string", Default: "Paris"},
+			"tags":      {Type: "array", MinItems: 2, Items: &models.SchemaObj{Type: "string"}},
+			"vip":       {Type: "boolean"},
+		},
+		PropertyOrder: []string{"firstName", "nickname", "city", "tags", "vip"},
+	}
+
+	generate := func(options Options) string {
+		return NewWithOptions(nil, options).generateSchemaExample(schema)
+	}
+
+	first := generate(Options{FakeData: true, Seed: 1})
+	if again := generate(Options{FakeData: true, Seed: 1}); again != first {
+		t.Errorf("Expected the same seed to give the same values:\n%s\n%s", first, again)
+	}
+	if other := generate(Options{FakeData: true, Seed: 2}); other == first {
+		t.Errorf("Expected another seed to give other values:\n%s", other)
+	}
+
+	pattern := regexp.MustCompile(`^\{
+  "firstName": "[A-Z][a-z]+",
+  "nickname": "Rex",
+  "city": "Paris",
+  "tags": \[
+    "[a-z]+",
+    "[a-z]+"
+  \],
+  "vip": false
+\}$`)
+	if !pattern.MatchString(first) {
+		t.Errorf("Unexpected fake body:\n%s", first)
+	}
+
+	dictionary := FakeDictionary{{Names: []string{"name"}, Value: func(r *rand.Rand) interface{} { return "Custom" }}}
+	custom := generate(Options{FakeData: true, FakeDictionary: dictionary})
+	if !regexp.MustCompile(`"firstName": "Custom"`).MatchString(custom) {
+		t.Errorf("Expected the custom dictionary to be used:\n%s", custom)
+	}
+
+	if plain := generate(Options{}); !regexp.MustCompile(`"firstName": "string"`).MatchString(plain) {
+		t.Errorf("Expected no fake data without the option:\n%s", plain)
+	}
+}
+
+func TestGenerator_FakeDataAcrossTags(t *testing.T) {
+	data, err := os.ReadFile("../../../test/samples/chaining.yaml")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	parser := swagger.New()
+	doc, err := parser.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse swagger: %v", err)
+	}
+
+	// The files of several tags share the seeded source, whatever the order
+	// of the operations map
+	var first map[string]*models.HTTPFile
+	for i := 0; i < 20; i++ {
+		files, err := NewWithOptions(parser, Options{FakeData: true, Seed: 42}).Generate(doc, parser.GetBaseURL(doc))
+		if err != nil {
+			t.Fatalf("Failed to generate HTTP files: %v", err)
+		}
+		if len(files) < 2 {
+			t.Fatalf("Expected several files, got %d", len(files))
+		}
+		if first == nil {
+			first = files
+		} else if !reflect.DeepEqual(files, first) {
+			t.Fatalf("Expected the same seed to give the same files on every run")
+		}
+	}
+}
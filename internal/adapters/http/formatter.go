@@ -2,144 +2,353 @@ package http
 
 import (
 	"fmt"
-	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
 )
 
-// Formatter handles formatting HTTP files into the .http format
-type Formatter struct{}
+// Formatter handles formatting HTTP files into the .http format of an HTTP client
+type Formatter struct {
+	dialect dialect
+}
 
 // FormatHTTPFile formats an HTTPFile into a string representation in .http format
 func (f *Formatter) FormatHTTPFile(file *models.HTTPFile) string {
 	var builder strings.Builder
 
 	// Add base URL and global variables
-	builder.WriteString(f.formatGlobalVars(file.GlobalVars))
-	builder.WriteString("\n")
+	vars, captures := f.dialect.fileVars(file)
+	if len(vars) > 0 {
+		builder.WriteString(f.formatGlobalVars(vars, file.VarComments))
+		builder.WriteString("\n")
+	}
+
+	// Variables the file provides to its requests, left out of the requests
+	defined := make(map[string]bool, len(vars))
+	for name := range vars {
+		defined[name] = true
+	}
+	for name := range file.ResponseVars {
+		defined[name] = true
+	}
+	for name := range file.SharedVars {
+		defined[name] = true
+	}
+	for _, req := range file.Requests {
+		for _, capture := range req.Captures {
+			defined[capture.Variable] = true
+		}
+	}
+
+	// Those not defined in the file are global variables captured by response
+	// handlers, which requests can be sent before
+	globals := make(map[string]bool)
+	if f.dialect.scripts {
+		for name := range defined {
+			if _, ok := vars[name]; !ok {
+				globals[name] = true
+			}
+		}
+	}
+
+	// List the generated requests, so that merges tell them from those added by hand
+	if marker := generatedMarker(file.Requests); marker != "" {
+		builder.WriteString(marker)
+		builder.WriteString("\n")
+	}
 
-	// Add requests
+	// Add requests, with the values their responses provide to the other requests
 	for i, req := range file.Requests {
 		if i > 0 {
 			builder.WriteString("\n")
 		}
-		builder.WriteString(f.FormatHTTPRequest(req))
+		if extra := captures[req.ID]; len(extra) > 0 {
+			req.Captures = appendCaptures(req.Captures, extra)
+		}
+		builder.WriteString(f.formatRequest(req, defined, globals))
 		builder.WriteString("\n")
 	}
 
 	return builder.String()
 }
 
+// appendCaptures adds captures to those of a request, except those of
+// variables the request already captures
+func appendCaptures(captures, extra []models.ResponseCapture) []models.ResponseCapture {
+	all := append([]models.ResponseCapture{}, captures...)
+	for _, capture := range extra {
+		if !capturesVariable(captures, capture.Variable) {
+			all = append(all, capture)
+		}
+	}
+	return all
+}
+
+// capturesVariable reports whether captures store a variable
+func capturesVariable(captures []models.ResponseCapture, name string) bool {
+	for _, capture := range captures {
+		if capture.Variable == name {
+			return true
+		}
+	}
+	return false
+}
+
+// generatedMarker lists the IDs of the generated requests in a # @generated
+// comment, with the fingerprint of their generated body for those that have
+// one, e.g. # @generated createPet=4ac9712a getPet
+func generatedMarker(requests []models.HTTPRequest) string {
+	var entries []string
+	for _, req := range requests {
+		switch {
+		case req.Generated == "" || req.ID == "":
+		case requestBody(req) == "" && req.BodyFile == "":
+			entries = append(entries, req.ID)
+		default:
+			entries = append(entries, req.ID+"="+req.Generated)
+		}
+	}
+	if len(entries) == 0 {
+		return ""
+	}
+	return "# @generated " + strings.Join(entries, " ") + "\n"
+}
+
 // FormatHTTPRequest formats a single HTTPRequest into a string representation
 func (f *Formatter) FormatHTTPRequest(req models.HTTPRequest) string {
+	return f.formatRequest(req, nil, nil)
+}
+
+// formatRequest formats a request, with the values of its variables except
+// those defined by the file. The values of global variables are defaults
+// set by a pre-request script, until a response handler captures them.
+func (f *Formatter) formatRequest(req models.HTTPRequest, defined, globals map[string]bool) string {
 	var builder strings.Builder
 
 	// Add request name as a comment
 	builder.WriteString(fmt.Sprintf("### %s\n", req.Name))
 
+	// Add request variables, such as the values of path and query parameters
+	for _, name := range sortedKeys(req.Vars) {
+		if !defined[name] {
+			builder.WriteString(fmt.Sprintf("@%s = %s\n", name, req.Vars[name]))
+		}
+	}
+
+	// Add request name so that other requests can reference its response
+	if req.ID != "" {
+		builder.WriteString(fmt.Sprintf("# @name %s\n", req.ID))
+	}
+
+	// Mark deprecated requests, such as those of removed operations
+	if req.Deprecated {
+		builder.WriteString("# @deprecated\n")
+	}
+
 	// Add description if present
 	if req.Description != "" {
 		builder.WriteString(fmt.Sprintf("# %s\n", req.Description))
 	}
 
+	// Add a pre-request script defining the global variables not captured
+	// yet, followed by the statements written by hand
+	var preScript strings.Builder
+	for _, name := range sortedKeys(req.Vars) {
+		if globals[name] {
+			preScript.WriteString(fmt.Sprintf("    if (client.global.get(%q) == null) client.global.set(%q, %q);\n", name, name, req.Vars[name]))
+		}
+	}
+	if req.PreScript != "" {
+		preScript.WriteString(req.PreScript + "\n")
+	}
+	if preScript.Len() > 0 {
+		builder.WriteString("< {%\n")
+		builder.WriteString(preScript.String())
+		builder.WriteString("%}\n")
+	}
+
 	// Add method and URL
-	builder.WriteString(fmt.Sprintf("%s {{baseUrl}}%s\n", req.Method, req.Path))
+	builder.WriteString(fmt.Sprintf("%s %s\n", req.Method, requestURL(req.Path)))
 
-	// Add headers
-	for name, value := range req.Headers {
-		builder.WriteString(fmt.Sprintf("%s: %s\n", name, value))
+	// Add headers, sorted by name
+	for _, name := range sortedKeys(req.Headers) {
+		builder.WriteString(fmt.Sprintf("%s: %s\n", name, req.Headers[name]))
 	}
 
-	// Add body if present
-	if req.Body != "" {
+	// Add body if present, or the file it is read from
+	if body := requestBody(req); body != "" {
 		builder.WriteString("\n")
-		builder.WriteString(req.Body)
+		builder.WriteString(body)
+		builder.WriteString("\n")
+	} else if req.BodyFile != "" {
+		builder.WriteString(fmt.Sprintf("\n< %s\n", req.BodyFile))
+	}
+
+	// Add httpyac assertions, and the response lines written by hand
+	var assertions strings.Builder
+	if req.Expect != nil && f.dialect.assertions == assertLines {
+		assertions.WriteString(httpyacAssertions(req.Expect))
+	}
+	for _, line := range req.Assertions {
+		assertions.WriteString(line + "\n")
+	}
+	if assertions.Len() > 0 {
 		builder.WriteString("\n")
+		builder.WriteString(assertions.String())
+	}
+
+	// Add a response handler storing captured values in global variables
+	// and testing the response, followed by the statements written by hand
+	var script strings.Builder
+	if f.dialect.scripts {
+		for _, capture := range req.Captures {
+			script.WriteString(fmt.Sprintf("    client.global.set(%q, %s);\n", capture.Variable, scriptPath(capture.Path)))
+		}
+		if req.Expect != nil && f.dialect.assertions == assertTests {
+			script.WriteString(jetBrainsTests(req.Expect))
+		}
+	}
+	if req.Script != "" {
+		script.WriteString(req.Script + "\n")
+	}
+	if script.Len() > 0 {
+		builder.WriteString("\n> {%\n")
+		builder.WriteString(script.String())
+		builder.WriteString("%}\n")
 	}
 
 	return builder.String()
 }
 
-// formatGlobalVars formats global variables for the .http file
-func (f *Formatter) formatGlobalVars(vars map[string]string) string {
+// jetBrainsTests asserts the expected response with JetBrains HTTP Client tests
+func jetBrainsTests(expect *models.ResponseExpectation) string {
 	var builder strings.Builder
 
-	// Add comment header for variables
-	if len(vars) > 0 {
-		builder.WriteString("# Global variables\n")
+	test := func(name string, assertions ...string) {
+		builder.WriteString(fmt.Sprintf("    client.test(%q, function() {\n", name))
+		for _, assertion := range assertions {
+			builder.WriteString(fmt.Sprintf("        client.assert(%s);\n", assertion))
+		}
+		builder.WriteString("    });\n")
 	}
 
-	// Add each variable
-	for name, value := range vars {
-		builder.WriteString(fmt.Sprintf("@%s = %s\n", name, value))
+	if low, high, ok := statusRange(expect.Status); ok {
+		condition := fmt.Sprintf("response.status === %d", low)
+		if high != low+1 {
+			condition = fmt.Sprintf("response.status >= %d && response.status < %d", low, high)
+		}
+		test("Status is "+expect.Status, fmt.Sprintf(`%s, "Expected status %s, got " + response.status`, condition, expect.Status))
+	}
+	if expect.ContentType != "" {
+		test("Content-Type is "+expect.ContentType, fmt.Sprintf(`response.contentType.mimeType === %q, "Expected %s, got " + response.contentType.mimeType`, expect.ContentType, expect.ContentType))
+	}
+	if len(expect.Fields) > 0 {
+		assertions := make([]string, 0, len(expect.Fields))
+		for _, field := range expect.Fields {
+			assertions = append(assertions, fmt.Sprintf(`response.body.hasOwnProperty(%q), "Missing field %s"`, field, field))
+		}
+		test("Body has required fields", assertions...)
 	}
 
 	return builder.String()
 }
 
-/*
-// formatNamedRequest formats a request with a name and response capture
-
-	func (f *Formatter) formatNamedRequest(req models.HTTPRequest) string {
-		var builder strings.Builder
-
-		// Add request name as a comment
-		builder.WriteString(fmt.Sprintf("### %s\n", req.Name))
-
-		// Add request name for response capture
-		builder.WriteString(fmt.Sprintf("# @name %s\n", getVarName(req.Name)))
+// httpyacAssertions asserts the expected response with httpyac ?? lines
+func httpyacAssertions(expect *models.ResponseExpectation) string {
+	var builder strings.Builder
 
-		// Add description if present
-		if req.Description != "" {
-			builder.WriteString(fmt.Sprintf("# %s\n", req.Description))
+	if low, high, ok := statusRange(expect.Status); ok {
+		if high == low+1 {
+			builder.WriteString(fmt.Sprintf("?? status == %d\n", low))
+		} else {
+			builder.WriteString(fmt.Sprintf("?? status >= %d\n?? status < %d\n", low, high))
 		}
+	}
+	if expect.ContentType != "" {
+		builder.WriteString(fmt.Sprintf("?? header content-type includes %s\n", expect.ContentType))
+	}
+	for _, field := range expect.Fields {
+		builder.WriteString(fmt.Sprintf("?? js response.parsedBody%s exists\n", jsProperty(field)))
+	}
 
-		// Add method and URL
-		builder.WriteString(fmt.Sprintf("%s {{baseUrl}}%s\n", req.Method, req.Path))
+	return builder.String()
+}
 
-		// Add headers
-		for name, value := range req.Headers {
-			builder.WriteString(fmt.Sprintf("%s: %s\n", name, value))
-		}
+// statusRange returns the status codes matched by a response code, from low
+// included to high excluded: 201 matches 201 and 2XX matches 200 to 299
+func statusRange(code string) (int, int, bool) {
+	if len(code) == 3 && strings.HasSuffix(strings.ToUpper(code), "XX") && code[0] >= '1' && code[0] <= '5' {
+		low := int(code[0]-'0') * 100
+		return low, low + 100, true
+	}
+	status, err := strconv.Atoi(code)
+	if err != nil {
+		return 0, 0, false
+	}
+	return status, status + 1, true
+}
 
-		// Add body if present
-		if req.Body != "" {
-			builder.WriteString("\n")
-			builder.WriteString(req.Body)
-			builder.WriteString("\n")
-		}
+// jsProperty returns a JavaScript property accessor, e.g. .id or ["first-name"]
+func jsProperty(name string) string {
+	if name != "" && identifier(name) == name && (name[0] < '0' || name[0] > '9') {
+		return "." + name
+	}
+	return fmt.Sprintf("[%q]", name)
+}
 
-		return builder.String()
+// requestURL prefixes paths with the base URL. Absolute URLs, such as
+// those of OAuth2 token endpoints, are used as they are.
+func requestURL(path string) string {
+	if strings.HasPrefix(path, "/") {
+		return "{{baseUrl}}" + path
 	}
-*/
-func getVarName(name string) string {
-	// 1) normalize spaces & hyphens to underscores
-	s := strings.ReplaceAll(name, " ", "_")
-	s = strings.ReplaceAll(s, "-", "_")
+	return path
+}
 
-	// 2) insert underscore between a lowercase/digit and uppercase (camelCase boundary)
-	re := regexp.MustCompile(`([a-z0-9])([A-Z])`)
-	s = re.ReplaceAllString(s, `${1}_${2}`)
+// formatGlobalVars formats global variables for the .http file, each preceded
+// by its comment if any
+func (f *Formatter) formatGlobalVars(vars, comments map[string]string) string {
+	var builder strings.Builder
 
-	// 3) lowercase everything
-	s = strings.ToLower(s)
+	// Add comment header for variables
+	if len(vars) > 0 {
+		builder.WriteString("# Global variables\n")
+	}
 
-	// 4) strip out any remaining non-alphanumeric/_, and ensure it doesn't start with a digit
-	var result strings.Builder
-	for i, ch := range s {
-		if (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9') || ch == '_' {
-			if i == 0 && (ch >= '0' && ch <= '9') {
-				result.WriteRune('_')
-			}
-			result.WriteRune(ch)
+	// Add each variable, sorted by name
+	for _, name := range sortedKeys(vars) {
+		if comment := comments[name]; comment != "" {
+			builder.WriteString(fmt.Sprintf("# %s: %s\n", name, comment))
 		}
+		builder.WriteString(fmt.Sprintf("@%s = %s\n", name, vars[name]))
 	}
 
-	return result.String()
+	return builder.String()
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
-// NewFormatter creates a new Formatter instance
+// NewFormatter creates a new Formatter instance for the JetBrains HTTP Client
 func NewFormatter() *Formatter {
-	return &Formatter{}
+	return NewDialectFormatter(DialectJetBrains)
+}
+
+// NewDialectFormatter creates a new Formatter instance for a dialect, see
+// ValidDialect. Unknown dialects get the JetBrains HTTP Client syntax.
+func NewDialectFormatter(name string) *Formatter {
+	d, ok := dialects[name]
+	if !ok {
+		d = dialects[DialectJetBrains]
+	}
+	return &Formatter{dialect: d}
 }
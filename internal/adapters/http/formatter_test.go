@@ -69,6 +69,53 @@ func TestFormatter_FormatHTTPRequest(t *testing.T) {
 				"Accept: application/json",
 			},
 		},
+		{
+			name: "named request",
+			request: models.HTTPRequest{
+				Name:        "Create Pet",
+				ID:          "createPet",
+				Method:      "POST",
+				Path:        "/pets",
+				Headers:     map[string]string{},
+				Description: "Create a new pet",
+			},
+			expected: []string{
+				"### Create Pet\n# @name createPet\n# Create a new pet\n",
+				"POST {{baseUrl}}/pets",
+			},
+		},
+		{
+			name: "deprecated request",
+			request: models.HTTPRequest{
+				Name:       "Get Pet",
+				ID:         "getPet",
+				Method:     "GET",
+				Path:       "/pets/{{petId}}",
+				Headers:    map[string]string{},
+				Deprecated: true,
+			},
+			expected: []string{
+				"### Get Pet\n# @name getPet\n# @deprecated\n",
+				"GET {{baseUrl}}/pets/{{petId}}",
+			},
+		},
+		{
+			name: "absolute URL with response capture",
+			request: models.HTTPRequest{
+				Name:   "Get token",
+				Method: "POST",
+				Path:   "https://auth.example.com/token",
+				Headers: map[string]string{
+					"Content-Type": "application/x-www-form-urlencoded",
+				},
+				Body:     "grant_type=client_credentials",
+				Captures: []models.ResponseCapture{{Variable: "authToken", Path: "access_token"}},
+			},
+			expected: []string{
+				"POST https://auth.example.com/token\n",
+				"grant_type=client_credentials\n\n> {%\n    client.global.set(\"authToken\", response.body.access_token);\n%}\n",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -95,11 +142,13 @@ func TestFormatter_FormatHTTPFile(t *testing.T) {
 			"authToken":  "your_auth_token",
 			"apiVersion": "v1",
 		},
+		ResponseVars: map[string]models.ResponseRef{"itemId": {Request: "createItem", Path: "id"}},
 		Requests: []models.HTTPRequest{
 			{
 				Name:   "Get All Items",
 				Method: "GET",
-				Path:   "/items",
+				Path:   "/items?limit={{limit}}&version={{apiVersion}}",
+				Vars:   map[string]string{"limit": "10", "apiVersion": "v2", "itemId": "1"},
 				Headers: map[string]string{
 					"Accept": "application/json",
 				},
@@ -126,9 +175,8 @@ func TestFormatter_FormatHTTPFile(t *testing.T) {
 		"@baseUrl = http://api.example.com",
 		"@authToken = your_auth_token",
 		"@apiVersion = v1",
-		"### Get All Items",
-		"# Get all items",
-		"GET {{baseUrl}}/items",
+		"### Get All Items\n@limit = 10\n# Get all items\n",
+		"GET {{baseUrl}}/items?limit={{limit}}&version={{apiVersion}}",
 		"Accept: application/json",
 		"### Create Item",
 		"# Create a new item",
@@ -143,46 +191,76 @@ func TestFormatter_FormatHTTPFile(t *testing.T) {
 			t.Errorf("Expected result to contain '%s', but it was not found.\nResult: %s", expected, result)
 		}
 	}
+
+	// Variables of the file are not redefined by the requests
+	for _, unexpected := range []string{"@apiVersion = v2", "@itemId = 1"} {
+		if strings.Contains(result, unexpected) {
+			t.Errorf("Expected result not to contain '%s'.\nResult: %s", unexpected, result)
+		}
+	}
 }
 
-func TestGetVarName(t *testing.T) {
+func TestFormatter_Assertions(t *testing.T) {
+	request := models.HTTPRequest{
+		Name:    "Create Pet",
+		Method:  "POST",
+		Path:    "/pets",
+		Headers: map[string]string{},
+		Expect: &models.ResponseExpectation{
+			Status:      "2XX",
+			ContentType: "application/json",
+			Fields:      []string{"id", "first-name"},
+		},
+	}
+
 	tests := []struct {
-		name     string
-		input    string
+		dialect  string
 		expected string
 	}{
 		{
-			name:     "simple name",
-			input:    "Get Pets",
-			expected: "get_pets",
-		},
-		{
-			name:     "with special characters",
-			input:    "Create Item #123",
-			expected: "create_item_123",
-		},
-		{
-			name:     "starts with number",
-			input:    "123 Test",
-			expected: "_123_test",
+			dialect: DialectJetBrains,
+			expected: `### Create Pet
+POST {{baseUrl}}/pets
+
+> {%
+    client.test("Status is 2XX", function() {
+        client.assert(response.status >= 200 && response.status < 300, "Expected status 2XX, got " + response.status);
+    });
+    client.test("Content-Type is application/json", function() {
+        client.assert(response.contentType.mimeType === "application/json", "Expected application/json, got " + response.contentType.mimeType);
+    });
+    client.test("Body has required fields", function() {
+        client.assert(response.body.hasOwnProperty("id"), "Missing field id");
+        client.assert(response.body.hasOwnProperty("first-name"), "Missing field first-name");
+    });
+%}
+`,
 		},
 		{
-			name:     "camelCase",
-			input:    "getPetById",
-			expected: "get_pet_by_id",
+			dialect: DialectHTTPYac,
+			expected: `### Create Pet
+POST {{baseUrl}}/pets
+
+?? status >= 200
+?? status < 300
+?? header content-type includes application/json
+?? js response.parsedBody.id exists
+?? js response.parsedBody["first-name"] exists
+`,
 		},
 		{
-			name:     "with hyphens",
-			input:    "test-name-with-hyphens",
-			expected: "test_name_with_hyphens",
+			dialect: DialectRESTClient,
+			expected: `### Create Pet
+POST {{baseUrl}}/pets
+`,
 		},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := getVarName(tt.input)
-			if result != tt.expected {
-				t.Errorf("getVarName(%s) = %s, want %s", tt.input, result, tt.expected)
+		t.Run(tt.dialect, func(t *testing.T) {
+			formatter := NewDialectFormatter(tt.dialect)
+			if got := formatter.FormatHTTPRequest(request); got != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, got)
 			}
 		})
 	}
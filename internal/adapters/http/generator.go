@@ -3,15 +3,75 @@ package http
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/edgardnogueira/swagger-to-http-file/internal/application/parser"
 	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
 )
 
+// Options controls how requests are generated
+type Options struct {
+	// Branches lists the preferred oneOf/anyOf branches, matched against the
+	// referenced schema name, the schema title or a discriminator value
+	Branches []string
+
+	// BranchVariants emits one request per oneOf/anyOf branch of a request body
+	BranchVariants bool
+
+	// ExampleVariants emits one request per named example of a request body,
+	// titled with the summary of the example
+	ExampleVariants bool
+
+	// RequiredQueryOnly leaves optional query parameters out of the request URL
+	RequiredQueryOnly bool
+
+	// SortBy orders the requests of each file: SortSource (the default) keeps
+	// the order of the document, SortPath, SortMethod and SortOperationID sort them
+	SortBy string
+
+	// Assertions sets the documented success response of each request, which
+	// formatters turn into response assertions
+	Assertions bool
+
+	// FakeData fills generated values with plausible data, such as names,
+	// addresses and prices, chosen from the names and formats of properties
+	// and parameters. Values given by the document are kept.
+	FakeData bool
+
+	// Seed seeds the fake data, the same seed always gives the same values
+	Seed int64
+
+	// FakeDictionary replaces the rules choosing fake data from names,
+	// DefaultFakeDictionary when nil
+	FakeDictionary FakeDictionary
+}
+
+// Request orders accepted by Options.SortBy
+const (
+	SortSource      = "source"
+	SortPath        = "path"
+	SortMethod      = "method"
+	SortOperationID = "operationId"
+)
+
+// ValidSortBy reports whether sortBy is a supported request order
+func ValidSortBy(sortBy string) bool {
+	switch sortBy {
+	case "", SortSource, SortPath, SortMethod, SortOperationID:
+		return true
+	}
+	return false
+}
+
 // Generator implements the application.HTTPGenerator interface
 type Generator struct {
-	parser parser.SwaggerParser
+	parser   parser.SwaggerParser
+	options  Options
+	resolver parser.RefResolver
+	schemes  map[string]models.SecurityScheme
+	fake     *fakeData
+	err      error
 }
 
 // Generate creates HTTP files from a Swagger document
@@ -20,69 +80,176 @@ func (g *Generator) Generate(doc *models.SwaggerDoc, baseURL string) (map[string
 		return nil, fmt.Errorf("swagger document is nil")
 	}
 
+	// References are resolved against the document being generated
+	g.resolver = g.parser.Resolver(doc)
+	g.schemes = securitySchemes(doc)
+	g.fake = newFakeData(g.options)
+	g.err = nil
+
 	// Extract global variables
 	globalVars := g.ExtractGlobalVars(doc)
 
 	// Extract operations by tag
 	operations := g.parser.ExtractOperations(doc)
 
-	// Create HTTP files per tag
+	// Server variables, including those of path and operation servers
+	serverVars, varComments := serverVariables(doc, operations)
+	for name, value := range serverVars {
+		if _, exists := globalVars[name]; !exists {
+			globalVars[name] = value
+		}
+	}
+
+	// Create HTTP files per tag, in sorted order: the files draw their fake
+	// data from one seeded source
 	files := make(map[string]*models.HTTPFile)
+	chained := make(map[string]fileOps)
 
-	for tag, ops := range operations {
+	for _, tag := range sortedTags(operations) {
+		ops := operations[tag]
 		HTTPFile := &models.HTTPFile{
-			BaseURL:    baseURL,
-			GlobalVars: globalVars,
-			Requests:   []models.HTTPRequest{},
-			Tag:        tag,
+			BaseURL:     baseURL,
+			GlobalVars:  globalVars,
+			VarComments: varComments,
+			Requests:    []models.HTTPRequest{},
+			Tag:         tag,
 		}
 
-		// Generate requests for each operation
-		for _, op := range ops {
+		// Generate requests for each operation, remembering where each one starts
+		ops = g.sortOperations(ops)
+		first := make([]int, len(ops))
+		for i, op := range ops {
 			request := g.GenerateRequest(op, baseURL)
+			first[i] = len(HTTPFile.Requests)
+
+			if g.options.ExampleVariants {
+				if variants := g.exampleVariants(op, request); len(variants) > 0 {
+					HTTPFile.Requests = append(HTTPFile.Requests, variants...)
+					continue
+				}
+			}
+
+			if g.options.BranchVariants {
+				if variants := g.bodyVariants(op, request); len(variants) > 0 {
+					HTTPFile.Requests = append(HTTPFile.Requests, variants...)
+					continue
+				}
+			}
+
 			HTTPFile.Requests = append(HTTPFile.Requests, request)
 		}
 
+		// Path parameters reference the responses of the requests creating them
+		uniqueIDs(HTTPFile.Requests)
+		ids := make([]string, len(ops))
+		for i := range ops {
+			ids[i] = HTTPFile.Requests[first[i]].ID
+		}
+		g.addChainVars(HTTPFile, ops, ids)
+		scopeRequestVars(HTTPFile)
+
 		files[tag] = HTTPFile
+		chained[tag] = fileOps{ops: ops, ids: ids}
+	}
+
+	// Path parameters also reference the requests of other files creating them
+	g.shareChainVars(files, chained)
+
+	// OAuth2 and OpenID Connect token requests go into their own file
+	g.addAuthFile(files, baseURL, globalVars)
+
+	// Generated requests are told from those added by hand when merging
+	for _, file := range files {
+		for i := range file.Requests {
+			file.Requests[i].Generated = bodyFingerprint(file.Requests[i])
+		}
+	}
+
+	if g.err != nil {
+		return nil, g.err
 	}
 
 	return files, nil
 }
 
+// sortOperations orders operations as requested by Options.SortBy. The sort is
+// stable, so operations comparing equal keep the order of the document.
+func (g *Generator) sortOperations(ops []models.OperationInfo) []models.OperationInfo {
+	var key func(op models.OperationInfo) string
+	switch g.options.SortBy {
+	case SortPath:
+		key = func(op models.OperationInfo) string { return op.Path }
+	case SortMethod:
+		key = func(op models.OperationInfo) string { return op.Method }
+	case SortOperationID:
+		key = func(op models.OperationInfo) string { return op.Operation.OperationID }
+	default:
+		return ops
+	}
+
+	sorted := make([]models.OperationInfo, len(ops))
+	copy(sorted, ops)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return key(sorted[i]) < key(sorted[j])
+	})
+	return sorted
+}
+
 // GenerateRequest creates a single HTTP request from an operation
 func (g *Generator) GenerateRequest(op models.OperationInfo, baseURL string) models.HTTPRequest {
 	// Format path with parameters
 	path := g.FormatPath(op.Path, op.Parameters)
 
+	// Path and operation servers replace the base URL
+	if len(op.Servers) > 0 {
+		path = strings.TrimSuffix(serverURL(op.Servers[0]), "/") + path
+	}
+
 	// Create request
 	request := models.HTTPRequest{
 		Name:        generateRequestName(op),
+		ID:          requestID(op),
 		Method:      op.Method,
 		Path:        path,
 		Headers:     extractHeaders(op),
-		Body:        generateRequestBody(op),
 		Description: generateDescription(op),
-		Vars:        extractVars(op),
+		Vars:        g.extractVars(op),
 		Tag:         getFirstTag(op.Operation),
+		Deprecated:  op.Operation.Deprecated,
+	}
+
+	g.setRequestBody(op, &request)
+	g.applySecurity(op, &request)
+
+	if g.options.Assertions {
+		request.Expect = g.responseExpectation(op)
 	}
 
 	return request
 }
 
-// FormatPath formats path parameters for use in a request URL
+// FormatPath formats path parameters for use in a request URL and appends
+// the query parameters as a query string
 func (g *Generator) FormatPath(path string, params []models.Parameter) string {
 	// For .http files, path parameters are referenced as {{paramName}}
-	re := regexp.MustCompile(`\{([^}]+)\}`)
-	return re.ReplaceAllString(path, "{{$1}}")
+	path = pathParamPattern.ReplaceAllString(path, "{{$1}}")
+
+	query, _ := g.buildQuery(params)
+	if query != "" {
+		path += "?" + query
+	}
+
+	return path
 }
 
 // ExtractGlobalVars extracts global variables that could be used across requests
 func (g *Generator) ExtractGlobalVars(doc *models.SwaggerDoc) map[string]string {
 	vars := make(map[string]string)
 
-	// Add baseUrl variable
+	// Add baseUrl variable, referencing the server variables
 	if len(doc.Servers) > 0 && doc.Servers[0].URL != "" {
-		vars["baseUrl"] = doc.Servers[0].URL
+		vars["baseUrl"] = serverURL(doc.Servers[0])
+		addServerVars(vars, nil, doc.Servers[0])
 	} else if doc.Host != "" {
 		scheme := "http"
 		if len(doc.Schemes) > 0 {
@@ -93,12 +260,104 @@ func (g *Generator) ExtractGlobalVars(doc *models.SwaggerDoc) map[string]string
 		vars["baseUrl"] = "http://localhost"
 	}
 
-	// Add common auth variables
-	vars["authToken"] = "your_auth_token"
+	for name, value := range credentialVars(doc) {
+		vars[name] = value
+	}
 
 	return vars
 }
 
+// credentialVars returns a variable per security scheme, or a generic auth
+// token when the document declares no scheme
+func credentialVars(doc *models.SwaggerDoc) map[string]string {
+	schemes := securitySchemes(doc)
+	if len(schemes) == 0 {
+		return map[string]string{"authToken": "your_auth_token"}
+	}
+	return securityVars(schemes)
+}
+
+// ExtractEnvironments creates one environment per server of the document, or a
+// single "default" environment when none is declared. The base URL, with the
+// server variables set to their defaults, is public; credentials and the OAuth2
+// client settings are secrets.
+func (g *Generator) ExtractEnvironments(doc *models.SwaggerDoc) []models.Environment {
+	secrets := credentialVars(doc)
+	_, authVars := authRequests(securitySchemes(doc))
+	for name, value := range authVars {
+		secrets[name] = value
+	}
+
+	// Variables of the servers used by path items and operations
+	overrides := make(map[string]string)
+	operations := g.parser.ExtractOperations(doc)
+	for _, tag := range sortedTags(operations) {
+		for _, op := range operations[tag] {
+			if len(op.Servers) > 0 {
+				addServerVars(overrides, nil, op.Servers[0])
+			}
+		}
+	}
+
+	if len(doc.Servers) == 0 {
+		vars := map[string]string{"baseUrl": g.ExtractGlobalVars(doc)["baseUrl"]}
+		for k, v := range overrides {
+			vars[k] = v
+		}
+		return []models.Environment{{
+			Name:    "default",
+			Vars:    vars,
+			Secrets: secrets,
+		}}
+	}
+
+	envs := make([]models.Environment, 0, len(doc.Servers))
+	used := make(map[string]bool)
+	for i, server := range doc.Servers {
+		name := environmentName(server.Description)
+		if name == "" || used[name] {
+			name = fmt.Sprintf("server%d", i+1)
+		}
+		used[name] = true
+
+		envSecrets := make(map[string]string, len(secrets))
+		for k, v := range secrets {
+			envSecrets[k] = v
+		}
+
+		vars := map[string]string{"baseUrl": server.ExpandURL()}
+		for k, v := range overrides {
+			vars[k] = v
+		}
+
+		envs = append(envs, models.Environment{
+			Name:    name,
+			Vars:    vars,
+			Secrets: envSecrets,
+		})
+	}
+	return envs
+}
+
+// environmentName turns a server description into an environment name,
+// e.g. "Staging server" becomes "staging-server"
+func environmentName(description string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(description) {
+		if ('a' <= r && r <= 'z') || ('0' <= r && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
 // Helper functions for request generation
 
 // generateRequestName creates a readable name for the request
@@ -139,28 +398,19 @@ func toTitleCase(s string) string {
 func extractHeaders(op models.OperationInfo) map[string]string {
 	headers := make(map[string]string)
 
-	// Add Content-Type header based on consumes
-	if len(op.Operation.Consumes) > 0 {
-		headers["Content-Type"] = op.Operation.Consumes[0]
-	} else {
-		// Default to JSON for operations with request bodies
-		for _, param := range op.Parameters {
-			if param.In == "body" || isBodyParameter(param) {
-				headers["Content-Type"] = "application/json"
-				break
-			}
+	// Add Content-Type header based on consumes, defaulting to JSON
+	// for operations with request bodies
+	if hasRequestBody(op) {
+		if consumes := operationConsumes(op); len(consumes) > 0 {
+			headers["Content-Type"] = consumes[0]
+		} else {
+			headers["Content-Type"] = "application/json"
 		}
 	}
 
 	// Add Accept header based on produces
-	if len(op.Operation.Produces) > 0 {
-		headers["Accept"] = op.Operation.Produces[0]
-	}
-
-	// Add Authorization header if security is defined
-	if len(op.Operation.Security) > 0 {
-		// Generic placeholder for auth token
-		headers["Authorization"] = "Bearer {{authToken}}"
+	if produces := operationProduces(op); len(produces) > 0 {
+		headers["Accept"] = produces[0]
 	}
 
 	// Extract header parameters
@@ -174,84 +424,112 @@ func extractHeaders(op models.OperationInfo) map[string]string {
 	return headers
 }
 
-// isBodyParameter checks if a parameter is a body parameter in OpenAPI v3
+// hasRequestBody checks if the operation sends a request body
+func hasRequestBody(op models.OperationInfo) bool {
+	if op.Operation.RequestBody != nil {
+		return true
+	}
+	for _, param := range op.Parameters {
+		if param.In == "formData" || isBodyParameter(param) {
+			return true
+		}
+	}
+	return false
+}
+
+// operationConsumes returns the media types the operation accepts, including
+// those inherited from the document
+func operationConsumes(op models.OperationInfo) []string {
+	if len(op.Consumes) > 0 {
+		return op.Consumes
+	}
+	return op.Operation.Consumes
+}
+
+// operationProduces returns the media types the operation returns, including
+// those inherited from the document
+func operationProduces(op models.OperationInfo) []string {
+	if len(op.Produces) > 0 {
+		return op.Produces
+	}
+	return op.Operation.Produces
+}
+
+// isBodyParameter checks if a parameter is a body parameter in OpenAPI v3.
+// Query parameters are serialized into the URL and never count as a body.
 func isBodyParameter(param models.Parameter) bool {
 	return param.In == "body" ||
-		(param.Schema != nil && param.Schema.Type == "object") ||
-		(param.In == "query" && param.Style == "form" && param.Explode)
+		(param.In != "query" && param.Schema != nil && param.Schema.Type == "object")
 }
 
-// generateRequestBody generates a request body example based on the operation
-func generateRequestBody(op models.OperationInfo) string {
-	// Look for body parameters
-	for _, param := range op.Parameters {
-		if param.In == "body" && param.Schema != nil {
-			return generateSchemaExample(param.Schema)
-		}
+// bodyVariants creates one request per oneOf/anyOf branch of the request body schema.
+// It returns nil when the body is not a composition with several branches.
+func (g *Generator) bodyVariants(op models.OperationInfo, request models.HTTPRequest) []models.HTTPRequest {
+	media := g.requestBodyMedia(op)
+	if media == nil || media.Schema == nil {
+		return nil
 	}
+	schema := media.Schema
 
-	// Check for request body (OpenAPI v3)
-	if op.Operation.RequestBody != nil && op.Operation.RequestBody.Content != nil {
-		for contentType, mediaType := range op.Operation.RequestBody.Content {
-			if strings.Contains(contentType, "json") && mediaType.Schema != nil {
-				return generateSchemaExample(mediaType.Schema)
-			}
+	visiting := make(map[string]bool)
+	if schema.Ref != "" {
+		if g.resolver == nil {
+			return nil
 		}
+		resolved, err := g.resolver.ResolveSchema(schema)
+		if err != nil {
+			g.fail(err)
+			return nil
+		}
+		visiting[schema.Ref] = true
+		schema = resolved
+	}
+
+	branches := compositionBranches(schema)
+	if len(branches) < 2 {
+		return nil
 	}
 
-	return ""
+	variants := make([]models.HTTPRequest, 0, len(branches))
+	for i, branch := range branches {
+		variant := request
+		variant.Name = fmt.Sprintf("%s (%s)", request.Name, branchLabel(&branch, i))
+		variant.ID = identifier(request.ID + " " + branchLabel(&branch, i))
+		g.setBody(&variant, media, g.branchExample(schema, i, visiting))
+		variants = append(variants, variant)
+	}
+	return variants
 }
 
-// generateSchemaExample generates an example JSON for a schema
-func generateSchemaExample(schema *models.SchemaObj) string {
-	// For $ref schemas, we can't resolve them without a full document
-	if schema.Ref != "" {
-		return "{\n  // Reference to " + schema.Ref + "\n  // Replace with actual data\n}"
+// branchLabel names a oneOf/anyOf branch for use in request names
+func branchLabel(branch *models.SchemaObj, index int) string {
+	if branch.Ref != "" {
+		return refName(branch.Ref)
+	}
+	if branch.Title != "" {
+		return branch.Title
 	}
+	return fmt.Sprintf("option %d", index+1)
+}
 
-	// Handle primitive types
-	switch schema.Type {
-	case "string":
-		if schema.Example != nil {
-			return fmt.Sprintf("\"%v\"", schema.Example)
-		}
-		return "\"string\""
-	case "integer", "number":
-		if schema.Example != nil {
-			return fmt.Sprintf("%v", schema.Example)
-		}
-		return "0"
-	case "boolean":
-		if schema.Example != nil {
-			return fmt.Sprintf("%v", schema.Example)
-		}
-		return "false"
-	case "array":
-		if schema.Items != nil {
-			itemExample := generateSchemaExample(schema.Items)
-			return fmt.Sprintf("[\n  %s\n]", itemExample)
-		}
-		return "[]"
-	case "object":
-		var builder strings.Builder
-		builder.WriteString("{\n")
-
-		if schema.Properties != nil {
-			i := 0
-			for propName, propSchema := range schema.Properties {
-				propExample := generateSchemaExample(&propSchema)
-				if i > 0 {
-					builder.WriteString(",\n")
-				}
-				builder.WriteString(fmt.Sprintf("  \"%s\": %s", propName, propExample))
-				i++
-			}
-		}
+// resolveRequestBody follows a request body $ref, recording any resolution error
+func (g *Generator) resolveRequestBody(body *models.RequestBody) *models.RequestBody {
+	if body == nil || body.Ref == "" || g.resolver == nil {
+		return body
+	}
 
-		builder.WriteString("\n}")
-		return builder.String()
-	default:
-		return "{}"
+	resolved, err := g.resolver.ResolveRequestBody(body)
+	if err != nil {
+		g.fail(err)
+		return nil
+	}
+	return resolved
+}
+
+// fail records the first error encountered while generating requests
+func (g *Generator) fail(err error) {
+	if g.err == nil {
+		g.err = err
 	}
 }
 
@@ -269,44 +547,124 @@ func generateDescription(op models.OperationInfo) string {
 }
 
 // extractVars extracts variables from the operation
-func extractVars(op models.OperationInfo) map[string]string {
+func (g *Generator) extractVars(op models.OperationInfo) map[string]string {
 	vars := make(map[string]string)
 
 	// Add path parameters as variables
 	for _, param := range op.Parameters {
 		if param.In == "path" {
-			// Generate example value based on type
-			varValue := generateExampleValue(param)
-			vars[param.Name] = varValue
+			vars[param.Name] = pathValue(g.paramExample(param))
 		}
 	}
 
+	// Add query parameters as variables
+	_, queryVars := g.buildQuery(op.Parameters)
+	for name, value := range queryVars {
+		vars[name] = value
+	}
+
 	return vars
 }
 
-// generateExampleValue generates an example value for a parameter
-func generateExampleValue(param models.Parameter) string {
-	// Use example if provided
-	if param.Example != nil {
-		return fmt.Sprintf("%v", param.Example)
+// scopeRequestVars renames the request variables that requests of a file
+// define with different values, such as a petId that is an integer in one
+// operation and a string in another. HTTP clients share the variables of a
+// file, so the first request keeps the name and the others prefix it with
+// their ID, e.g. {{updatePetPetId}}. Request variables named like file
+// variables with other values are renamed the same way, since file variables
+// take precedence. Response variables provide the value of the requests and
+// are left alone.
+func scopeRequestVars(file *models.HTTPFile) {
+	values := make(map[string]string, len(file.GlobalVars))
+	for name, value := range file.GlobalVars {
+		values[name] = value
+	}
+	for i := range file.Requests {
+		req := &file.Requests[i]
+
+		vars := make(map[string]string, len(req.Vars))
+		var renames []string
+		for _, name := range sortedKeys(req.Vars) {
+			value := req.Vars[name]
+			_, response := file.ResponseVars[name]
+			if existing, seen := values[name]; response || !seen || existing == value {
+				values[name] = value
+				vars[name] = value
+				continue
+			}
+
+			prefix := req.ID
+			if prefix == "" {
+				prefix = req.Name
+			}
+			base := identifier(prefix + " " + name)
+			scoped := base
+			for n := 2; ; n++ {
+				existing, taken := values[scoped]
+				if _, response := file.ResponseVars[scoped]; !response && (!taken || existing == value) {
+					break
+				}
+				scoped = fmt.Sprintf("%s%d", base, n)
+			}
+			values[scoped] = value
+			vars[scoped] = value
+			renames = append(renames, "{{"+name+"}}", "{{"+scoped+"}}")
+		}
+		if len(renames) > 0 {
+			renameVars(req, strings.NewReplacer(renames...))
+		}
+
+		// Variants of a request share its variables, they get their own copy
+		req.Vars = vars
+	}
+}
+
+// renameVars replaces the references to variables in the URL, headers and
+// body of a request. Variants of a request share its headers and form parts,
+// they get their own copy.
+func renameVars(req *models.HTTPRequest, replacer *strings.Replacer) {
+	req.Path = replacer.Replace(req.Path)
+	req.Body = replacer.Replace(req.Body)
+	if len(req.FormParts) > 0 {
+		parts := make([]models.FormPart, len(req.FormParts))
+		for i, part := range req.FormParts {
+			part.Value = replacer.Replace(part.Value)
+			parts[i] = part
+		}
+		req.FormParts = parts
+	}
+	if req.Generated != "" {
+		req.Generated = bodyFingerprint(*req)
 	}
 
-	// Use default if provided
-	if param.Default != nil {
-		return fmt.Sprintf("%v", param.Default)
+	if len(req.Headers) == 0 {
+		return
+	}
+	headers := make(map[string]string, len(req.Headers))
+	for name, value := range req.Headers {
+		headers[name] = replacer.Replace(value)
 	}
+	req.Headers = headers
+}
 
-	// Generate based on type
-	switch param.Type {
-	case "string":
-		return "example_string"
-	case "integer", "number":
-		return "123"
-	case "boolean":
-		return "true"
-	default:
-		return "example"
+// pathValue serializes the example of a path parameter in the default simple
+// style: array items and object properties are separated by commas
+func pathValue(value interface{}) string {
+	switch v := value.(type) {
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, queryString(item))
+		}
+		return strings.Join(items, ",")
+	case jsonObject:
+		items := make([]string, 0, len(v)*2)
+		for _, field := range v {
+			items = append(items, field.Name, queryString(field.Value))
+		}
+		return strings.Join(items, ",")
 	}
+	return queryString(value)
 }
 
 // getFirstTag gets the first tag of an operation or returns "default"
@@ -319,7 +677,14 @@ func getFirstTag(op *models.Operation) string {
 
 // New creates a new Generator instance
 func New(p parser.SwaggerParser) *Generator {
+	return NewWithOptions(p, Options{})
+}
+
+// NewWithOptions creates a new Generator instance with the given options
+func NewWithOptions(p parser.SwaggerParser, options Options) *Generator {
 	return &Generator{
-		parser: p,
+		parser:  p,
+		options: options,
+		fake:    newFakeData(options),
 	}
 }
@@ -2,6 +2,8 @@ package http
 
 import (
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/edgardnogueira/swagger-to-http-file/internal/adapters/swagger"
@@ -61,16 +63,20 @@ func TestGenerator_Generate(t *testing.T) {
 		hasGetRequest := false
 		hasPostRequest := false
 		for _, req := range petsFile.Requests {
-			if req.Method == "GET" && req.Path == "/pets" {
+			if req.Method == "GET" && req.Path == "/pets?limit={{limit}}" {
 				hasGetRequest = true
 			}
 			if req.Method == "POST" && req.Path == "/pets" {
 				hasPostRequest = true
+				// The body is built from the referenced Pet definition
+				if !strings.Contains(req.Body, `"name": "string"`) {
+					t.Errorf("Expected POST /pets body to contain the Pet properties, got:\n%s", req.Body)
+				}
 			}
 		}
 
 		if !hasGetRequest {
-			t.Errorf("Missing GET /pets request")
+			t.Errorf("Missing GET /pets?limit={{limit}} request")
 		}
 		if !hasPostRequest {
 			t.Errorf("Missing POST /pets request")
@@ -176,8 +182,11 @@ func TestGenerator_GenerateRequest(t *testing.T) {
 	if getReq.Method != "GET" {
 		t.Errorf("Expected GET method, got %s", getReq.Method)
 	}
-	if getReq.Path != "/pets" {
-		t.Errorf("Expected /pets path, got %s", getReq.Path)
+	if getReq.Path != "/pets?limit={{limit}}" {
+		t.Errorf("Expected /pets?limit={{limit}} path, got %s", getReq.Path)
+	}
+	if getReq.Vars["limit"] != "0" {
+		t.Errorf("Expected limit variable to be 0, got %q", getReq.Vars["limit"])
 	}
 	if getReq.Name != "List all pets" {
 		t.Errorf("Expected 'List all pets' name, got %s", getReq.Name)
@@ -185,6 +194,15 @@ func TestGenerator_GenerateRequest(t *testing.T) {
 	if len(getReq.Body) > 0 {
 		t.Errorf("GET request should not have a body")
 	}
+	if getReq.Deprecated {
+		t.Errorf("GET request should not be deprecated")
+	}
+
+	// Deprecated operations are marked
+	getOp.Operation.Deprecated = true
+	if !generator.GenerateRequest(getOp, baseURL).Deprecated {
+		t.Errorf("Expected request of a deprecated operation to be deprecated")
+	}
 
 	// Generate and check POST request
 	postReq := generator.GenerateRequest(postOp, baseURL)
@@ -325,3 +343,365 @@ func TestGenerator_ExtractGlobalVars(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerator_ExtractEnvironments(t *testing.T) {
+	generator := New(swagger.New())
+
+	schemes := &models.Components{
+		SecuritySchemes: map[string]models.SecurityScheme{
+			"apiKey": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		doc      *models.SwaggerDoc
+		expected []models.Environment
+	}{
+		{
+			name: "one environment per server",
+			doc: &models.SwaggerDoc{
+				Servers: []models.Server{
+					{URL: "https://api.example.com", Description: "Production"},
+					{URL: "https://staging.example.com", Description: "Staging (EU)"},
+					{URL: "https://other.example.com", Description: "production"},
+				},
+				Components: schemes,
+			},
+			expected: []models.Environment{
+				{Name: "production", Vars: map[string]string{"baseUrl": "https://api.example.com"}, Secrets: map[string]string{"apiKey": "your_api_key"}},
+				{Name: "staging-eu", Vars: map[string]string{"baseUrl": "https://staging.example.com"}, Secrets: map[string]string{"apiKey": "your_api_key"}},
+				{Name: "server3", Vars: map[string]string{"baseUrl": "https://other.example.com"}, Secrets: map[string]string{"apiKey": "your_api_key"}},
+			},
+		},
+		{
+			name: "default environment without servers",
+			doc: &models.SwaggerDoc{
+				Host:     "api.example.com",
+				BasePath: "/v1",
+			},
+			expected: []models.Environment{
+				{Name: "default", Vars: map[string]string{"baseUrl": "http://api.example.com/v1"}, Secrets: map[string]string{"authToken": "your_auth_token"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envs := generator.ExtractEnvironments(tt.doc)
+			if !reflect.DeepEqual(envs, tt.expected) {
+				t.Errorf("Expected environments %+v, got %+v", tt.expected, envs)
+			}
+		})
+	}
+}
+
+func TestGenerator_GenerateResolvesRefs(t *testing.T) {
+	const sample = "../../../test/samples/refs/openapi.yaml"
+
+	data, err := os.ReadFile(sample)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	parser := swagger.New()
+	parser.SetBaseLocation(sample)
+	generator := New(parser)
+
+	doc, err := parser.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse swagger: %v", err)
+	}
+
+	files, err := generator.Generate(doc, parser.GetBaseURL(doc))
+	if err != nil {
+		t.Fatalf("Failed to generate HTTP files: %v", err)
+	}
+
+	ordersFile, exists := files["orders"]
+	if !exists || len(ordersFile.Requests) != 1 {
+		t.Fatalf("Expected a single request in the 'orders' file, got %+v", files)
+	}
+
+	// Local, escaped and cross-file references are expanded; the recursive parent is cut short
+	expected := `{
+  "id": 0,
+  "customer": {
+    "name": "string",
+    "address": {
+      "city": "string"
+    }
+  },
+  "lines": [
+    {
+      "sku": "string"
+    }
+  ],
+  "parent": {}
+}`
+	if body := ordersFile.Requests[0].Body; body != expected {
+		t.Errorf("Unexpected request body:\n%s\nwant:\n%s", body, expected)
+	}
+}
+
+func TestGenerator_GenerateSharedParameters(t *testing.T) {
+	data, err := os.ReadFile("../../../test/samples/shared-params.json")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	parser := swagger.New()
+	generator := New(parser)
+
+	doc, err := parser.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse swagger: %v", err)
+	}
+
+	files, err := generator.Generate(doc, parser.GetBaseURL(doc))
+	if err != nil {
+		t.Fatalf("Failed to generate HTTP files: %v", err)
+	}
+
+	requests := make(map[string]models.HTTPRequest)
+	for _, request := range files["users"].Requests {
+		requests[request.Method] = request
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		vars    map[string]string
+		headers map[string]string
+	}{
+		{
+			name:    "list with overridden query parameter",
+			method:  "GET",
+			path:    "/{{tenantId}}/users?pageSize={{pageSize}}",
+			vars:    map[string]string{"tenantId": "acme", "pageSize": "50"},
+			headers: map[string]string{"Accept": "application/json"},
+		},
+		{
+			name:   "create with inherited media types and a scoped query variable",
+			method: "POST",
+			path:   "/{{tenantId}}/users?pageSize={{createUserPageSize}}",
+			vars:   map[string]string{"tenantId": "acme", "createUserPageSize": "20"},
+			headers: map[string]string{
+				"Accept":       "application/xml",
+				"Content-Type": "application/json",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, ok := requests[tt.method]
+			if !ok {
+				t.Fatalf("Request %s not generated", tt.method)
+			}
+			if request.Path != tt.path {
+				t.Errorf("Expected path %q, got %q", tt.path, request.Path)
+			}
+			if !reflect.DeepEqual(request.Vars, tt.vars) {
+				t.Errorf("Expected vars %v, got %v", tt.vars, request.Vars)
+			}
+			if !reflect.DeepEqual(request.Headers, tt.headers) {
+				t.Errorf("Expected headers %v, got %v", tt.headers, request.Headers)
+			}
+		})
+	}
+}
+
+func TestGenerator_GenerateUnresolvableRef(t *testing.T) {
+	parser := swagger.New()
+	generator := New(parser)
+
+	doc := &models.SwaggerDoc{
+		Swagger: "2.0",
+		Paths: map[string]models.PathItem{
+			"/pets": {
+				Post: &models.Operation{
+					Parameters: []models.Parameter{
+						{
+							Name:   "pet",
+							In:     "body",
+							Schema: &models.SchemaObj{Ref: "#/definitions/Missing"},
+						},
+					},
+					Responses: map[string]models.Response{"201": {Description: "Created"}},
+				},
+			},
+		},
+	}
+
+	if _, err := generator.Generate(doc, "http://localhost"); err == nil {
+		t.Errorf("Expected an error for an unresolvable reference")
+	}
+}
+
+// generatePolymorphic generates the polymorphic sample with the given options
+func generatePolymorphic(t *testing.T, options Options) map[string]*models.HTTPFile {
+	t.Helper()
+
+	data, err := os.ReadFile("../../../test/samples/polymorphic.yaml")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	parser := swagger.New()
+	doc, err := parser.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse swagger: %v", err)
+	}
+
+	files, err := NewWithOptions(parser, options).Generate(doc, parser.GetBaseURL(doc))
+	if err != nil {
+		t.Fatalf("Failed to generate HTTP files: %v", err)
+	}
+	return files
+}
+
+func TestGenerator_Composition(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		tag      string
+		expected string
+	}{
+		{
+			name: "oneOf picks the first branch and sets the discriminator",
+			tag:  "pets",
+			expected: `{
+  "petType": "cat",
+  "name": "string",
+  "meows": false
+}`,
+		},
+		{
+			name:    "oneOf branch chosen by discriminator value",
+			options: Options{Branches: []string{"dog"}},
+			tag:     "pets",
+			expected: `{
+  "petType": "dog",
+  "name": "string",
+  "barks": false
+}`,
+		},
+		{
+			name: "allOf merges parent properties and names the subtype",
+			tag:  "adoptions",
+			expected: `{
+  "petType": "Cat",
+  "name": "string",
+  "meows": false
+}`,
+		},
+		{
+			name: "anyOf and not",
+			tag:  "tags",
+			expected: `{
+  "label": 0,
+  "note": 0
+}`,
+		},
+		{
+			name:    "anyOf branch chosen by title",
+			options: Options{Branches: []string{"missing", "Text"}},
+			tag:     "tags",
+			expected: `{
+  "label": "string",
+  "note": 0
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generatePolymorphic(t, tt.options)
+			file, exists := files[tt.tag]
+			if !exists || len(file.Requests) != 1 {
+				t.Fatalf("Expected a single request for tag %s", tt.tag)
+			}
+			if body := file.Requests[0].Body; body != tt.expected {
+				t.Errorf("Unexpected request body:\n%s\nwant:\n%s", body, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGenerator_BranchVariants(t *testing.T) {
+	files := generatePolymorphic(t, Options{BranchVariants: true})
+
+	requests := files["pets"].Requests
+	if len(requests) != 2 {
+		t.Fatalf("Expected one request per oneOf branch, got %d", len(requests))
+	}
+
+	expected := map[string]string{
+		"Add a pet (Cat)": `"petType": "cat"`,
+		"Add a pet (Dog)": `"petType": "dog"`,
+	}
+	for _, req := range requests {
+		want, ok := expected[req.Name]
+		if !ok {
+			t.Errorf("Unexpected request name %q", req.Name)
+			continue
+		}
+		if !strings.Contains(req.Body, want) {
+			t.Errorf("Expected %q body to contain %s, got:\n%s", req.Name, want, req.Body)
+		}
+	}
+
+	// Bodies without a composition are left alone
+	if len(files["adoptions"].Requests) != 1 {
+		t.Errorf("Expected a single adoptions request, got %d", len(files["adoptions"].Requests))
+	}
+}
+
+func TestGenerator_SwaggerV2Discriminator(t *testing.T) {
+	data := []byte(`{
+  "swagger": "2.0",
+  "paths": {
+    "/cats": {
+      "post": {
+        "tags": ["cats"],
+        "parameters": [{"name": "cat", "in": "body", "schema": {"$ref": "#/definitions/Cat"}}],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  },
+  "definitions": {
+    "Pet": {
+      "type": "object",
+      "discriminator": "kind",
+      "properties": {"kind": {"type": "string"}}
+    },
+    "Cat": {
+      "allOf": [
+        {"$ref": "#/definitions/Pet"},
+        {"properties": {"indoor": {"type": "boolean"}}}
+      ]
+    }
+  }
+}`)
+
+	parser := swagger.New()
+	doc, err := parser.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse swagger: %v", err)
+	}
+
+	files, err := New(parser).Generate(doc, "http://localhost")
+	if err != nil {
+		t.Fatalf("Failed to generate HTTP files: %v", err)
+	}
+
+	expected := `{
+  "kind": "Cat",
+  "indoor": false
+}`
+	if body := files["cats"].Requests[0].Body; body != expected {
+		t.Errorf("Unexpected request body:\n%s\nwant:\n%s", body, expected)
+	}
+}
@@ -0,0 +1,174 @@
+package http
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// MergeFiles merges a regenerated file into an existing one that may have
+// been edited by hand. Requests are matched by their name (# @name), or by
+// method and URL for requests without one:
+//
+//   - matched requests take the method, URL, headers, name and description
+//     of the document, keeping the headers, captures, response handler
+//     statements and assertions added by hand, and the body when it was
+//     edited since it was generated
+//   - generated requests of operations no longer in the document are kept,
+//     marked as deprecated; requests added by hand are kept as they are
+//   - requests of new operations are added at the end
+//
+// File and request variables keep their existing values; new variables are added.
+func MergeFiles(existing, generated *models.HTTPFile) *models.HTTPFile {
+	merged := &models.HTTPFile{
+		BaseURL:      generated.BaseURL,
+		GlobalVars:   make(map[string]string),
+		VarComments:  make(map[string]string),
+		ResponseVars: make(map[string]models.ResponseRef),
+		SharedVars:   generated.SharedVars,
+		Requests:     []models.HTTPRequest{},
+		Tag:          generated.Tag,
+	}
+
+	for name, value := range generated.GlobalVars {
+		merged.GlobalVars[name] = value
+	}
+	for name, value := range existing.GlobalVars {
+		merged.GlobalVars[name] = value
+	}
+	for name, comment := range existing.VarComments {
+		merged.VarComments[name] = comment
+	}
+	for name, comment := range generated.VarComments {
+		merged.VarComments[name] = comment
+	}
+	for name, ref := range existing.ResponseVars {
+		merged.ResponseVars[name] = ref
+	}
+	for name, ref := range generated.ResponseVars {
+		merged.ResponseVars[name] = ref
+	}
+
+	// Generated requests by key, the first one wins
+	byKey := make(map[string]int, len(generated.Requests))
+	for i, req := range generated.Requests {
+		if _, ok := byKey[requestKey(req)]; !ok {
+			byKey[requestKey(req)] = i
+		}
+	}
+
+	used := make([]bool, len(generated.Requests))
+	for _, req := range existing.Requests {
+		if i, ok := byKey[requestKey(req)]; ok && !used[i] {
+			used[i] = true
+			merged.Requests = append(merged.Requests, mergeRequest(req, generated.Requests[i], generated))
+			continue
+		}
+		if req.Generated != "" {
+			req.Deprecated = true
+		}
+		merged.Requests = append(merged.Requests, req)
+	}
+
+	for i, req := range generated.Requests {
+		if !used[i] {
+			merged.Requests = append(merged.Requests, req)
+		}
+	}
+
+	return merged
+}
+
+// requestKey identifies a request by its ID, or its method and URL when it has none
+func requestKey(req models.HTTPRequest) string {
+	if req.ID != "" {
+		return req.ID
+	}
+	return req.Method + " " + req.Path
+}
+
+// mergeRequest updates an existing request from its regenerated version
+func mergeRequest(existing, generated models.HTTPRequest, file *models.HTTPFile) models.HTTPRequest {
+	merged := generated
+
+	// Headers added by hand are kept, the document's values win
+	merged.Headers = make(map[string]string, len(generated.Headers))
+	for name, value := range existing.Headers {
+		if !hasHeader(generated.Headers, name) {
+			merged.Headers[name] = value
+		}
+	}
+	for name, value := range generated.Headers {
+		merged.Headers[name] = value
+	}
+
+	// Request variables keep their existing values, those added by hand are kept
+	if len(existing.Vars) > 0 {
+		merged.Vars = make(map[string]string, len(generated.Vars)+len(existing.Vars))
+		for name, value := range generated.Vars {
+			merged.Vars[name] = value
+		}
+		for name, value := range existing.Vars {
+			merged.Vars[name] = value
+		}
+	}
+
+	// Bodies edited since they were generated are kept, they are usually tuned
+	// by hand. Those of files written before requests were fingerprinted too.
+	if edited := existing.Generated == "" || existing.Generated != bodyFingerprint(existing); edited && (existing.Body != "" || existing.BodyFile != "" || len(existing.FormParts) > 0) {
+		merged.Body = existing.Body
+		merged.BodyFile = existing.BodyFile
+		merged.FormParts = existing.FormParts
+	}
+
+	// Captures added by hand are kept. Those of the document are written from
+	// the generated request and the response variables of the file.
+	provided := make(map[string]bool)
+	for _, capture := range generated.Captures {
+		provided[capture.Variable] = true
+	}
+	for name, ref := range file.ResponseVars {
+		if ref.Request == generated.ID {
+			provided[name] = true
+		}
+	}
+	merged.Captures = append([]models.ResponseCapture{}, generated.Captures...)
+	for _, capture := range existing.Captures {
+		if !provided[capture.Variable] {
+			merged.Captures = append(merged.Captures, capture)
+		}
+	}
+	if len(merged.Captures) == 0 {
+		merged.Captures = nil
+	}
+
+	// Scripts and assertions written by hand are kept
+	merged.PreScript = existing.PreScript
+	merged.Script = existing.Script
+	merged.Assertions = existing.Assertions
+
+	return merged
+}
+
+// hasHeader reports whether headers contain a header, ignoring the case of its name
+func hasHeader(headers map[string]string, name string) bool {
+	for header := range headers {
+		if strings.EqualFold(header, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyFingerprint identifies the body of a request, so that a merge can tell
+// whether a generated body was edited
+func bodyFingerprint(req models.HTTPRequest) string {
+	h := fnv.New32a()
+	h.Write([]byte(requestBody(req)))
+	if req.BodyFile != "" {
+		h.Write([]byte("\n< " + req.BodyFile))
+	}
+	return fmt.Sprintf("%08x", h.Sum32())
+}
@@ -0,0 +1,360 @@
+package http
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+func TestMergeFiles(t *testing.T) {
+	generatedBody := "{\n  \"name\": \"string\"\n}"
+	fingerprint := bodyFingerprint(models.HTTPRequest{Body: generatedBody})
+	empty := bodyFingerprint(models.HTTPRequest{})
+
+	existing := &models.HTTPFile{
+		BaseURL:     "http://localhost:8080",
+		GlobalVars:  map[string]string{"baseUrl": "http://localhost:8080", "limit": "5"},
+		VarComments: map[string]string{"limit": "page size"},
+		Requests: []models.HTTPRequest{
+			{
+				Name:      "List pets",
+				ID:        "listPets",
+				Method:    "GET",
+				Path:      "/pets?limit={{limit}}",
+				Headers:   map[string]string{"Accept": "application/json", "x-debug": "true"},
+				Vars:      map[string]string{"sort": "name", "page": "3"},
+				Generated: empty,
+			},
+			{
+				Name:    "Create a pet",
+				ID:      "createPet",
+				Method:  "POST",
+				Path:    "/pets",
+				Headers: map[string]string{"Content-Type": "application/json"},
+				Body:    "{\n  \"name\": \"Rex\"\n}",
+				Captures: []models.ResponseCapture{
+					{Variable: "petId", Path: "id"},
+					{Variable: "petName", Path: "name"},
+				},
+				Generated: fingerprint,
+			},
+			{
+				Name:      "Update a pet",
+				ID:        "updatePet",
+				Method:    "PUT",
+				Path:      "/pets/{{petId}}",
+				Headers:   map[string]string{"Content-Type": "application/json"},
+				Body:      generatedBody,
+				Generated: fingerprint,
+			},
+			{
+				Name:    "Check health",
+				Method:  "GET",
+				Path:    "/health",
+				Headers: map[string]string{},
+			},
+			{
+				Name:    "Ping",
+				ID:      "ping",
+				Method:  "GET",
+				Path:    "/ping",
+				Headers: map[string]string{},
+			},
+			{
+				Name:      "Feed a pet",
+				ID:        "feedPet",
+				Method:    "POST",
+				Path:      "/pets/{{petId}}/feed",
+				Headers:   map[string]string{},
+				Generated: empty,
+			},
+		},
+	}
+
+	generated := &models.HTTPFile{
+		BaseURL:      "https://api.example.com",
+		GlobalVars:   map[string]string{"baseUrl": "https://api.example.com", "limit": "20", "status": "available"},
+		VarComments:  map[string]string{"limit": "How many pets to return"},
+		ResponseVars: map[string]models.ResponseRef{"petId": {Request: "createPet", Path: "id"}},
+		Requests: []models.HTTPRequest{
+			{
+				Name:      "List pets",
+				ID:        "listPets",
+				Method:    "GET",
+				Path:      "/v2/pets?limit={{limit}}&status={{status}}&sort={{sort}}",
+				Headers:   map[string]string{"Accept": "application/json", "X-Debug": "false"},
+				Vars:      map[string]string{"sort": "id"},
+				Generated: empty,
+			},
+			{
+				Name:      "Create a pet",
+				ID:        "createPet",
+				Method:    "POST",
+				Path:      "/v2/pets",
+				Headers:   map[string]string{"Content-Type": "application/json"},
+				Body:      "{\n  \"name\": \"string\",\n  \"tag\": \"string\"\n}",
+				Generated: "new",
+			},
+			{
+				Name:      "Update a pet",
+				ID:        "updatePet",
+				Method:    "PUT",
+				Path:      "/v2/pets/{{petId}}",
+				Headers:   map[string]string{"Content-Type": "application/json"},
+				Body:      "{\n  \"name\": \"string\",\n  \"tag\": \"string\"\n}",
+				Generated: "new",
+			},
+			{
+				Name:      "Get a pet",
+				ID:        "getPet",
+				Method:    "GET",
+				Path:      "/v2/pets/{{petId}}",
+				Headers:   map[string]string{},
+				Generated: empty,
+			},
+		},
+		Tag: "pets",
+	}
+
+	expected := &models.HTTPFile{
+		BaseURL:      "https://api.example.com",
+		GlobalVars:   map[string]string{"baseUrl": "http://localhost:8080", "limit": "5", "status": "available"},
+		VarComments:  map[string]string{"limit": "How many pets to return"},
+		ResponseVars: map[string]models.ResponseRef{"petId": {Request: "createPet", Path: "id"}},
+		Requests: []models.HTTPRequest{
+			{
+				Name:      "List pets",
+				ID:        "listPets",
+				Method:    "GET",
+				Path:      "/v2/pets?limit={{limit}}&status={{status}}&sort={{sort}}",
+				Headers:   map[string]string{"Accept": "application/json", "X-Debug": "false"},
+				Vars:      map[string]string{"sort": "name", "page": "3"},
+				Generated: empty,
+			},
+			{
+				// The body was edited, it is kept
+				Name:      "Create a pet",
+				ID:        "createPet",
+				Method:    "POST",
+				Path:      "/v2/pets",
+				Headers:   map[string]string{"Content-Type": "application/json"},
+				Body:      "{\n  \"name\": \"Rex\"\n}",
+				Captures:  []models.ResponseCapture{{Variable: "petName", Path: "name"}},
+				Generated: "new",
+			},
+			{
+				// The body is the one generated before, it follows the document
+				Name:      "Update a pet",
+				ID:        "updatePet",
+				Method:    "PUT",
+				Path:      "/v2/pets/{{petId}}",
+				Headers:   map[string]string{"Content-Type": "application/json"},
+				Body:      "{\n  \"name\": \"string\",\n  \"tag\": \"string\"\n}",
+				Generated: "new",
+			},
+			{
+				Name:    "Check health",
+				Method:  "GET",
+				Path:    "/health",
+				Headers: map[string]string{},
+			},
+			{
+				// Named requests added by hand are not deprecated
+				Name:    "Ping",
+				ID:      "ping",
+				Method:  "GET",
+				Path:    "/ping",
+				Headers: map[string]string{},
+			},
+			{
+				Name:       "Feed a pet",
+				ID:         "feedPet",
+				Method:     "POST",
+				Path:       "/pets/{{petId}}/feed",
+				Headers:    map[string]string{},
+				Deprecated: true,
+				Generated:  empty,
+			},
+			{
+				Name:      "Get a pet",
+				ID:        "getPet",
+				Method:    "GET",
+				Path:      "/v2/pets/{{petId}}",
+				Headers:   map[string]string{},
+				Generated: empty,
+			},
+		},
+		Tag: "pets",
+	}
+
+	merged := MergeFiles(existing, generated)
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("Expected %+v\ngot %+v", expected, merged)
+	}
+}
+
+func TestMergeFiles_MatchesByMethodAndPath(t *testing.T) {
+	existing := &models.HTTPFile{
+		Requests: []models.HTTPRequest{
+			{Name: "Ping", Method: "GET", Path: "/ping", Body: "edited"},
+			{Name: "Custom", Method: "GET", Path: "/custom"},
+		},
+	}
+	generated := &models.HTTPFile{
+		Requests: []models.HTTPRequest{
+			{Name: "Ping the server", Method: "GET", Path: "/ping", Headers: map[string]string{"Accept": "text/plain"}},
+		},
+	}
+
+	merged := MergeFiles(existing, generated)
+	if len(merged.Requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(merged.Requests))
+	}
+
+	ping := merged.Requests[0]
+	if ping.Name != "Ping the server" || ping.Body != "edited" || ping.Headers["Accept"] != "text/plain" {
+		t.Errorf("Expected ping request to be merged, got %+v", ping)
+	}
+
+	// Requests added by hand are never deprecated
+	if custom := merged.Requests[1]; custom.Name != "Custom" || custom.Deprecated {
+		t.Errorf("Expected custom request to be kept as is, got %+v", custom)
+	}
+}
+
+func TestMergeFiles_RoundTrip(t *testing.T) {
+	file := &models.HTTPFile{
+		BaseURL:      "https://api.example.com",
+		GlobalVars:   map[string]string{"baseUrl": "https://api.example.com"},
+		ResponseVars: map[string]models.ResponseRef{"petId": {Request: "createPet", Path: "id"}},
+		Requests: []models.HTTPRequest{
+			{Name: "Create a pet", ID: "createPet", Method: "POST", Path: "/pets", Headers: map[string]string{}, Body: "{}"},
+			{Name: "Get a pet", ID: "getPet", Method: "GET", Path: "/pets/{{petId}}", Headers: map[string]string{}},
+		},
+	}
+	for i := range file.Requests {
+		file.Requests[i].Generated = bodyFingerprint(file.Requests[i])
+	}
+
+	// Merging a file into its own formatted output changes nothing
+	for _, name := range []string{DialectJetBrains, DialectRESTClient} {
+		t.Run(name, func(t *testing.T) {
+			formatter := NewDialectFormatter(name)
+			content := formatter.FormatHTTPFile(file)
+
+			existing, err := NewParser().Parse([]byte(content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+
+			if merged := formatter.FormatHTTPFile(MergeFiles(existing, file)); merged != content {
+				t.Errorf("Expected merged file to be unchanged\nexpected:\n%s\ngot:\n%s", content, merged)
+			}
+		})
+	}
+}
+
+func TestMergeFiles_KeepsResponseHandlers(t *testing.T) {
+	generated := &models.HTTPFile{
+		GlobalVars: map[string]string{"baseUrl": "https://api.example.com"},
+		Requests: []models.HTTPRequest{{
+			Name:     "Create a pet",
+			ID:       "createPet",
+			Method:   "POST",
+			Path:     "/pets",
+			Headers:  map[string]string{},
+			Captures: []models.ResponseCapture{{Variable: "petId", Path: "id"}},
+			Expect:   &models.ResponseExpectation{Status: "201"},
+		}},
+	}
+
+	tests := []struct {
+		dialect string
+		custom  string // lines added by hand after the generated request
+	}{
+		{
+			dialect: DialectJetBrains,
+			custom: "> {%\n" +
+				"    client.global.set(\"petId\", response.body.id);\n" +
+				"    client.test(\"Status is 201\", function() {\n" +
+				"        client.assert(response.status === 201, \"Expected status 201, got \" + response.status);\n" +
+				"    });\n" +
+				"    client.test(\"Pet is named\", function() {\n" +
+				"        client.assert(response.body.name === \"Rex\");\n" +
+				"    });\n" +
+				"%}\n",
+		},
+		{
+			dialect: DialectHTTPYac,
+			custom: "?? status == 201\n" +
+				"?? body name == Rex\n" +
+				"\n" +
+				"> {%\n" +
+				"    client.global.set(\"petId\", response.body.id);\n" +
+				"    client.test(\"Pet is named\", function() {\n" +
+				"        client.assert(response.body.name === \"Rex\");\n" +
+				"    });\n" +
+				"%}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			formatter := NewDialectFormatter(tt.dialect)
+			content := formatter.FormatHTTPFile(generated)
+
+			// The edited file replaces the generated handler with one testing more
+			requestLine := "POST {{baseUrl}}/pets\n"
+			end := strings.Index(content, requestLine)
+			if end < 0 {
+				t.Fatalf("Unexpected generated file:\n%s", content)
+			}
+			edited := content[:end+len(requestLine)] + "\n" + tt.custom + "\n"
+
+			existing, err := NewParser().Parse([]byte(edited))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+
+			// The custom test survives the merge, the generated ones are not repeated
+			if merged := formatter.FormatHTTPFile(MergeFiles(existing, generated)); merged != edited {
+				t.Errorf("Expected the handler written by hand to be kept\nexpected:\n%s\ngot:\n%s", edited, merged)
+			}
+		})
+	}
+}
+
+func TestMergeFiles_KeepsPreRequestScripts(t *testing.T) {
+	generated := &models.HTTPFile{
+		GlobalVars: map[string]string{"baseUrl": "https://api.example.com"},
+		SharedVars: map[string]string{"petId": "0"},
+		Requests: []models.HTTPRequest{{
+			Name:    "Get a pet",
+			ID:      "getPet",
+			Method:  "GET",
+			Path:    "/pets/{{petId}}",
+			Headers: map[string]string{},
+			Vars:    map[string]string{"petId": "0"},
+		}},
+	}
+	formatter := NewDialectFormatter(DialectJetBrains)
+	content := formatter.FormatHTTPFile(generated)
+
+	// The edited file changes the default of petId and adds a statement
+	edited := strings.Replace(content, `client.global.set("petId", "0");`+"\n",
+		`client.global.set("petId", "42");`+"\n    request.variables.set(\"trace\", \"on\");\n", 1)
+	if edited == content {
+		t.Fatalf("Unexpected generated file:\n%s", content)
+	}
+
+	existing, err := NewParser().Parse([]byte(edited))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if merged := formatter.FormatHTTPFile(MergeFiles(existing, generated)); merged != edited {
+		t.Errorf("Expected the pre-request script written by hand to be kept\nexpected:\n%s\ngot:\n%s", edited, merged)
+	}
+}
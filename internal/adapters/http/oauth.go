@@ -0,0 +1,262 @@
+package http
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// authFileTag is the tag of the file holding the token requests
+const authFileTag = "auth"
+
+// defaultRedirectURI is the placeholder redirect URI of browser based flows
+const defaultRedirectURI = "http://localhost:8080/callback"
+
+// addAuthFile adds the requests obtaining OAuth2 and OpenID Connect access
+// tokens to the auth file. Their responses are captured into {{authToken}},
+// which the requests of the other files send as a bearer token. Clients
+// without scripts cannot share it between files, those files get a
+// placeholder to paste the token into.
+func (g *Generator) addAuthFile(files map[string]*models.HTTPFile, baseURL string, globalVars map[string]string) {
+	requests, authVars := authRequests(g.schemes)
+	if len(requests) == 0 {
+		return
+	}
+
+	file, exists := files[authFileTag]
+	if !exists {
+		file = &models.HTTPFile{
+			BaseURL: baseURL,
+			Tag:     authFileTag,
+		}
+		files[authFileTag] = file
+	}
+
+	// The global variables are shared by all files, copy them before adding ours
+	vars := make(map[string]string, len(globalVars)+len(authVars))
+	for name, value := range globalVars {
+		vars[name] = value
+	}
+	for name, value := range authVars {
+		vars[name] = value
+	}
+	file.GlobalVars = vars
+
+	// Token requests come first, ahead of any operation tagged "auth". Their
+	// IDs give way to those of the operations, which chained variables use.
+	all := append(append([]models.HTTPRequest{}, file.Requests...), requests...)
+	uniqueIDs(all)
+	file.Requests = append(all[len(file.Requests):], all[:len(file.Requests)]...)
+
+	for tag, other := range files {
+		if tag != authFileTag && sendsToken(other) {
+			if other.SharedVars == nil {
+				other.SharedVars = make(map[string]string)
+			}
+			other.SharedVars["authToken"] = "your_auth_token"
+		}
+	}
+}
+
+// sendsToken reports whether requests of a file send the {{authToken}} of the auth file
+func sendsToken(file *models.HTTPFile) bool {
+	for _, req := range file.Requests {
+		if req.Auth != nil && req.Auth.Token == variable("authToken") {
+			return true
+		}
+	}
+	return false
+}
+
+// authRequests creates the token requests of every OAuth2 and OpenID Connect
+// scheme, along with the variables they use
+func authRequests(schemes map[string]models.SecurityScheme) ([]models.HTTPRequest, map[string]string) {
+	names := make([]string, 0, len(schemes))
+	for name := range schemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var requests []models.HTTPRequest
+	vars := make(map[string]string)
+
+	for _, name := range names {
+		scheme := schemes[name]
+		prefix := variableName(name)
+
+		switch strings.ToLower(scheme.Type) {
+		case "oauth2":
+			flows := oauthFlows(scheme)
+			if flow := flows.ClientCredentials; flow != nil {
+				requests = append(requests, tokenRequest(name, "client credentials", scheme, flow.TokenURL, [][2]string{
+					{"grant_type", "client_credentials"},
+					{"client_id", variable(prefix + "ClientId")},
+					{"client_secret", variable(prefix + "ClientSecret")},
+					{"scope", scopeValue(flow.Scopes)},
+				}))
+				addClientVars(vars, prefix)
+			}
+			if flow := flows.Password; flow != nil {
+				requests = append(requests, tokenRequest(name, "password", scheme, flow.TokenURL, [][2]string{
+					{"grant_type", "password"},
+					{"username", variable(prefix + "Username")},
+					{"password", variable(prefix + "Password")},
+					{"client_id", variable(prefix + "ClientId")},
+					{"client_secret", variable(prefix + "ClientSecret")},
+					{"scope", scopeValue(flow.Scopes)},
+				}))
+				addClientVars(vars, prefix)
+				vars[prefix+"Username"] = "username"
+				vars[prefix+"Password"] = "password"
+			}
+			if flow := flows.AuthorizationCode; flow != nil {
+				authorize := authorizeRequest(name, "authorization code", flow, "code", prefix)
+				authorize.Description = fmt.Sprintf("Open this URL in a browser, then copy the code parameter of the redirect into @%sAuthorizationCode", prefix)
+				requests = append(requests, authorize)
+				requests = append(requests, tokenRequest(name, "authorization code", scheme, flow.TokenURL, [][2]string{
+					{"grant_type", "authorization_code"},
+					{"code", variable(prefix + "AuthorizationCode")},
+					{"redirect_uri", variable(prefix + "RedirectUri")},
+					{"client_id", variable(prefix + "ClientId")},
+					{"client_secret", variable(prefix + "ClientSecret")},
+				}))
+				addClientVars(vars, prefix)
+				vars[prefix+"RedirectUri"] = defaultRedirectURI
+				vars[prefix+"AuthorizationCode"] = "your_authorization_code"
+			}
+			if flow := flows.Implicit; flow != nil {
+				authorize := authorizeRequest(name, "implicit", flow, "token", prefix)
+				authorize.Description = "Open this URL in a browser, then copy the access_token of the redirect into @authToken"
+				requests = append(requests, authorize)
+				vars[prefix+"ClientId"] = "your_client_id"
+				vars[prefix+"RedirectUri"] = defaultRedirectURI
+			}
+		case "openidconnect":
+			if scheme.OpenIDConnectURL == "" {
+				continue
+			}
+			endpoint := prefix + "TokenEndpoint"
+			requests = append(requests, models.HTTPRequest{
+				Name:        fmt.Sprintf("Discover %s endpoints", name),
+				ID:          identifier("discover " + name),
+				Method:      "GET",
+				Path:        scheme.OpenIDConnectURL,
+				Headers:     map[string]string{"Accept": "application/json"},
+				Description: "Captures the token endpoint of the OpenID Connect provider",
+				Tag:         authFileTag,
+				Captures:    []models.ResponseCapture{{Variable: endpoint, Path: "token_endpoint"}},
+			})
+			requests = append(requests, tokenRequest(name, "client credentials", scheme, variable(endpoint), [][2]string{
+				{"grant_type", "client_credentials"},
+				{"client_id", variable(prefix + "ClientId")},
+				{"client_secret", variable(prefix + "ClientSecret")},
+				{"scope", "openid"},
+			}))
+			addClientVars(vars, prefix)
+		}
+	}
+
+	return requests, vars
+}
+
+// oauthFlows returns the OAuth2 flows of a scheme, mapping the single
+// Swagger v2 flow onto its OpenAPI v3 equivalent
+func oauthFlows(scheme models.SecurityScheme) models.OAuthFlows {
+	if scheme.Flows != nil {
+		return *scheme.Flows
+	}
+
+	flow := &models.OAuthFlow{
+		AuthorizationURL: scheme.AuthorizationURL,
+		TokenURL:         scheme.TokenURL,
+		Scopes:           scheme.Scopes,
+	}
+
+	var flows models.OAuthFlows
+	switch scheme.Flow {
+	case "application":
+		flows.ClientCredentials = flow
+	case "password":
+		flows.Password = flow
+	case "accessCode":
+		flows.AuthorizationCode = flow
+	case "implicit":
+		flows.Implicit = flow
+	}
+	return flows
+}
+
+// tokenRequest creates a request to a token endpoint whose access token is
+// captured into {{authToken}}. Empty parameters are left out of the form.
+func tokenRequest(name, flow string, scheme models.SecurityScheme, tokenURL string, params [][2]string) models.HTTPRequest {
+	var form []string
+	for _, param := range params {
+		if param[1] != "" {
+			form = append(form, param[0]+"="+param[1])
+		}
+	}
+
+	return models.HTTPRequest{
+		Name:   fmt.Sprintf("Get %s token (%s)", name, flow),
+		ID:     identifier(fmt.Sprintf("get %s token %s", name, flow)),
+		Method: "POST",
+		Path:   tokenURL,
+		Headers: map[string]string{
+			"Content-Type": "application/x-www-form-urlencoded",
+			"Accept":       "application/json",
+		},
+		Body:        strings.Join(form, "&"),
+		Description: scheme.Description,
+		Tag:         authFileTag,
+		Captures:    []models.ResponseCapture{{Variable: "authToken", Path: "access_token"}},
+	}
+}
+
+// authorizeRequest creates the authorization request of a browser based flow
+func authorizeRequest(name, flow string, oauthFlow *models.OAuthFlow, responseType, prefix string) models.HTTPRequest {
+	query := []string{
+		"response_type=" + responseType,
+		"client_id=" + variable(prefix+"ClientId"),
+		"redirect_uri=" + variable(prefix+"RedirectUri"),
+	}
+	if scope := scopeValue(oauthFlow.Scopes); scope != "" {
+		query = append(query, "scope="+scope)
+	}
+
+	separator := "?"
+	if strings.Contains(oauthFlow.AuthorizationURL, "?") {
+		separator = "&"
+	}
+
+	return models.HTTPRequest{
+		Name:    fmt.Sprintf("Authorize %s (%s)", name, flow),
+		ID:      identifier(fmt.Sprintf("authorize %s %s", name, flow)),
+		Method:  "GET",
+		Path:    oauthFlow.AuthorizationURL + separator + strings.Join(query, "&"),
+		Headers: map[string]string{},
+		Tag:     authFileTag,
+	}
+}
+
+// addClientVars adds the client credential variables of a scheme
+func addClientVars(vars map[string]string, prefix string) {
+	vars[prefix+"ClientId"] = "your_client_id"
+	vars[prefix+"ClientSecret"] = "your_client_secret"
+}
+
+// scopeValue joins the scopes of a flow into a percent-encoded scope parameter
+func scopeValue(scopes map[string]string) string {
+	names := make([]string, 0, len(scopes))
+	for name := range scopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return escapeQuery(strings.Join(names, " "), false)
+}
+
+// variable returns a reference to a .http variable
+func variable(name string) string {
+	return fmt.Sprintf("{{%s}}", name)
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/adapters/swagger"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+func TestAuthRequests_SwaggerV2Flows(t *testing.T) {
+	scopes := map[string]string{"write:pets": "Modify pets", "read:pets": "Read pets"}
+
+	tests := []struct {
+		name     string
+		scheme   models.SecurityScheme
+		requests []string
+		body     string
+		path     string
+	}{
+		{
+			name:     "application",
+			scheme:   models.SecurityScheme{Type: "oauth2", Flow: "application", TokenURL: "https://auth.example.com/token", Scopes: scopes},
+			requests: []string{"Get petstore_auth token (client credentials)"},
+			body:     "grant_type=client_credentials&client_id={{petstore_authClientId}}&client_secret={{petstore_authClientSecret}}&scope=read%3Apets%20write%3Apets",
+			path:     "https://auth.example.com/token",
+		},
+		{
+			name:     "password",
+			scheme:   models.SecurityScheme{Type: "oauth2", Flow: "password", TokenURL: "/oauth/token"},
+			requests: []string{"Get petstore_auth token (password)"},
+			body:     "grant_type=password&username={{petstore_authUsername}}&password={{petstore_authPassword}}&client_id={{petstore_authClientId}}&client_secret={{petstore_authClientSecret}}",
+			path:     "/oauth/token",
+		},
+		{
+			name:     "accessCode",
+			scheme:   models.SecurityScheme{Type: "oauth2", Flow: "accessCode", AuthorizationURL: "https://auth.example.com/authorize", TokenURL: "https://auth.example.com/token"},
+			requests: []string{"Authorize petstore_auth (authorization code)", "Get petstore_auth token (authorization code)"},
+			path:     "https://auth.example.com/authorize?response_type=code&client_id={{petstore_authClientId}}&redirect_uri={{petstore_authRedirectUri}}",
+		},
+		{
+			name:     "implicit",
+			scheme:   models.SecurityScheme{Type: "oauth2", Flow: "implicit", AuthorizationURL: "https://auth.example.com/authorize?audience=api", Scopes: scopes},
+			requests: []string{"Authorize petstore_auth (implicit)"},
+			path:     "https://auth.example.com/authorize?audience=api&response_type=token&client_id={{petstore_authClientId}}&redirect_uri={{petstore_authRedirectUri}}&scope=read%3Apets%20write%3Apets",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests, vars := authRequests(map[string]models.SecurityScheme{"petstore_auth": tt.scheme})
+
+			var names []string
+			for _, request := range requests {
+				names = append(names, request.Name)
+			}
+			if !reflect.DeepEqual(names, tt.requests) {
+				t.Fatalf("Expected requests %v, got %v", tt.requests, names)
+			}
+
+			first := requests[0]
+			if first.Path != tt.path {
+				t.Errorf("Expected path %q, got %q", tt.path, first.Path)
+			}
+			if tt.body != "" && first.Body != tt.body {
+				t.Errorf("Expected body %q, got %q", tt.body, first.Body)
+			}
+			if _, ok := vars["petstore_authClientId"]; !ok {
+				t.Errorf("Expected the client id variable, got %v", vars)
+			}
+
+			// The last request obtains the token, unless the flow returns it to the browser
+			last := requests[len(requests)-1]
+			captures := []models.ResponseCapture{{Variable: "authToken", Path: "access_token"}}
+			if tt.name == "implicit" {
+				captures = nil
+			}
+			if !reflect.DeepEqual(last.Captures, captures) {
+				t.Errorf("Expected captures %v, got %v", captures, last.Captures)
+			}
+		})
+	}
+}
+
+func TestGenerator_GenerateAuthFile(t *testing.T) {
+	generator := New(swagger.New())
+
+	doc := &models.SwaggerDoc{
+		OpenAPI: "3.0.0",
+		Components: &models.Components{
+			SecuritySchemes: map[string]models.SecurityScheme{
+				"oauth": {
+					Type: "oauth2",
+					Flows: &models.OAuthFlows{
+						ClientCredentials: &models.OAuthFlow{TokenURL: "https://auth.example.com/token"},
+					},
+				},
+			},
+		},
+		Security: []map[string][]string{{"oauth": {}}},
+		Paths: map[string]models.PathItem{
+			"/logout": {
+				Post: &models.Operation{Tags: []string{"auth"}, OperationID: "logout"},
+			},
+			"/pets": {
+				Get: &models.Operation{Tags: []string{"pets"}, OperationID: "listPets"},
+			},
+		},
+	}
+
+	files, err := generator.Generate(doc, "https://api.example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate HTTP files: %v", err)
+	}
+
+	auth, ok := files["auth"]
+	if !ok {
+		t.Fatalf("Expected an auth file")
+	}
+	if len(auth.Requests) != 2 || !strings.HasPrefix(auth.Requests[0].Name, "Get oauth token") || auth.Requests[1].Name != "Logout" {
+		t.Errorf("Expected the token request before the auth operations, got %+v", auth.Requests)
+	}
+	if auth.GlobalVars["oauthClientId"] == "" {
+		t.Errorf("Expected client variables in the auth file, got %v", auth.GlobalVars)
+	}
+
+	pets := files["pets"]
+	if _, ok := pets.GlobalVars["oauthClientId"]; ok {
+		t.Errorf("Client variables should only be defined in the auth file")
+	}
+	if _, ok := pets.GlobalVars["authToken"]; ok {
+		t.Errorf("authToken should be left to the value captured by the auth file")
+	}
+	if got := pets.Requests[0].Headers["Authorization"]; got != "Bearer {{authToken}}" {
+		t.Errorf("Expected bearer token header, got %q", got)
+	}
+}
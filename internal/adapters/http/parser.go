@@ -0,0 +1,543 @@
+package http
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+var (
+	// fileVarPattern matches file variable definitions, e.g. @baseUrl = http://localhost
+	fileVarPattern = regexp.MustCompile(`^@([A-Za-z0-9_.-]+)\s*=\s?(.*)$`)
+
+	// requestLinePattern matches request lines, e.g. GET {{baseUrl}}/pets HTTP/1.1
+	requestLinePattern = regexp.MustCompile(`^([A-Z]+)\s+(\S.*?)(?:\s+HTTP/[0-9.]+)?$`)
+
+	// metadataPattern matches request metadata comments, e.g. # @name createPet
+	metadataPattern = regexp.MustCompile(`^@([A-Za-z-]+)(?:\s*=?\s*(.*))?$`)
+
+	// varCommentPattern matches the comments written above variables, e.g. # region: one of: eu, us
+	varCommentPattern = regexp.MustCompile(`^([A-Za-z0-9_.-]+): (.+)$`)
+
+	// requestReferencePattern matches the values read from the responses of
+	// named requests, e.g. {{createPet.response.body.$.id}}
+	requestReferencePattern = regexp.MustCompile(`^\{\{([A-Za-z0-9_]+)\.response\.body\.\$\.([^{}]+)\}\}$`)
+
+	// globalSetPattern matches the values response handlers capture, e.g.
+	// client.global.set("petId", response.body.id)
+	globalSetPattern = regexp.MustCompile(`client\.global\.set\(("(?:[^"\\]|\\.)*")\s*,\s*response\.body((?:\.[A-Za-z_$][A-Za-z0-9_$]*|\["(?:[^"\\]|\\.)*"\])*)\s*\)`)
+
+	// globalDefaultPattern matches the defaults of global variables that
+	// pre-request scripts set until a response handler captures them, e.g.
+	// if (client.global.get("petId") == null) client.global.set("petId", "0");
+	globalDefaultPattern = regexp.MustCompile(`^if \(client\.global\.get\(("(?:[^"\\]|\\.)*")\) == null\) client\.global\.set\(("(?:[^"\\]|\\.)*"), ("(?:[^"\\]|\\.)*")\);$`)
+
+	// generatedTestPattern matches the first line of the tests the formatter
+	// writes for the expected response, e.g. client.test("Status is 201", function() {
+	generatedTestPattern = regexp.MustCompile(`^client\.test\("(?:Status is [^"]*|Content-Type is [^"]*|Body has required fields)", function\(\) \{$`)
+
+	// generatedAssertionPattern matches the httpyac assertions the formatter
+	// writes for the expected response, e.g. ?? status == 201
+	generatedAssertionPattern = regexp.MustCompile(`^\?\? (?:status (?:==|>=|<) \d+|header content-type includes \S+|js response\.parsedBody\S* exists)$`)
+
+	// propertyPattern matches the property accessors of a JavaScript expression
+	propertyPattern = regexp.MustCompile(`\.([A-Za-z_$][A-Za-z0-9_$]*)|\[("(?:[^"\\]|\\.)*")\]`)
+)
+
+// Parser reads .http and .rest files, in the syntax of the JetBrains HTTP
+// Client and VS Code REST Client, back into HTTP files
+type Parser struct{}
+
+// ParseFile parses a .http or .rest file. The requests are tagged with the
+// file name without extension, e.g. pets for pets.http.
+func (p *Parser) ParseFile(path string) (*models.HTTPFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HTTP file: %w", err)
+	}
+
+	file, err := p.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	file.Tag = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	for i := range file.Requests {
+		file.Requests[i].Tag = file.Tag
+	}
+	return file, nil
+}
+
+// requestState is the part of a request block being read
+type requestState int
+
+const (
+	statePreamble  requestState = iota // separator, comments and metadata
+	statePreScript                     // pre-request script
+	stateHeaders                       // request line and headers
+	stateBody                          // body, after the blank line ending the headers
+	stateHandler                       // response handler script
+)
+
+// blockParser reads the request blocks of a file, separated by ### lines
+type blockParser struct {
+	file       *models.HTTPFile
+	state      requestState
+	block      bool              // a ### separator started the block
+	name       string            // request name of the ### separator
+	vars       map[string]string // variables defined in the block
+	id         string            // request name of the @name metadata
+	deprecated bool              // set by the @deprecated metadata
+	generated  map[string]string // body fingerprints of the @generated metadata, by request ID
+	defaults   map[string]string // defaults of global variables set by pre-request scripts
+	comments   []string          // comments before the request line
+	preScript  []string          // pre-request script
+	request    *models.HTTPRequest
+	body       []string
+	script     []string
+	response   []string // response lines other than handler scripts
+}
+
+// Parse parses the content of a .http file: file variables, requests with
+// their name, description, headers and body, and the values that response
+// handlers capture. Values read from named requests, as VS Code REST Client
+// does, become response variables.
+func (p *Parser) Parse(data []byte) (*models.HTTPFile, error) {
+	b := &blockParser{
+		file: &models.HTTPFile{
+			GlobalVars:   make(map[string]string),
+			VarComments:  make(map[string]string),
+			ResponseVars: make(map[string]models.ResponseRef),
+			Requests:     []models.HTTPRequest{},
+		},
+	}
+
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	for i, line := range strings.Split(content, "\n") {
+		if err := b.parseLine(line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+	b.endBlock()
+
+	b.file.BaseURL = b.file.GlobalVars["baseUrl"]
+	for i, req := range b.file.Requests {
+		b.file.Requests[i].Generated = b.generated[req.ID]
+	}
+
+	// Global variables that no request of the file captures are captured by
+	// requests of other files
+	captured := make(map[string]bool)
+	for _, req := range b.file.Requests {
+		for _, capture := range req.Captures {
+			captured[capture.Variable] = true
+		}
+	}
+	for name, value := range b.defaults {
+		if !captured[name] {
+			if b.file.SharedVars == nil {
+				b.file.SharedVars = make(map[string]string)
+			}
+			b.file.SharedVars[name] = value
+		}
+	}
+	return b.file, nil
+}
+
+// parseLine reads a line of the file
+func (b *blockParser) parseLine(line string) error {
+	if strings.HasPrefix(line, "###") {
+		b.endBlock()
+		b.block = true
+		b.name = strings.TrimSpace(strings.TrimPrefix(line, "###"))
+		return nil
+	}
+
+	trimmed := strings.TrimSpace(line)
+	switch b.state {
+	case statePreamble:
+		if strings.HasPrefix(trimmed, "< {%") {
+			script := strings.TrimPrefix(trimmed, "< {%")
+			if strings.HasSuffix(script, "%}") {
+				b.preScript = append(b.preScript, strings.TrimSuffix(script, "%}"))
+				return nil
+			}
+			b.state = statePreScript
+			b.preScript = append(b.preScript, script)
+			return nil
+		}
+		return b.parsePreamble(trimmed)
+
+	case statePreScript:
+		if strings.HasSuffix(trimmed, "%}") {
+			b.preScript = append(b.preScript, strings.TrimSuffix(trimmed, "%}"))
+			b.state = statePreamble
+			return nil
+		}
+		b.preScript = append(b.preScript, line)
+
+	case stateHeaders:
+		switch {
+		case trimmed == "":
+			b.state = stateBody
+		case isResponsePart(trimmed):
+			// Requests without body can be followed by their handler directly
+			b.state = stateBody
+			return b.parseLine(line)
+		case isComment(trimmed):
+			// Commented out headers are ignored
+		case len(b.request.Headers) == 0 && line != trimmed && (trimmed[0] == '?' || trimmed[0] == '&'):
+			// Query parameters continued on the next lines
+			b.request.Path += trimmed
+		default:
+			name, value, ok := strings.Cut(trimmed, ":")
+			if !ok || strings.TrimSpace(name) == "" {
+				return fmt.Errorf("invalid header %q", trimmed)
+			}
+			b.request.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+
+	case stateBody:
+		switch {
+		case strings.HasPrefix(trimmed, "> {%"):
+			script := strings.TrimPrefix(trimmed, "> {%")
+			if strings.HasSuffix(script, "%}") {
+				b.script = append(b.script, strings.TrimSuffix(script, "%}"))
+				return nil
+			}
+			b.state = stateHandler
+			b.script = append(b.script, script)
+		case isResponsePart(trimmed):
+			// Assertions, response references and handler files are not part of the body
+			b.response = append(b.response, trimmed)
+		default:
+			b.body = append(b.body, line)
+		}
+
+	case stateHandler:
+		if strings.HasSuffix(trimmed, "%}") {
+			b.script = append(b.script, strings.TrimSuffix(trimmed, "%}"))
+			b.state = stateBody
+			return nil
+		}
+		b.script = append(b.script, line)
+	}
+	return nil
+}
+
+// parsePreamble reads a line before the request line: comments, metadata and
+// file variables, or the request line itself
+func (b *blockParser) parsePreamble(line string) error {
+	switch {
+	case line == "":
+		return nil
+
+	case isComment(line):
+		comment := strings.TrimSpace(strings.TrimLeft(line, "#/"))
+		if match := metadataPattern.FindStringSubmatch(comment); match != nil {
+			switch match[1] {
+			case "name":
+				b.id = strings.TrimSpace(match[2])
+			case "deprecated":
+				b.deprecated = true
+			case "generated":
+				b.addGenerated(match[2])
+			}
+			return nil
+		}
+		b.comments = append(b.comments, comment)
+		return nil
+
+	case strings.HasPrefix(line, "@"):
+		match := fileVarPattern.FindStringSubmatch(line)
+		if match == nil {
+			return fmt.Errorf("invalid variable %q", line)
+		}
+		b.addVar(match[1], strings.TrimSpace(match[2]))
+		return nil
+	}
+
+	method, url := "GET", line
+	if match := requestLinePattern.FindStringSubmatch(line); match != nil {
+		method, url = match[1], match[2]
+	} else if !strings.Contains(line, "://") && !strings.HasPrefix(line, "{{") && !strings.HasPrefix(line, "/") {
+		return fmt.Errorf("invalid request line %q", line)
+	}
+
+	// Paths are relative to the base URL, as the formatter writes them
+	if rest := strings.TrimPrefix(url, "{{baseUrl}}"); rest != url && strings.HasPrefix(rest, "/") {
+		url = rest
+	}
+
+	// The defaults of global variables are the values of request variables
+	defaults, preScript := scriptDefaults(b.preScript)
+	for name, value := range defaults {
+		if b.defaults == nil {
+			b.defaults = make(map[string]string)
+		}
+		b.defaults[name] = value
+		if _, ok := b.vars[name]; !ok {
+			if b.vars == nil {
+				b.vars = make(map[string]string)
+			}
+			b.vars[name] = value
+		}
+	}
+
+	b.request = &models.HTTPRequest{
+		Name:        b.name,
+		ID:          b.id,
+		Method:      method,
+		Path:        url,
+		Headers:     make(map[string]string),
+		Description: strings.Join(b.comments, "\n"),
+		Vars:        b.vars,
+		Deprecated:  b.deprecated,
+		PreScript:   preScript,
+	}
+	if b.request.Name == "" {
+		b.request.Name = fmt.Sprintf("%s %s", method, url)
+	}
+	b.state = stateHeaders
+	return nil
+}
+
+// addGenerated records the requests listed by @generated metadata. Those
+// without body fingerprint were generated without body.
+func (b *blockParser) addGenerated(list string) {
+	if b.generated == nil {
+		b.generated = make(map[string]string)
+	}
+	for _, entry := range strings.Fields(list) {
+		id, fingerprint, ok := strings.Cut(entry, "=")
+		if !ok {
+			fingerprint = bodyFingerprint(models.HTTPRequest{})
+		}
+		b.generated[id] = fingerprint
+	}
+}
+
+// addVar adds a variable. Variables of a request block are request
+// variables, the others file variables. The comment above a file variable,
+// written as "name: text", is the variable's comment; the other comments are dropped.
+func (b *blockParser) addVar(name, value string) {
+	if b.block && !requestReferencePattern.MatchString(value) {
+		if b.vars == nil {
+			b.vars = make(map[string]string)
+		}
+		b.vars[name] = value
+		return
+	}
+
+	if n := len(b.comments); n > 0 {
+		if match := varCommentPattern.FindStringSubmatch(b.comments[n-1]); match != nil && match[1] == name {
+			b.file.VarComments[name] = match[2]
+		}
+	}
+	b.comments = nil
+
+	if match := requestReferencePattern.FindStringSubmatch(value); match != nil {
+		b.file.ResponseVars[name] = models.ResponseRef{Request: match[1], Path: match[2]}
+		return
+	}
+	b.file.GlobalVars[name] = value
+}
+
+// endBlock adds the request of the current block, if any, and starts a new block
+func (b *blockParser) endBlock() {
+	if b.request != nil {
+		// Trailing blank lines separate the requests
+		for len(b.body) > 0 && strings.TrimSpace(b.body[len(b.body)-1]) == "" {
+			b.body = b.body[:len(b.body)-1]
+		}
+		body := strings.Join(b.body, "\n")
+		if path, ok := strings.CutPrefix(body, "< "); ok && !strings.Contains(path, "\n") {
+			b.request.BodyFile = strings.TrimSpace(path)
+		} else if parts := formParts(b.request.Headers, body); len(parts) > 0 {
+			b.request.FormParts = parts
+		} else {
+			b.request.Body = body
+		}
+
+		b.request.Captures = scriptCaptures(strings.Join(b.script, "\n"))
+		b.request.Script = customScript(b.script)
+		for _, line := range b.response {
+			if !generatedAssertionPattern.MatchString(line) {
+				b.request.Assertions = append(b.request.Assertions, line)
+			}
+		}
+		b.file.Requests = append(b.file.Requests, *b.request)
+	} else {
+		// Blocks without request only define variables
+		for name, value := range b.vars {
+			b.file.GlobalVars[name] = value
+		}
+	}
+
+	*b = blockParser{file: b.file, generated: b.generated, defaults: b.defaults}
+}
+
+// formParts splits a multipart/form-data body into its parts, separated by
+// the boundary of the Content-Type header. Parts whose content is a single
+// "< path" line are read from that file. It returns nil for other bodies.
+func formParts(headers map[string]string, body string) []models.FormPart {
+	var boundary string
+	for name, value := range headers {
+		if strings.EqualFold(name, "Content-Type") {
+			if mediaType, params, err := mime.ParseMediaType(value); err == nil && mediaType == "multipart/form-data" {
+				boundary = params["boundary"]
+			}
+		}
+	}
+	if boundary == "" {
+		return nil
+	}
+
+	var parts []models.FormPart
+	var part *models.FormPart
+	var content []string
+	inHeaders := false
+	endPart := func() {
+		if part == nil {
+			return
+		}
+		value := strings.Join(content, "\n")
+		if path, ok := strings.CutPrefix(value, "< "); ok && !strings.Contains(path, "\n") {
+			part.File = strings.TrimSpace(path)
+		} else {
+			part.Value = value
+		}
+		parts = append(parts, *part)
+		part, content = nil, nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		switch {
+		case line == "--"+boundary || line == "--"+boundary+"--":
+			endPart()
+			if line == "--"+boundary {
+				part, inHeaders = &models.FormPart{}, true
+			}
+		case part == nil:
+			// Preamble and epilogue
+		case inHeaders && line == "":
+			inHeaders = false
+		case inHeaders:
+			name, value, _ := strings.Cut(line, ":")
+			value = strings.TrimSpace(value)
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "content-disposition":
+				if _, params, err := mime.ParseMediaType(value); err == nil {
+					part.Name, part.FileName = params["name"], params["filename"]
+				}
+			case "content-type":
+				part.ContentType = value
+			}
+		default:
+			content = append(content, line)
+		}
+	}
+	endPart()
+	return parts
+}
+
+// scriptCaptures returns the values a response handler stores in global variables
+func scriptCaptures(script string) []models.ResponseCapture {
+	var captures []models.ResponseCapture
+	for _, match := range globalSetPattern.FindAllStringSubmatch(script, -1) {
+		variable, err := strconv.Unquote(match[1])
+		if err != nil {
+			continue
+		}
+
+		var path []string
+		for _, property := range propertyPattern.FindAllStringSubmatch(match[2], -1) {
+			name := property[1]
+			if property[2] != "" {
+				if name, err = strconv.Unquote(property[2]); err != nil {
+					break
+				}
+			}
+			path = append(path, name)
+		}
+		if len(path) == 0 {
+			continue
+		}
+		captures = append(captures, models.ResponseCapture{Variable: variable, Path: strings.Join(path, ".")})
+	}
+	return captures
+}
+
+// scriptDefaults returns the defaults a pre-request script sets for global
+// variables, and the statements written by hand
+func scriptDefaults(script []string) (map[string]string, string) {
+	defaults := make(map[string]string)
+	var lines []string
+	for _, line := range script {
+		if match := globalDefaultPattern.FindStringSubmatch(strings.TrimSpace(line)); match != nil && match[1] == match[2] {
+			name, nameErr := strconv.Unquote(match[1])
+			value, valueErr := strconv.Unquote(match[3])
+			if nameErr == nil && valueErr == nil {
+				defaults[name] = value
+				continue
+			}
+		}
+		lines = append(lines, line)
+	}
+	return defaults, trimBlankLines(lines)
+}
+
+// customScript returns the statements of a response handler written by hand.
+// The captures and the tests the formatter writes are left out, they are
+// written again from the captures and the expected response of the request.
+func customScript(script []string) string {
+	var lines []string
+	inTest := false
+	for _, line := range script {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case inTest:
+			inTest = trimmed != "});"
+		case generatedTestPattern.MatchString(trimmed):
+			inTest = true
+		case trimmed != "" && globalSetPattern.FindString(trimmed) == strings.TrimSuffix(trimmed, ";"):
+			// Captures
+		default:
+			lines = append(lines, line)
+		}
+	}
+	return trimBlankLines(lines)
+}
+
+// trimBlankLines joins script lines, without the blank lines around them
+func trimBlankLines(lines []string) string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isResponsePart reports whether a line handles the response: a response
+// handler, a response reference or an httpyac assertion
+func isResponsePart(line string) bool {
+	return strings.HasPrefix(line, "> ") || strings.HasPrefix(line, "<> ") || strings.HasPrefix(line, "??")
+}
+
+// isComment reports whether a line is a # or // comment
+func isComment(line string) bool {
+	return strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//")
+}
+
+// NewParser creates a new Parser instance
+func NewParser() *Parser {
+	return &Parser{}
+}
@@ -0,0 +1,309 @@
+package http
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/application/parser"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+var _ parser.HTTPParser = (*Parser)(nil)
+
+func TestParser_Parse(t *testing.T) {
+	content := "# Global variables\r\n" +
+		"@baseUrl = https://api.example.com\r\n" +
+		"# region: eu or us\r\n" +
+		"@region = eu\r\n" +
+		"@petId = {{createPet.response.body.$.id}}\r\n" +
+		"\r\n" +
+		"### Create a pet\r\n" +
+		"# @name createPet\r\n" +
+		"# @deprecated\r\n" +
+		"# Adds a pet\r\n" +
+		"// to the store\r\n" +
+		"POST {{baseUrl}}/pets HTTP/1.1\r\n" +
+		"Content-Type: application/json\r\n" +
+		"# X-Debug: true\r\n" +
+		"Authorization: Bearer {{authToken}}\r\n" +
+		"\r\n" +
+		"{\r\n" +
+		"  \"name\": \"Rex\"\r\n" +
+		"}\r\n" +
+		"\r\n" +
+		"> {%\r\n" +
+		"    client.global.set(\"petName\", response.body.name);\r\n" +
+		"    client.global.set(\"firstName\", response.body.owner[\"first-name\"]);\r\n" +
+		"%}\r\n" +
+		"\r\n" +
+		"### Upload a photo\r\n" +
+		"@photoId = 7\r\n" +
+		"@size = {{defaultSize}}\r\n" +
+		"PUT {{baseUrl}}/pets/{{petId}}/photo/{{photoId}}?size={{size}}\r\n" +
+		"Content-Type: image/png\r\n" +
+		"\r\n" +
+		"< ./photo.png\r\n" +
+		"\r\n" +
+		"###\r\n" +
+		"https://status.example.com/health\r\n" +
+		"    ?verbose=true\r\n" +
+		"    &region={{region}}\r\n" +
+		"> {% client.global.set(\"status\", response.body.status); %}\r\n"
+
+	file, err := NewParser().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := &models.HTTPFile{
+		BaseURL:      "https://api.example.com",
+		GlobalVars:   map[string]string{"baseUrl": "https://api.example.com", "region": "eu"},
+		VarComments:  map[string]string{"region": "eu or us"},
+		ResponseVars: map[string]models.ResponseRef{"petId": {Request: "createPet", Path: "id"}},
+		Requests: []models.HTTPRequest{
+			{
+				Name:        "Create a pet",
+				ID:          "createPet",
+				Method:      "POST",
+				Path:        "/pets",
+				Headers:     map[string]string{"Content-Type": "application/json", "Authorization": "Bearer {{authToken}}"},
+				Body:        "{\n  \"name\": \"Rex\"\n}",
+				Description: "Adds a pet\nto the store",
+				Deprecated:  true,
+				Captures: []models.ResponseCapture{
+					{Variable: "petName", Path: "name"},
+					{Variable: "firstName", Path: "owner.first-name"},
+				},
+			},
+			{
+				Name:     "Upload a photo",
+				Method:   "PUT",
+				Path:     "/pets/{{petId}}/photo/{{photoId}}?size={{size}}",
+				Headers:  map[string]string{"Content-Type": "image/png"},
+				BodyFile: "./photo.png",
+				Vars:     map[string]string{"photoId": "7", "size": "{{defaultSize}}"},
+			},
+			{
+				Name:     "GET https://status.example.com/health",
+				Method:   "GET",
+				Path:     "https://status.example.com/health?verbose=true&region={{region}}",
+				Headers:  map[string]string{},
+				Captures: []models.ResponseCapture{{Variable: "status", Path: "status"}},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(file, expected) {
+		t.Errorf("Expected %+v\ngot %+v", expected, file)
+	}
+}
+
+func TestParser_ParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "invalid header",
+			content: "### Get pets\nGET /pets\nAccept application/json\n",
+		},
+		{
+			name:    "invalid request line",
+			content: "### Get pets\nget pets\n",
+		},
+		{
+			name:    "invalid variable",
+			content: "@ = value\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewParser().Parse([]byte(tt.content)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestParser_ParseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pets.rest")
+	if err := os.WriteFile(path, []byte("GET https://api.example.com/pets\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	file, err := NewParser().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if file.Tag != "pets" || len(file.Requests) != 1 || file.Requests[0].Tag != "pets" {
+		t.Errorf("Expected a request tagged pets, got %+v", file)
+	}
+
+	if _, err := NewParser().ParseFile(filepath.Join(t.TempDir(), "missing.http")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestParser_Generated(t *testing.T) {
+	content := "@baseUrl = https://api.example.com\n" +
+		"\n" +
+		"# @generated createPet=4ac9712a getPet\n" +
+		"\n" +
+		"### Create a pet\n" +
+		"# @name createPet\n" +
+		"POST {{baseUrl}}/pets\n" +
+		"\n" +
+		"{}\n" +
+		"\n" +
+		"### Get a pet\n" +
+		"# @name getPet\n" +
+		"GET {{baseUrl}}/pets/1\n" +
+		"\n" +
+		"### Ping\n" +
+		"# @name ping\n" +
+		"GET {{baseUrl}}/ping\n"
+
+	file, err := NewParser().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// Requests generated without body get the fingerprint of an empty body
+	expected := map[string]string{"createPet": "4ac9712a", "getPet": bodyFingerprint(models.HTTPRequest{}), "ping": ""}
+	for _, req := range file.Requests {
+		if req.Generated != expected[req.ID] {
+			t.Errorf("Expected %s to have fingerprint %q, got %q", req.ID, expected[req.ID], req.Generated)
+		}
+	}
+	if len(file.Requests) != 3 || len(file.GlobalVars) != 1 {
+		t.Errorf("Unexpected file %+v", file)
+	}
+}
+
+func TestParser_PreScript(t *testing.T) {
+	content := "@baseUrl = https://api.example.com\n" +
+		"\n" +
+		"### Get a visit\n" +
+		"# @name getVisit\n" +
+		"< {%\n" +
+		"    if (client.global.get(\"petId\") == null) client.global.set(\"petId\", \"0\");\n" +
+		"    if (client.global.get(\"visitId\") == null) client.global.set(\"visitId\", \"string\");\n" +
+		"    request.variables.set(\"date\", new Date().toISOString());\n" +
+		"%}\n" +
+		"GET {{baseUrl}}/pets/{{petId}}/visits/{{visitId}}?date={{date}}\n" +
+		"\n" +
+		"> {%\n" +
+		"    client.global.set(\"visitId\", response.body.id);\n" +
+		"%}\n"
+
+	file, err := NewParser().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(file.Requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(file.Requests))
+	}
+
+	// Defaults are request variables, those captured by other files shared ones
+	req := file.Requests[0]
+	if !reflect.DeepEqual(req.Vars, map[string]string{"petId": "0", "visitId": "string"}) {
+		t.Errorf("Expected the defaults as request variables, got %v", req.Vars)
+	}
+	if !reflect.DeepEqual(file.SharedVars, map[string]string{"petId": "0"}) {
+		t.Errorf("Expected petId to be shared, got %v", file.SharedVars)
+	}
+	if req.PreScript != `    request.variables.set("date", new Date().toISOString());` {
+		t.Errorf("Expected the statement written by hand, got %q", req.PreScript)
+	}
+
+	if formatted := NewDialectFormatter(DialectJetBrains).FormatHTTPFile(file); !strings.Contains(formatted, content[strings.Index(content, "###"):]) {
+		t.Errorf("Round trip changed the request:\n%s", formatted)
+	}
+}
+
+func TestParser_FormParts(t *testing.T) {
+	content := "POST {{baseUrl}}/photos\n" +
+		"Content-Type: multipart/form-data; boundary=b1\n" +
+		"\n" +
+		"--b1\n" +
+		"Content-Disposition: form-data; name=\"caption\"\n" +
+		"\n" +
+		"Rex\n" +
+		"at the beach\n" +
+		"--b1\n" +
+		"Content-Disposition: form-data; name=\"photo\"; filename=\"rex.png\"\n" +
+		"Content-Type: image/png\n" +
+		"\n" +
+		"< ./photos/rex.png\n" +
+		"--b1--\n"
+
+	file, err := NewParser().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	req := file.Requests[0]
+	expected := []models.FormPart{
+		{Name: "caption", Value: "Rex\nat the beach"},
+		{Name: "photo", File: "./photos/rex.png", FileName: "rex.png", ContentType: "image/png"},
+	}
+	if req.Body != "" || !reflect.DeepEqual(req.FormParts, expected) {
+		t.Errorf("Expected form parts %+v, got %+v (body %q)", expected, req.FormParts, req.Body)
+	}
+
+	// The parts are written back with the boundary of the request
+	if got := requestBody(req); got+"\n" != content[strings.Index(content, "--b1"):] {
+		t.Errorf("Unexpected multipart body:\n%s", got)
+	}
+}
+
+// TestParser_RoundTrip parses the golden files and expects the formatter to
+// write them back unchanged
+func TestParser_RoundTrip(t *testing.T) {
+	tests := []struct {
+		golden  string
+		dialect string
+	}{
+		{golden: "petstore", dialect: DialectJetBrains},
+		{golden: "petstore-sorted", dialect: DialectJetBrains},
+		{golden: "chaining", dialect: DialectJetBrains},
+		{golden: "polymorphic", dialect: DialectJetBrains},
+		{golden: "refs", dialect: DialectJetBrains},
+		{golden: "security", dialect: DialectJetBrains},
+		{golden: "servers", dialect: DialectJetBrains},
+		{golden: "shared-params", dialect: DialectJetBrains},
+		{golden: "bodies", dialect: DialectJetBrains},
+		{golden: "forms", dialect: DialectJetBrains},
+		{golden: "dialect-rest-client", dialect: DialectRESTClient},
+		{golden: "dialect-rest-client-auth", dialect: DialectRESTClient},
+	}
+
+	for _, tt := range tests {
+		paths, err := filepath.Glob(filepath.Join("..", "..", "..", "test", "golden", tt.golden, "*.http"))
+		if err != nil || len(paths) == 0 {
+			t.Fatalf("No golden files in %s", tt.golden)
+		}
+
+		for _, path := range paths {
+			t.Run(tt.golden+"/"+filepath.Base(path), func(t *testing.T) {
+				want, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("Failed to read golden file: %v", err)
+				}
+
+				file, err := NewParser().ParseFile(path)
+				if err != nil {
+					t.Fatalf("ParseFile failed: %v", err)
+				}
+
+				if got := NewDialectFormatter(tt.dialect).FormatHTTPFile(file); got != string(want) {
+					t.Errorf("Round trip changed the file:\n%s\nwant:\n%s", got, want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package http
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// buildQuery serializes the query parameters into a query string template.
+// Values are referenced as {{variables}}; the returned map holds their
+// example values, already serialized and percent-encoded for the URL.
+func (g *Generator) buildQuery(params []models.Parameter) (string, map[string]string) {
+	var parts []string
+	vars := make(map[string]string)
+
+	for _, param := range params {
+		if param.In != "query" {
+			continue
+		}
+		if g.options.RequiredQueryOnly && !param.Required {
+			continue
+		}
+
+		style, explode := queryStyle(param)
+		value := g.paramExample(param)
+		name := escapeQuery(param.Name, true)
+		varName := variableName(param.Name)
+
+		switch v := value.(type) {
+		case jsonObject:
+			switch {
+			case style == "deepObject":
+				for _, field := range v {
+					fieldVar := varName + "_" + variableName(field.Name)
+					parts = append(parts, fmt.Sprintf("%s[%s]={{%s}}", name, escapeQuery(field.Name, true), fieldVar))
+					vars[fieldVar] = escapeQuery(queryString(field.Value), param.AllowReserved)
+				}
+			case explode:
+				for _, field := range v {
+					fieldVar := varName + "_" + variableName(field.Name)
+					parts = append(parts, fmt.Sprintf("%s={{%s}}", escapeQuery(field.Name, true), fieldVar))
+					vars[fieldVar] = escapeQuery(queryString(field.Value), param.AllowReserved)
+				}
+			default:
+				items := make([]string, 0, len(v)*2)
+				for _, field := range v {
+					items = append(items, escapeQuery(field.Name, param.AllowReserved), escapeQuery(queryString(field.Value), param.AllowReserved))
+				}
+				parts = append(parts, fmt.Sprintf("%s={{%s}}", name, varName))
+				vars[varName] = strings.Join(items, queryDelimiter(style))
+			}
+		case []interface{}:
+			if explode && len(v) > 1 {
+				// Exploded arrays repeat the parameter, with a variable per item
+				for i, item := range v {
+					itemVar := fmt.Sprintf("%s_%d", varName, i+1)
+					parts = append(parts, fmt.Sprintf("%s={{%s}}", name, itemVar))
+					vars[itemVar] = escapeQuery(queryString(item), param.AllowReserved)
+				}
+				continue
+			}
+			parts = append(parts, fmt.Sprintf("%s={{%s}}", name, varName))
+			if explode {
+				// A single item is written as a plain parameter
+				if len(v) > 0 {
+					vars[varName] = escapeQuery(queryString(v[0]), param.AllowReserved)
+				} else {
+					vars[varName] = ""
+				}
+				continue
+			}
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, escapeQuery(queryString(item), param.AllowReserved))
+			}
+			vars[varName] = strings.Join(items, queryDelimiter(style))
+		default:
+			parts = append(parts, fmt.Sprintf("%s={{%s}}", name, varName))
+			vars[varName] = escapeQuery(queryString(v), param.AllowReserved)
+		}
+	}
+
+	return strings.Join(parts, "&"), vars
+}
+
+// queryStyle returns the OpenAPI v3 serialization style of a query parameter
+// and whether it is exploded, mapping the Swagger v2 collectionFormat
+func queryStyle(param models.Parameter) (string, bool) {
+	style := param.Style
+	explode := false
+
+	if style == "" && param.Type != "" {
+		// Swagger v2 parameters default to comma separated values
+		switch param.CollectionFormat {
+		case "ssv":
+			style = "spaceDelimited"
+		case "pipes":
+			style = "pipeDelimited"
+		case "tsv":
+			style = "tabDelimited"
+		case "multi":
+			style, explode = "form", true
+		default:
+			style = "form"
+		}
+		return style, explode
+	}
+
+	if style == "" {
+		style = "form"
+	}
+
+	// Only the form style explodes by default
+	explode = style == "form"
+	if param.Explode != nil {
+		explode = *param.Explode
+	}
+
+	return style, explode
+}
+
+// queryDelimiter returns the separator between the values of a non-exploded parameter
+func queryDelimiter(style string) string {
+	switch style {
+	case "spaceDelimited":
+		return "%20"
+	case "pipeDelimited":
+		return "|"
+	case "tabDelimited":
+		return "%09"
+	default:
+		return ","
+	}
+}
+
+// paramExample builds an example value for a parameter: the example the
+// document gives, or one built from its schema or its Swagger v2 type fields
+func (g *Generator) paramExample(param models.Parameter) interface{} {
+	if example := g.parameterExample(param); example != nil {
+		return example
+	}
+
+	schema := parameterSchema(param)
+	if schema != nil && schema.Ref != "" && g.resolver != nil {
+		resolved, err := g.resolver.ResolveSchema(schema)
+		if err != nil {
+			g.fail(err)
+			return ""
+		}
+		schema = resolved
+	}
+
+	if schema != nil && (schema.Type == "array" || schema.Type == "object" || len(schema.Properties) > 0) {
+		switch {
+		case schema.Example != nil:
+			return g.exampleValue(schema.Example, schema)
+		case param.Default != nil:
+			return g.exampleValue(param.Default, schema)
+		}
+		return g.fakeParam(param, schema, g.schemaExample(schema, make(map[string]bool)))
+	}
+
+	if param.Default != nil {
+		return param.Default
+	}
+	if schema == nil {
+		schema = &models.SchemaObj{Type: "string"}
+	}
+	return g.fakeParam(param, schema, primitiveExample(schema))
+}
+
+// parameterSchema returns the schema of a parameter, building one from the
+// Swagger v2 type fields when the parameter has no schema
+func parameterSchema(param models.Parameter) *models.SchemaObj {
+	if param.Schema != nil {
+		return param.Schema
+	}
+	if param.Type == "" {
+		return nil
+	}
+	return &models.SchemaObj{
+		Type:             param.Type,
+		Format:           param.Format,
+		Items:            param.Items,
+		Enum:             param.Enum,
+		Default:          param.Default,
+		Example:          param.Example,
+		Maximum:          param.Maximum,
+		Minimum:          param.Minimum,
+		ExclusiveMaximum: param.ExclusiveMaximum,
+		ExclusiveMinimum: param.ExclusiveMinimum,
+		MaxLength:        param.MaxLength,
+		MinLength:        param.MinLength,
+		Pattern:          param.Pattern,
+		MaxItems:         param.MaxItems,
+		MinItems:         param.MinItems,
+		MultipleOf:       param.MultipleOf,
+	}
+}
+
+// queryString converts an example value to its textual form. Numbers are
+// written in decimal notation, e.g. 1000000 rather than 1e+06.
+func queryString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// escapeQuery percent-encodes a query string component. Unreserved characters
+// are always kept; RFC 3986 reserved characters are kept when allowReserved is set.
+func escapeQuery(s string, allowReserved bool) string {
+	const reserved = ":/?#[]@!$&'()*+,;="
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		case allowReserved && strings.IndexByte(reserved, c) >= 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// variableName turns a parameter name into a valid .http variable name
+func variableName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if r == '_' || r == '-' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/adapters/swagger"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func TestGenerator_BuildQuery(t *testing.T) {
+	arraySchema := &models.SchemaObj{
+		Type:    "array",
+		Items:   &models.SchemaObj{Type: "string"},
+		Example: []interface{}{"red", "green"},
+	}
+	objectSchema := &models.SchemaObj{
+		Type: "object",
+		Properties: map[string]models.SchemaObj{
+			"role":      {Type: "string", Example: "admin"},
+			"firstName": {Type: "string", Example: "Alex"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		params    []models.Parameter
+		options   Options
+		wantQuery string
+		wantVars  map[string]string
+	}{
+		{
+			name: "primitive parameters",
+			params: []models.Parameter{
+				{Name: "limit", In: "query", Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(100)},
+				{Name: "id", In: "path", Required: true, Type: "string"},
+				{Name: "sort", In: "query", Schema: &models.SchemaObj{Type: "string", Example: "name"}},
+				{Name: "since", In: "query", Schema: &models.SchemaObj{Type: "string", Format: "date"}},
+			},
+			wantQuery: "limit={{limit}}&sort={{sort}}&since={{since}}",
+			wantVars:  map[string]string{"limit": "1", "sort": "name", "since": "2024-01-01"},
+		},
+		{
+			name: "required only",
+			params: []models.Parameter{
+				{Name: "limit", In: "query", Type: "integer"},
+				{Name: "q", In: "query", Required: true, Type: "string", Example: "cat"},
+			},
+			options:   Options{RequiredQueryOnly: true},
+			wantQuery: "q={{q}}",
+			wantVars:  map[string]string{"q": "cat"},
+		},
+		{
+			name:      "form array exploded by default",
+			params:    []models.Parameter{{Name: "color", In: "query", Schema: arraySchema}},
+			wantQuery: "color={{color_1}}&color={{color_2}}",
+			wantVars:  map[string]string{"color_1": "red", "color_2": "green"},
+		},
+		{
+			name:      "exploded array of one item",
+			params:    []models.Parameter{{Name: "color", In: "query", Schema: &models.SchemaObj{Type: "array", Example: []interface{}{"red"}}}},
+			wantQuery: "color={{color}}",
+			wantVars:  map[string]string{"color": "red"},
+		},
+		{
+			name:      "form array not exploded",
+			params:    []models.Parameter{{Name: "color", In: "query", Explode: boolPtr(false), Schema: arraySchema}},
+			wantQuery: "color={{color}}",
+			wantVars:  map[string]string{"color": "red,green"},
+		},
+		{
+			name:      "space delimited array",
+			params:    []models.Parameter{{Name: "color", In: "query", Style: "spaceDelimited", Schema: arraySchema}},
+			wantQuery: "color={{color}}",
+			wantVars:  map[string]string{"color": "red%20green"},
+		},
+		{
+			name:      "pipe delimited array",
+			params:    []models.Parameter{{Name: "color", In: "query", Style: "pipeDelimited", Schema: arraySchema}},
+			wantQuery: "color={{color}}",
+			wantVars:  map[string]string{"color": "red|green"},
+		},
+		{
+			name:      "form object exploded",
+			params:    []models.Parameter{{Name: "filter", In: "query", Schema: objectSchema}},
+			wantQuery: "firstName={{filter_firstName}}&role={{filter_role}}",
+			wantVars:  map[string]string{"filter_firstName": "Alex", "filter_role": "admin"},
+		},
+		{
+			name:      "form object not exploded",
+			params:    []models.Parameter{{Name: "filter", In: "query", Explode: boolPtr(false), Schema: objectSchema}},
+			wantQuery: "filter={{filter}}",
+			wantVars:  map[string]string{"filter": "firstName,Alex,role,admin"},
+		},
+		{
+			name:      "deep object",
+			params:    []models.Parameter{{Name: "filter", In: "query", Style: "deepObject", Explode: boolPtr(true), Schema: objectSchema}},
+			wantQuery: "filter[firstName]={{filter_firstName}}&filter[role]={{filter_role}}",
+			wantVars:  map[string]string{"filter_firstName": "Alex", "filter_role": "admin"},
+		},
+		{
+			name: "reserved characters",
+			params: []models.Parameter{
+				{Name: "redirect", In: "query", Schema: &models.SchemaObj{Type: "string", Example: "/a b?c=d"}},
+				{Name: "path", In: "query", AllowReserved: true, Schema: &models.SchemaObj{Type: "string", Example: "/a b?c=d"}},
+			},
+			wantQuery: "redirect={{redirect}}&path={{path}}",
+			wantVars:  map[string]string{"redirect": "%2Fa%20b%3Fc%3Dd", "path": "/a%20b?c=d"},
+		},
+		{
+			name: "swagger v2 collection formats",
+			params: []models.Parameter{
+				{Name: "csv", In: "query", Type: "array", Items: &models.SchemaObj{Type: "integer"}, Default: []interface{}{1, 2}},
+				{Name: "multi", In: "query", Type: "array", CollectionFormat: "multi", Items: &models.SchemaObj{Type: "integer"}, Default: []interface{}{1, 2}},
+				{Name: "pipes", In: "query", Type: "array", CollectionFormat: "pipes", Items: &models.SchemaObj{Type: "integer"}, Default: []interface{}{1, 2}},
+			},
+			wantQuery: "csv={{csv}}&multi={{multi_1}}&multi={{multi_2}}&pipes={{pipes}}",
+			wantVars:  map[string]string{"csv": "1,2", "multi_1": "1", "multi_2": "2", "pipes": "1|2"},
+		},
+		{
+			name: "numbers without exponent",
+			params: []models.Parameter{
+				{Name: "max", In: "query", Type: "number", Example: float64(1000000)},
+				{Name: "ratio", In: "query", Type: "number", Example: 0.000001},
+				{Name: "ids", In: "query", Explode: boolPtr(false), Schema: &models.SchemaObj{Type: "array", Example: []interface{}{float64(2e6), 1.5}}},
+			},
+			wantQuery: "max={{max}}&ratio={{ratio}}&ids={{ids}}",
+			wantVars:  map[string]string{"max": "1000000", "ratio": "0.000001", "ids": "2000000,1.5"},
+		},
+		{
+			name:      "names that are not valid variables",
+			params:    []models.Parameter{{Name: "page[size]", In: "query", Type: "integer", Example: 20}},
+			wantQuery: "page[size]={{page_size_}}",
+			wantVars:  map[string]string{"page_size_": "20"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewWithOptions(swagger.New(), tt.options)
+			query, vars := generator.buildQuery(tt.params)
+			if query != tt.wantQuery {
+				t.Errorf("buildQuery() query = %q, want %q", query, tt.wantQuery)
+			}
+			if !reflect.DeepEqual(vars, tt.wantVars) {
+				t.Errorf("buildQuery() vars = %v, want %v", vars, tt.wantVars)
+			}
+		})
+	}
+}
+
+func TestGenerator_FormatPathWithQuery(t *testing.T) {
+	generator := New(swagger.New())
+
+	params := []models.Parameter{
+		{Name: "petId", In: "path", Required: true, Type: "string"},
+		{Name: "fields", In: "query", Type: "string"},
+	}
+
+	want := "/pets/{{petId}}?fields={{fields}}"
+	if got := generator.FormatPath("/pets/{petId}", params); got != want {
+		t.Errorf("FormatPath() = %q, want %q", got, want)
+	}
+}
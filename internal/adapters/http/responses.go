@@ -0,0 +1,147 @@
+package http
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// successResponse returns the documented success response of an operation and
+// its status code: 201, then 200, then the first other 2XX code. It returns
+// nil when the operation documents no success response.
+func (g *Generator) successResponse(op models.OperationInfo) (string, *models.Response) {
+	codes := make([]string, 0, len(op.Operation.Responses))
+	for code := range op.Operation.Responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		return "", nil
+	}
+
+	sort.Slice(codes, func(i, j int) bool {
+		return successRank(codes[i]) < successRank(codes[j]) ||
+			successRank(codes[i]) == successRank(codes[j]) && codes[i] < codes[j]
+	})
+
+	response := op.Operation.Responses[codes[0]]
+	if response.Ref != "" && g.resolver != nil {
+		resolved, err := g.resolver.ResolveResponse(&response)
+		if err != nil {
+			g.fail(err)
+			return "", nil
+		}
+		return codes[0], resolved
+	}
+	return codes[0], &response
+}
+
+// successRank orders success status codes by preference
+func successRank(code string) int {
+	switch code {
+	case "201":
+		return 0
+	case "200":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// responseSchema returns the JSON schema of a response, from the Swagger v2
+// schema or the first JSON media type of OpenAPI v3, resolving references
+// and flattening allOf compositions
+func (g *Generator) responseSchema(response *models.Response) *models.SchemaObj {
+	schema := response.Schema
+	if schema == nil {
+		contentTypes := make([]string, 0, len(response.Content))
+		for contentType := range response.Content {
+			contentTypes = append(contentTypes, contentType)
+		}
+		sort.Strings(contentTypes)
+
+		for _, contentType := range contentTypes {
+			if strings.Contains(contentType, "json") && response.Content[contentType].Schema != nil {
+				schema = response.Content[contentType].Schema
+				break
+			}
+		}
+	}
+	if schema == nil {
+		return nil
+	}
+
+	visiting := make(map[string]bool)
+	ref := schema.Ref
+	if ref != "" {
+		if g.resolver == nil {
+			return nil
+		}
+		resolved, err := g.resolver.ResolveSchema(schema)
+		if err != nil {
+			g.fail(err)
+			return nil
+		}
+		visiting[ref] = true
+		schema = resolved
+	}
+
+	if len(schema.AllOf) > 0 {
+		schema = g.mergeAllOf(schema, ref, visiting)
+	}
+	return schema
+}
+
+// responseExpectation describes the documented success response of an
+// operation: its status code, media type and required top-level fields
+func (g *Generator) responseExpectation(op models.OperationInfo) *models.ResponseExpectation {
+	code, response := g.successResponse(op)
+	if response == nil {
+		return nil
+	}
+
+	expect := &models.ResponseExpectation{
+		Status:      code,
+		ContentType: responseContentType(op, response),
+	}
+	if schema := g.responseSchema(response); schema != nil && expect.ContentType != "" {
+		seen := make(map[string]bool)
+		for _, name := range schema.Required {
+			if _, ok := schema.Properties[name]; ok && !seen[name] {
+				seen[name] = true
+				expect.Fields = append(expect.Fields, name)
+			}
+		}
+	}
+	return expect
+}
+
+// responseContentType returns the media type of a response body: the first
+// JSON media type of OpenAPI v3, else the first one, or the first type the
+// Swagger v2 operation produces. It is empty for responses without body.
+func responseContentType(op models.OperationInfo, response *models.Response) string {
+	if len(response.Content) > 0 {
+		contentTypes := make([]string, 0, len(response.Content))
+		for contentType := range response.Content {
+			contentTypes = append(contentTypes, contentType)
+		}
+		sort.Strings(contentTypes)
+
+		for _, contentType := range contentTypes {
+			if strings.Contains(contentType, "json") {
+				return contentType
+			}
+		}
+		return contentTypes[0]
+	}
+
+	if response.Schema == nil {
+		return ""
+	}
+	if produces := operationProduces(op); len(produces) > 0 {
+		return produces[0]
+	}
+	return "application/json"
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/adapters/swagger"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+func TestGenerator_ResponseExpectation(t *testing.T) {
+	pet := &models.SchemaObj{
+		Type:     "object",
+		Required: []string{"id", "name", "undeclared"},
+		Properties: map[string]models.SchemaObj{
+			"id":   {Type: "integer"},
+			"name": {Type: "string"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		op       models.OperationInfo
+		expected *models.ResponseExpectation
+	}{
+		{
+			name: "swagger v2 schema with produces",
+			op: models.OperationInfo{
+				Produces: []string{"application/xml", "application/json"},
+				Operation: &models.Operation{Responses: map[string]models.Response{
+					"200":     {Schema: pet},
+					"default": {Description: "Error"},
+				}},
+			},
+			expected: &models.ResponseExpectation{Status: "200", ContentType: "application/xml", Fields: []string{"id", "name"}},
+		},
+		{
+			name: "openapi v3 prefers created and json",
+			op: models.OperationInfo{
+				Operation: &models.Operation{Responses: map[string]models.Response{
+					"200": {Description: "Updated"},
+					"201": {Content: map[string]models.MediaTypeObj{
+						"text/plain":       {},
+						"application/json": {Schema: pet},
+					}},
+				}},
+			},
+			expected: &models.ResponseExpectation{Status: "201", ContentType: "application/json", Fields: []string{"id", "name"}},
+		},
+		{
+			name: "no body",
+			op: models.OperationInfo{
+				Operation: &models.Operation{Responses: map[string]models.Response{
+					"2XX": {Description: "Deleted"},
+				}},
+			},
+			expected: &models.ResponseExpectation{Status: "2XX"},
+		},
+		{
+			name: "no success response",
+			op: models.OperationInfo{
+				Operation: &models.Operation{Responses: map[string]models.Response{
+					"404": {Description: "Not found"},
+				}},
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := New(swagger.New())
+			if got := generator.responseExpectation(tt.op); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// securitySchemes collects the security schemes of the document, from the
+// OpenAPI v3 components and the Swagger v2 securityDefinitions
+func securitySchemes(doc *models.SwaggerDoc) map[string]models.SecurityScheme {
+	schemes := make(map[string]models.SecurityScheme)
+	for name, scheme := range doc.SecurityDefinitions {
+		schemes[name] = scheme
+	}
+	if doc.Components != nil {
+		for name, scheme := range doc.Components.SecuritySchemes {
+			schemes[name] = scheme
+		}
+	}
+	return schemes
+}
+
+// operationSecurity returns the effective security requirement of the operation
+func operationSecurity(op models.OperationInfo) []map[string][]string {
+	if op.Security != nil {
+		return op.Security
+	}
+	return op.Operation.Security
+}
+
+// applySecurity adds the credentials required by the operation to the request.
+// The first alternative of the security requirement is used; all of its
+// schemes are applied, each one referencing its own variable. The request's
+// Auth describes the credentials an API client can set up as authentication.
+func (g *Generator) applySecurity(op models.OperationInfo, request *models.HTTPRequest) {
+	security := operationSecurity(op)
+	if len(security) == 0 {
+		return
+	}
+
+	requirement := security[0]
+	names := make([]string, 0, len(requirement))
+	for name := range requirement {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// The authentication of the Authorization header, which the last scheme
+	// setting it wins, and of the first API key
+	var authorization, apiKey *models.HTTPAuth
+
+	var cookies, query []string
+	for _, name := range names {
+		scheme, ok := g.schemes[name]
+		if !ok {
+			// Unknown schemes fall back to a generic bearer token
+			request.Headers["Authorization"] = "Bearer {{authToken}}"
+			authorization = bearerAuth("authToken")
+			continue
+		}
+
+		varName := variableName(name)
+		switch strings.ToLower(scheme.Type) {
+		case "apikey":
+			value := fmt.Sprintf("{{%s}}", varName)
+			switch scheme.In {
+			case "query":
+				query = append(query, fmt.Sprintf("%s=%s", escapeQuery(scheme.Name, true), value))
+				if apiKey == nil {
+					apiKey = &models.HTTPAuth{Type: models.AuthAPIKey, Name: scheme.Name, Value: value, In: "query"}
+				}
+			case "cookie":
+				cookies = append(cookies, fmt.Sprintf("%s=%s", scheme.Name, value))
+			default:
+				request.Headers[scheme.Name] = value
+				if apiKey == nil {
+					apiKey = &models.HTTPAuth{Type: models.AuthAPIKey, Name: scheme.Name, Value: value, In: "header"}
+				}
+			}
+		case "basic":
+			request.Headers["Authorization"] = basicAuthorization(varName)
+			authorization = basicAuth(varName)
+		case "http":
+			switch strings.ToLower(scheme.Scheme) {
+			case "basic":
+				request.Headers["Authorization"] = basicAuthorization(varName)
+				authorization = basicAuth(varName)
+			case "bearer":
+				request.Headers["Authorization"] = fmt.Sprintf("Bearer {{%s}}", varName)
+				authorization = bearerAuth(varName)
+			default:
+				request.Headers["Authorization"] = fmt.Sprintf("%s {{%s}}", toTitleCase(scheme.Scheme), varName)
+				authorization = nil
+			}
+		default:
+			// OAuth2 and OpenID Connect tokens are shared by all requests
+			request.Headers["Authorization"] = "Bearer {{authToken}}"
+			authorization = bearerAuth("authToken")
+		}
+	}
+
+	// API clients set up one authentication per request, the credentials of
+	// the other schemes stay in the headers
+	if authorization != nil {
+		request.Auth = authorization
+	} else if apiKey != nil {
+		request.Auth = apiKey
+	}
+
+	if len(cookies) > 0 {
+		request.Headers["Cookie"] = strings.Join(cookies, "; ")
+	}
+
+	if len(query) > 0 {
+		separator := "?"
+		if strings.Contains(request.Path, "?") {
+			separator = "&"
+		}
+		request.Path += separator + strings.Join(query, "&")
+	}
+}
+
+// basicAuth returns the basic authentication of a scheme's variables
+func basicAuth(varName string) *models.HTTPAuth {
+	return &models.HTTPAuth{
+		Type:     models.AuthBasic,
+		Username: fmt.Sprintf("{{%sUsername}}", varName),
+		Password: fmt.Sprintf("{{%sPassword}}", varName),
+	}
+}
+
+// bearerAuth returns the bearer authentication of a token variable
+func bearerAuth(varName string) *models.HTTPAuth {
+	return &models.HTTPAuth{Type: models.AuthBearer, Token: fmt.Sprintf("{{%s}}", varName)}
+}
+
+// basicAuthorization returns an HTTP Basic Authorization header value. Both
+// the JetBrains HTTP Client and REST Client encode "Basic user password".
+func basicAuthorization(varName string) string {
+	return fmt.Sprintf("Basic {{%sUsername}} {{%sPassword}}", varName, varName)
+}
+
+// securityVars returns the variables referenced by the security schemes, with
+// placeholder values. OAuth2 and OpenID Connect schemes share {{authToken}},
+// which is left undefined so that the value captured by the auth file is used.
+func securityVars(schemes map[string]models.SecurityScheme) map[string]string {
+	vars := make(map[string]string)
+	for name, scheme := range schemes {
+		varName := variableName(name)
+		switch strings.ToLower(scheme.Type) {
+		case "apikey":
+			vars[varName] = "your_api_key"
+		case "basic":
+			vars[varName+"Username"] = "username"
+			vars[varName+"Password"] = "password"
+		case "http":
+			switch strings.ToLower(scheme.Scheme) {
+			case "basic":
+				vars[varName+"Username"] = "username"
+				vars[varName+"Password"] = "password"
+			case "bearer":
+				vars[varName] = "your_auth_token"
+			default:
+				vars[varName] = "your_credentials"
+			}
+		}
+	}
+	return vars
+}
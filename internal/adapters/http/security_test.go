@@ -0,0 +1,171 @@
+package http
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/adapters/swagger"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+func TestGenerator_SwaggerV2Security(t *testing.T) {
+	parser := swagger.New()
+	generator := New(parser)
+
+	doc := &models.SwaggerDoc{
+		Swagger: "2.0",
+		SecurityDefinitions: map[string]models.SecurityScheme{
+			"basic":   {Type: "basic"},
+			"api_key": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+			"token":   {Type: "apiKey", In: "query", Name: "access token"},
+			"oauth":   {Type: "oauth2"},
+		},
+		Security: []map[string][]string{{"api_key": {}}},
+		Paths: map[string]models.PathItem{
+			"/inherited": {
+				Get: &models.Operation{Tags: []string{"inherited"}},
+			},
+			"/basic": {
+				Get: &models.Operation{
+					Tags:     []string{"basic"},
+					Security: []map[string][]string{{"basic": {}}},
+				},
+			},
+			"/query": {
+				Get: &models.Operation{
+					Tags: []string{"query"},
+					Parameters: []models.Parameter{
+						{Name: "q", In: "query", Required: true, Type: "string"},
+					},
+					Security: []map[string][]string{{"token": {}}},
+				},
+			},
+			"/oauth": {
+				Get: &models.Operation{
+					Tags:     []string{"oauth"},
+					Security: []map[string][]string{{"oauth": {"read"}}},
+				},
+			},
+			"/public": {
+				Get: &models.Operation{
+					Tags:     []string{"public"},
+					Security: []map[string][]string{},
+				},
+			},
+			"/optional": {
+				Get: &models.Operation{
+					Tags:     []string{"optional"},
+					Security: []map[string][]string{{}, {"basic": {}}},
+				},
+			},
+			"/combined": {
+				Get: &models.Operation{
+					Tags:     []string{"combined"},
+					Security: []map[string][]string{{"api_key": {}, "oauth": {}}},
+				},
+			},
+		},
+	}
+
+	files, err := generator.Generate(doc, "")
+	if err != nil {
+		t.Fatalf("Failed to generate HTTP files: %v", err)
+	}
+
+	tests := []struct {
+		tag     string
+		path    string
+		headers map[string]string
+		auth    *models.HTTPAuth
+	}{
+		{
+			tag:     "inherited",
+			path:    "/inherited",
+			headers: map[string]string{"X-API-Key": "{{api_key}}"},
+			auth:    &models.HTTPAuth{Type: models.AuthAPIKey, Name: "X-API-Key", Value: "{{api_key}}", In: "header"},
+		},
+		{
+			tag:     "basic",
+			path:    "/basic",
+			headers: map[string]string{"Authorization": "Basic {{basicUsername}} {{basicPassword}}"},
+			auth:    &models.HTTPAuth{Type: models.AuthBasic, Username: "{{basicUsername}}", Password: "{{basicPassword}}"},
+		},
+		{
+			tag:     "query",
+			path:    "/query?q={{q}}&access%20token={{token}}",
+			headers: map[string]string{},
+			auth:    &models.HTTPAuth{Type: models.AuthAPIKey, Name: "access token", Value: "{{token}}", In: "query"},
+		},
+		{
+			tag:     "oauth",
+			path:    "/oauth",
+			headers: map[string]string{"Authorization": "Bearer {{authToken}}"},
+			auth:    &models.HTTPAuth{Type: models.AuthBearer, Token: "{{authToken}}"},
+		},
+		{
+			tag:     "combined",
+			path:    "/combined",
+			headers: map[string]string{"X-API-Key": "{{api_key}}", "Authorization": "Bearer {{authToken}}"},
+			auth:    &models.HTTPAuth{Type: models.AuthBearer, Token: "{{authToken}}"},
+		},
+		{
+			tag:     "public",
+			path:    "/public",
+			headers: map[string]string{},
+		},
+		{
+			tag:     "optional",
+			path:    "/optional",
+			headers: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			file, ok := files[tt.tag]
+			if !ok || len(file.Requests) != 1 {
+				t.Fatalf("Expected a single request for tag %s", tt.tag)
+			}
+
+			request := file.Requests[0]
+			if request.Path != tt.path {
+				t.Errorf("Expected path %q, got %q", tt.path, request.Path)
+			}
+			if !reflect.DeepEqual(request.Headers, tt.headers) {
+				t.Errorf("Expected headers %v, got %v", tt.headers, request.Headers)
+			}
+			if !reflect.DeepEqual(request.Auth, tt.auth) {
+				t.Errorf("Expected auth %+v, got %+v", tt.auth, request.Auth)
+			}
+		})
+	}
+
+	expectedVars := map[string]string{
+		"api_key":       "your_api_key",
+		"basicUsername": "username",
+		"basicPassword": "password",
+		"token":         "your_api_key",
+	}
+	for name, value := range expectedVars {
+		if got := files["basic"].GlobalVars[name]; got != value {
+			t.Errorf("Expected global var %s=%q, got %q", name, value, got)
+		}
+	}
+}
+
+func TestGenerator_UnknownSecurityScheme(t *testing.T) {
+	generator := New(swagger.New())
+
+	op := models.OperationInfo{
+		Path:   "/pets",
+		Method: "GET",
+		Operation: &models.Operation{
+			Security: []map[string][]string{{"undeclared": {}}},
+		},
+	}
+
+	request := generator.GenerateRequest(op, "")
+	if got := request.Headers["Authorization"]; got != "Bearer {{authToken}}" {
+		t.Errorf("Expected generic bearer token, got %q", got)
+	}
+}
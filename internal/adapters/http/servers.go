@@ -0,0 +1,85 @@
+package http
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// serverURL returns the URL of a server with its variables referenced as
+// {{variables}}, e.g. https://{{region}}.api.example.com
+func serverURL(server models.Server) string {
+	url := server.URL
+	for name := range server.Variables {
+		url = strings.ReplaceAll(url, "{"+name+"}", variable(variableName(name)))
+	}
+	return url
+}
+
+// addServerVars adds the variables of a server with their default values, and
+// a comment listing the allowed values. Variables already set are kept.
+func addServerVars(vars, comments map[string]string, server models.Server) {
+	names := make([]string, 0, len(server.Variables))
+	for name := range server.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		varName := variableName(name)
+		if _, exists := vars[varName]; exists {
+			continue
+		}
+
+		v := server.Variables[name]
+		vars[varName] = v.Default
+		if comment := serverVarComment(v); comment != "" && comments != nil {
+			comments[varName] = comment
+		}
+	}
+}
+
+// serverVarComment describes a server variable and its allowed values
+func serverVarComment(v models.ServerVariable) string {
+	var parts []string
+	if v.Description != "" {
+		parts = append(parts, strings.Join(strings.Fields(v.Description), " "))
+	}
+	if len(v.Enum) > 0 {
+		parts = append(parts, fmt.Sprintf("one of: %s", strings.Join(v.Enum, ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// sortedTags returns the tags of the operations in sorted order
+func sortedTags(operations map[string][]models.OperationInfo) []string {
+	tags := make([]string, 0, len(operations))
+	for tag := range operations {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// serverVariables collects the variables of the first document server and of
+// the servers that path items and operations use instead
+func serverVariables(doc *models.SwaggerDoc, operations map[string][]models.OperationInfo) (map[string]string, map[string]string) {
+	vars := make(map[string]string)
+	comments := make(map[string]string)
+
+	if len(doc.Servers) > 0 {
+		addServerVars(vars, comments, doc.Servers[0])
+	}
+
+	for _, tag := range sortedTags(operations) {
+		for _, op := range operations[tag] {
+			if len(op.Servers) > 0 {
+				addServerVars(vars, comments, op.Servers[0])
+			}
+		}
+	}
+
+	return vars, comments
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/adapters/swagger"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+func TestServerURL(t *testing.T) {
+	server := models.Server{
+		URL: "https://{region}.api.example.com/{base-path}/{unknown}",
+		Variables: map[string]models.ServerVariable{
+			"region":    {Default: "eu-west-1", Enum: []string{"eu-west-1", "us-east-1"}},
+			"base-path": {Default: "v2", Description: "API\n version"},
+		},
+	}
+
+	if got, want := serverURL(server), "https://{{region}}.api.example.com/{{base-path}}/{unknown}"; got != want {
+		t.Errorf("serverURL() = %q, want %q", got, want)
+	}
+	if got, want := server.ExpandURL(), "https://eu-west-1.api.example.com/v2/{unknown}"; got != want {
+		t.Errorf("ExpandURL() = %q, want %q", got, want)
+	}
+
+	vars := make(map[string]string)
+	comments := make(map[string]string)
+	addServerVars(vars, comments, server)
+
+	expectedVars := map[string]string{"region": "eu-west-1", "base-path": "v2"}
+	if !reflect.DeepEqual(vars, expectedVars) {
+		t.Errorf("Expected vars %v, got %v", expectedVars, vars)
+	}
+	expectedComments := map[string]string{
+		"region":    "one of: eu-west-1, us-east-1",
+		"base-path": "API version",
+	}
+	if !reflect.DeepEqual(comments, expectedComments) {
+		t.Errorf("Expected comments %v, got %v", expectedComments, comments)
+	}
+}
+
+func TestGenerator_ServerOverrides(t *testing.T) {
+	generator := New(swagger.New())
+
+	doc := &models.SwaggerDoc{
+		OpenAPI: "3.0.0",
+		Servers: []models.Server{{URL: "https://api.example.com"}},
+		Paths: map[string]models.PathItem{
+			"/files/{id}": {
+				Servers: []models.Server{{URL: "https://files.example.com/"}},
+				Get: &models.Operation{
+					Tags: []string{"files"},
+					Parameters: []models.Parameter{
+						{Name: "id", In: "path", Required: true, Type: "string"},
+					},
+				},
+				Put: &models.Operation{
+					Tags: []string{"files"},
+					Servers: []models.Server{{
+						URL:       "https://{zone}.upload.example.com",
+						Variables: map[string]models.ServerVariable{"zone": {Default: "eu"}},
+					}},
+				},
+			},
+			"/pets": {
+				Get: &models.Operation{Tags: []string{"pets"}},
+			},
+		},
+	}
+
+	files, err := generator.Generate(doc, "https://api.example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate HTTP files: %v", err)
+	}
+
+	paths := make(map[string]string)
+	for _, file := range files {
+		for _, request := range file.Requests {
+			paths[request.Method+" "+request.Tag] = request.Path
+		}
+	}
+
+	expected := map[string]string{
+		"GET files": "https://files.example.com/files/{{id}}",
+		"PUT files": "https://{{zone}}.upload.example.com/files/{{id}}",
+		"GET pets":  "/pets",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected paths %v, got %v", expected, paths)
+	}
+
+	if got := files["pets"].GlobalVars["zone"]; got != "eu" {
+		t.Errorf("Expected the zone variable of the operation server, got %q", got)
+	}
+}
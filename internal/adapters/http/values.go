@@ -0,0 +1,233 @@
+package http
+
+import (
+	"math"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// formatExamples holds a valid value for each well-known string format
+var formatExamples = map[string]string{
+	"uuid":          "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"date-time":     "2024-01-01T12:00:00Z",
+	"date":          "2024-01-01",
+	"time":          "12:00:00",
+	"duration":      "P1D",
+	"email":         "user@example.com",
+	"idn-email":     "user@example.com",
+	"uri":           "https://example.com",
+	"url":           "https://example.com",
+	"iri":           "https://example.com",
+	"uri-reference": "/example",
+	"hostname":      "example.com",
+	"ipv4":          "192.168.0.1",
+	"ipv6":          "2001:db8::1",
+	"byte":          "ZXhhbXBsZQ==",
+	"password":      "password",
+}
+
+// primitiveExample builds an example value for a string, number, integer or
+// boolean schema, or a schema with an enum and no type. The example of the
+// schema wins, then its default and its first enum value; otherwise the value
+// is generated to meet the format, bounds, length and pattern of the schema.
+func primitiveExample(schema *models.SchemaObj) interface{} {
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	}
+
+	switch schema.Type {
+	case "integer", "number":
+		return numberExample(schema)
+	case "boolean":
+		return false
+	default:
+		return stringExample(schema)
+	}
+}
+
+// stringExample generates a string matching the pattern of a schema, or the
+// value of its format, within its length limits. Values of a format are
+// kept whole, padding or cutting them would break the format.
+func stringExample(schema *models.SchemaObj) string {
+	if schema.Pattern != "" {
+		if value, ok := patternExample(schema.Pattern, schema.MinLength); ok {
+			return value
+		}
+	}
+
+	if value, ok := formatExamples[schema.Format]; ok {
+		return value
+	}
+
+	value := "string"
+	if len(value) < schema.MinLength {
+		value = strings.Repeat(value, schema.MinLength/len(value)+1)[:schema.MinLength]
+	}
+	if schema.MaxLength > 0 && len(value) > schema.MaxLength {
+		value = value[:schema.MaxLength]
+	}
+	return value
+}
+
+// numberExample generates a number within the bounds of a schema, a multiple
+// of its multipleOf, as close to zero as possible. Integral values are int64.
+func numberExample(schema *models.SchemaObj) interface{} {
+	integer := schema.Type == "integer"
+	min, minExclusive, hasMin := schemaBound(schema.Minimum, schema.ExclusiveMinimum)
+	max, maxExclusive, hasMax := schemaBound(schema.Maximum, schema.ExclusiveMaximum)
+	if integer {
+		// Rounding a fractional bound inward makes it inclusive
+		minExclusive = minExclusive && math.Ceil(min) == min
+		maxExclusive = maxExclusive && math.Floor(max) == max
+		min, max = math.Ceil(min), math.Floor(max)
+	}
+
+	value := 0.0
+	if hasMin && (value < min || (value == min && minExclusive)) {
+		value = min
+		if minExclusive {
+			value++
+		}
+	}
+	if hasMax && (value > max || (value == max && maxExclusive)) {
+		value = max
+		if maxExclusive {
+			value--
+		}
+	}
+	if hasMin && hasMax && (value < min || value > max) && !integer {
+		// Exclusive bounds closer than 1 to each other
+		value = (min + max) / 2
+	}
+
+	if m := schema.MultipleOf; m > 0 && math.Mod(value, m) != 0 {
+		value = math.Ceil(value/m) * m
+		if hasMax && value > max {
+			value = math.Floor(max/m) * m
+		}
+	}
+
+	if value == math.Trunc(value) && math.Abs(value) < 1e15 {
+		return int64(value)
+	}
+	return value
+}
+
+// schemaBound returns the minimum or maximum of a schema and whether it is
+// exclusive. exclusive is a flag up to OpenAPI 3.0 and the bound itself in 3.1.
+func schemaBound(bound *float64, exclusive interface{}) (float64, bool, bool) {
+	switch exclusive := exclusive.(type) {
+	case float64:
+		return exclusive, true, true
+	case int:
+		return float64(exclusive), true, true
+	case bool:
+		if bound != nil {
+			return *bound, exclusive, true
+		}
+	}
+	if bound != nil {
+		return *bound, false, true
+	}
+	return 0, false, false
+}
+
+// patternExample generates a string matching a regular expression, at least
+// minLength long when possible. Open-ended repetitions are repeated as few
+// times as needed; alternations take their first branch.
+func patternExample(pattern string, minLength int) (string, bool) {
+	matcher, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", false
+	}
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+
+	var fallback string
+	found := false
+	for repeat := 1; repeat <= minLength || repeat == 1; repeat++ {
+		var b strings.Builder
+		writePattern(&b, re, repeat)
+		value := b.String()
+		if !matcher.MatchString(value) {
+			continue
+		}
+		if len(value) >= minLength {
+			return value, true
+		}
+		if !found {
+			fallback, found = value, true
+		}
+	}
+	return fallback, found
+}
+
+// writePattern writes a string matching a parsed regular expression.
+// Open-ended repetitions are written repeat times.
+func writePattern(b *strings.Builder, re *syntax.Regexp, repeat int) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			b.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		b.WriteRune(classRune(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteRune('a')
+	case syntax.OpCapture:
+		writePattern(b, re.Sub[0], repeat)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writePattern(b, sub, repeat)
+		}
+	case syntax.OpAlternate:
+		writePattern(b, re.Sub[0], repeat)
+	case syntax.OpStar, syntax.OpPlus:
+		for i := 0; i < repeat; i++ {
+			writePattern(b, re.Sub[0], repeat)
+		}
+	case syntax.OpRepeat:
+		count := re.Min
+		if count < repeat {
+			count = repeat
+		}
+		if re.Max >= 0 && count > re.Max {
+			count = re.Max
+		}
+		for i := 0; i < count; i++ {
+			writePattern(b, re.Sub[0], repeat)
+		}
+	}
+	// Anchors, word boundaries, empty matches and optional parts write nothing
+}
+
+// classRune picks a character of a character class, given as lo-hi pairs,
+// preferring a letter or a digit
+func classRune(ranges []rune) rune {
+	for _, want := range "aA0" {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if ranges[i] <= want && want <= ranges[i+1] {
+				return want
+			}
+		}
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		if ranges[i] > ' ' {
+			return ranges[i]
+		}
+	}
+	if len(ranges) > 0 {
+		return ranges[0]
+	}
+	return 'a'
+}
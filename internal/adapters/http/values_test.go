@@ -0,0 +1,99 @@
+package http
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+func TestPrimitiveExample(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   models.SchemaObj
+		expected interface{}
+	}{
+		{"example wins", models.SchemaObj{Type: "string", Example: "Rex", Default: "Max", Enum: []interface{}{"Bella"}}, "Rex"},
+		{"default", models.SchemaObj{Type: "string", Default: "Max", Enum: []interface{}{"Bella"}}, "Max"},
+		{"first enum value", models.SchemaObj{Type: "string", Enum: []interface{}{"available", "sold"}}, "available"},
+		{"enum without type", models.SchemaObj{Enum: []interface{}{float64(3), float64(5)}}, float64(3)},
+		{"plain string", models.SchemaObj{Type: "string"}, "string"},
+		{"uuid", models.SchemaObj{Type: "string", Format: "uuid"}, "3fa85f64-5717-4562-b3fc-2c963f66afa6"},
+		{"date-time", models.SchemaObj{Type: "string", Format: "date-time"}, "2024-01-01T12:00:00Z"},
+		{"date", models.SchemaObj{Type: "string", Format: "date"}, "2024-01-01"},
+		{"email", models.SchemaObj{Type: "string", Format: "email"}, "user@example.com"},
+		{"uri", models.SchemaObj{Type: "string", Format: "uri"}, "https://example.com"},
+		{"ipv4", models.SchemaObj{Type: "string", Format: "ipv4"}, "192.168.0.1"},
+		{"byte", models.SchemaObj{Type: "string", Format: "byte"}, "ZXhhbXBsZQ=="},
+		{"min length", models.SchemaObj{Type: "string", MinLength: 10}, "stringstri"},
+		{"max length", models.SchemaObj{Type: "string", MaxLength: 3}, "str"},
+		{"uuid with min length", models.SchemaObj{Type: "string", Format: "uuid", MinLength: 40}, "3fa85f64-5717-4562-b3fc-2c963f66afa6"},
+		{"date with min length", models.SchemaObj{Type: "string", Format: "date", MinLength: 12}, "2024-01-01"},
+		{"date-time with max length", models.SchemaObj{Type: "string", Format: "date-time", MaxLength: 10}, "2024-01-01T12:00:00Z"},
+		{"email with min length", models.SchemaObj{Type: "string", Format: "email", MinLength: 20}, "user@example.com"},
+		{"unknown format with min length", models.SchemaObj{Type: "string", Format: "sku", MinLength: 8}, "stringst"},
+		{"pattern", models.SchemaObj{Type: "string", Pattern: `^[A-Z]{3}-\d{4}$`}, "AAA-0000"},
+		{"pattern with min length", models.SchemaObj{Type: "string", Pattern: `^[a-z]+$`, MinLength: 4}, "aaaa"},
+		{"pattern alternation", models.SchemaObj{Type: "string", Pattern: `^(cat|dog)s?$`}, "cat"},
+		{"invalid pattern", models.SchemaObj{Type: "string", Pattern: `^[a-z`, Format: "email"}, "user@example.com"},
+		{"integer", models.SchemaObj{Type: "integer"}, int64(0)},
+		{"minimum", models.SchemaObj{Type: "integer", Minimum: floatPtr(1)}, int64(1)},
+		{"exclusive minimum flag", models.SchemaObj{Type: "integer", Minimum: floatPtr(0), ExclusiveMinimum: true}, int64(1)},
+		{"exclusive minimum bound", models.SchemaObj{Type: "integer", ExclusiveMinimum: float64(10)}, int64(11)},
+		{"fractional exclusive minimum bound", models.SchemaObj{Type: "integer", ExclusiveMinimum: 0.5}, int64(1)},
+		{"fractional exclusive minimum flag", models.SchemaObj{Type: "integer", Minimum: floatPtr(1.5), ExclusiveMinimum: true}, int64(2)},
+		{"negative fractional exclusive minimum", models.SchemaObj{Type: "integer", ExclusiveMinimum: -0.5}, int64(0)},
+		{"fractional exclusive maximum bound", models.SchemaObj{Type: "integer", ExclusiveMaximum: -2.5}, int64(-3)},
+		{"negative maximum", models.SchemaObj{Type: "integer", Maximum: floatPtr(-5)}, int64(-5)},
+		{"fractional minimum", models.SchemaObj{Type: "number", Minimum: floatPtr(0.5)}, 0.5},
+		{"fractional integer minimum", models.SchemaObj{Type: "integer", Minimum: floatPtr(2.5)}, int64(3)},
+		{"narrow exclusive range", models.SchemaObj{Type: "number", Minimum: floatPtr(0), Maximum: floatPtr(0.5), ExclusiveMinimum: true, ExclusiveMaximum: true}, 0.25},
+		{"multiple of", models.SchemaObj{Type: "integer", Minimum: floatPtr(7), MultipleOf: 5}, int64(10)},
+		{"boolean", models.SchemaObj{Type: "boolean"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := primitiveExample(&tt.schema); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("primitiveExample() = %#v, want %#v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPatternExample(t *testing.T) {
+	patterns := []string{
+		`^\d{5}(-\d{4})?$`,
+		`^[A-Z][a-z]+ [A-Z][a-z]+$`,
+		`^\+?[0-9 ]{7,15}$`,
+		`^[^@\s]+@[^@\s]+\.[a-z]{2,}$`,
+		`^v\d+\.\d+\.\d+$`,
+		`[a-f0-9]{8}`,
+	}
+
+	for _, pattern := range patterns {
+		value, ok := patternExample(pattern, 0)
+		if !ok {
+			t.Errorf("patternExample(%q) found no value", pattern)
+			continue
+		}
+		if !regexp.MustCompile(pattern).MatchString(value) {
+			t.Errorf("patternExample(%q) = %q, which does not match", pattern, value)
+		}
+	}
+}
+
+func TestGenerator_ArrayMinItems(t *testing.T) {
+	g := New(nil)
+	schema := &models.SchemaObj{
+		Type:     "array",
+		MinItems: 3,
+		Items:    &models.SchemaObj{Type: "string", Enum: []interface{}{"a", "b"}},
+	}
+
+	expected := []interface{}{"a", "a", "a"}
+	if got := g.schemaExample(schema, make(map[string]bool)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,146 @@
+package http
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// xmlBody writes a value as an XML document. Element names, attributes,
+// namespaces and wrapped arrays follow the xml metadata of the schemas; the
+// root element is named after the referenced schema. A document example
+// already written as XML is kept as it is.
+func (g *Generator) xmlBody(schema *models.SchemaObj, value interface{}) string {
+	if text, ok := value.(string); ok && strings.HasPrefix(strings.TrimSpace(text), "<") {
+		return text
+	}
+
+	name := "root"
+	if schema != nil && schema.Ref != "" {
+		name = refName(schema.Ref)
+	}
+
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	g.writeXMLElement(&b, name, value, schema, 0)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// writeXMLElement writes a value as an element named after its property, or
+// after the xml name of its schema. Arrays write an element per item, inside
+// a wrapping element when the schema says so or at the root of the document.
+func (g *Generator) writeXMLElement(b *strings.Builder, name string, value interface{}, schema *models.SchemaObj, depth int) {
+	resolved := g.exampleSchema(schema)
+	meta := &models.XMLObj{}
+	if resolved != nil && resolved.XML != nil {
+		meta = resolved.XML
+	}
+	if meta.Name != "" {
+		name = meta.Name
+	}
+	indent := strings.Repeat("  ", depth)
+
+	if items, ok := value.([]interface{}); ok {
+		var itemSchema *models.SchemaObj
+		itemName := name
+		if resolved != nil && resolved.Items != nil {
+			itemSchema = resolved.Items
+			if itemSchema.Ref != "" && depth == 0 {
+				itemName = refName(itemSchema.Ref)
+			}
+		}
+
+		if !meta.Wrapped && depth > 0 {
+			for _, item := range items {
+				g.writeXMLElement(b, itemName, item, itemSchema, depth)
+			}
+			return
+		}
+
+		b.WriteString(indent + "<" + xmlName(name, meta) + xmlNamespace(meta) + ">\n")
+		for _, item := range items {
+			g.writeXMLElement(b, itemName, item, itemSchema, depth+1)
+		}
+		b.WriteString(indent + "</" + xmlName(name, meta) + ">\n")
+		return
+	}
+
+	open := xmlName(name, meta) + xmlNamespace(meta)
+	object, isObject := value.(jsonObject)
+	if !isObject {
+		if value == nil {
+			b.WriteString(indent + "<" + open + "/>\n")
+			return
+		}
+		b.WriteString(indent + "<" + open + ">" + escapeXML(queryString(value)) + "</" + xmlName(name, meta) + ">\n")
+		return
+	}
+
+	// Properties marked as attributes go into the opening tag
+	var children []jsonField
+	for _, field := range object {
+		var prop *models.SchemaObj
+		if resolved != nil {
+			if p, ok := resolved.Properties[field.Name]; ok {
+				prop = &p
+			}
+		}
+		if prop != nil && prop.XML != nil && prop.XML.Attribute {
+			attrName := field.Name
+			if prop.XML.Name != "" {
+				attrName = prop.XML.Name
+			}
+			open += " " + xmlName(attrName, prop.XML) + `="` + escapeXML(queryString(field.Value)) + `"`
+			continue
+		}
+		children = append(children, field)
+	}
+
+	if len(children) == 0 {
+		b.WriteString(indent + "<" + open + "/>\n")
+		return
+	}
+
+	b.WriteString(indent + "<" + open + ">\n")
+	for _, field := range children {
+		var prop *models.SchemaObj
+		if resolved != nil {
+			if p, ok := resolved.Properties[field.Name]; ok {
+				prop = &p
+			}
+		}
+		g.writeXMLElement(b, field.Name, field.Value, prop, depth+1)
+	}
+	b.WriteString(indent + "</" + xmlName(name, meta) + ">\n")
+}
+
+// xmlName qualifies an element or attribute name with the namespace prefix
+func xmlName(name string, meta *models.XMLObj) string {
+	if meta.Prefix != "" {
+		return meta.Prefix + ":" + name
+	}
+	return name
+}
+
+// xmlNamespace declares the namespace of an element, as an attribute
+func xmlNamespace(meta *models.XMLObj) string {
+	switch {
+	case meta.Namespace == "":
+		return ""
+	case meta.Prefix != "":
+		return ` xmlns:` + meta.Prefix + `="` + escapeXML(meta.Namespace) + `"`
+	default:
+		return ` xmlns="` + escapeXML(meta.Namespace) + `"`
+	}
+}
+
+// escapeXML escapes the text of an element or attribute value
+func escapeXML(s string) string {
+	var buf bytes.Buffer
+	if err := xml.EscapeText(&buf, []byte(s)); err != nil {
+		return s
+	}
+	return buf.String()
+}
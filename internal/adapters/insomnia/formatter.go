@@ -0,0 +1,379 @@
+package insomnia
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// exportSource identifies the tool in the __export_source of exports
+const exportSource = "swagger-to-http-file"
+
+// placeholderPattern matches the {{variable}} placeholders of HTTP requests
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// export is an Insomnia export in format 4
+type export struct {
+	Type      string     `json:"_type"`
+	Format    int        `json:"__export_format"`
+	Source    string     `json:"__export_source"`
+	Resources []resource `json:"resources"`
+}
+
+// resource is a workspace, environment, request group or request
+type resource struct {
+	ID             string            `json:"_id"`
+	Type           string            `json:"_type"`
+	ParentID       *string           `json:"parentId"`
+	Name           string            `json:"name"`
+	Description    string            `json:"description,omitempty"`
+	Scope          string            `json:"scope,omitempty"`
+	Data           map[string]string `json:"data,omitempty"`
+	MetaSortKey    int               `json:"metaSortKey,omitempty"`
+	Method         string            `json:"method,omitempty"`
+	URL            string            `json:"url,omitempty"`
+	Body           *body             `json:"body,omitempty"`
+	Parameters     []pair            `json:"parameters,omitempty"`
+	Headers        []pair            `json:"headers,omitempty"`
+	Authentication *authentication   `json:"authentication,omitempty"`
+}
+
+type body struct {
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Params   []pair `json:"params,omitempty"`
+	FileName string `json:"fileName,omitempty"`
+}
+
+type pair struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Type     string `json:"type,omitempty"`
+	FileName string `json:"fileName,omitempty"`
+}
+
+type authentication struct {
+	Type     string `json:"type"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+	Key      string `json:"key,omitempty"`
+	Value    string `json:"value,omitempty"`
+	AddTo    string `json:"addTo,omitempty"`
+}
+
+// Formatter exports HTTP files as an Insomnia v4 export
+type Formatter struct{}
+
+// FormatCollection formats HTTP files as an Insomnia export holding a
+// workspace named after the API. The global variables go to its base
+// environment, where the values read from responses reference the requests
+// returning them. Resource IDs derive from the names, so that importing a
+// regenerated export updates the previous one.
+func (f *Formatter) FormatCollection(name string, files []*models.HTTPFile) ([]byte, error) {
+	workspaceID := resourceID("wrk", name)
+	e := export{
+		Type:   "export",
+		Format: 4,
+		Source: exportSource,
+		Resources: []resource{
+			{ID: workspaceID, Type: "workspace", Name: name, Scope: "collection"},
+		},
+	}
+
+	// Resource IDs of the requests, by file and position
+	requestIDs := make([][]string, len(files))
+	for i, file := range files {
+		requestIDs[i] = make([]string, len(file.Requests))
+		for j, req := range file.Requests {
+			requestIDs[i][j] = requestResourceID(name, file.Tag, req, j)
+		}
+	}
+
+	vars := environmentVars(files, requestIDs)
+	e.Resources = append(e.Resources, resource{
+		ID:       resourceID("env", name, "base"),
+		Type:     "environment",
+		ParentID: &workspaceID,
+		Name:     "Base Environment",
+		Data:     vars,
+	})
+
+	for i, file := range files {
+		parentID := workspaceID
+		if file.Tag != "" {
+			parentID = resourceID("fld", name, file.Tag)
+			e.Resources = append(e.Resources, resource{
+				ID:          parentID,
+				Type:        "request_group",
+				ParentID:    &workspaceID,
+				Name:        file.Tag,
+				MetaSortKey: i + 1,
+			})
+		}
+		for j, req := range file.Requests {
+			r := requestResource(req, vars)
+			r.ID = requestIDs[i][j]
+			r.ParentID = &parentID
+			r.MetaSortKey = j + 1
+			e.Resources = append(e.Resources, r)
+		}
+	}
+
+	content, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Insomnia export: %v", err)
+	}
+	return append(content, '\n'), nil
+}
+
+// environmentVars returns the variables of the base environment: the global
+// variables, and references to the responses providing values to other
+// requests. The first file reading a value from a response provides it.
+func environmentVars(files []*models.HTTPFile, requestIDs [][]string) map[string]string {
+	vars := make(map[string]string)
+	for _, file := range files {
+		for name, value := range file.GlobalVars {
+			vars[name] = template(value, nil)
+		}
+	}
+
+	referenced := make(map[string]bool)
+	reference := func(name, id, path string) {
+		if referenced[name] || id == "" {
+			return
+		}
+		referenced[name] = true
+		vars[name] = responseReference(id, path)
+	}
+	for i, file := range files {
+		byID := make(map[string]string, len(file.Requests))
+		for j, req := range file.Requests {
+			if _, ok := byID[req.ID]; !ok && req.ID != "" {
+				byID[req.ID] = requestIDs[i][j]
+			}
+		}
+		for _, name := range sortedKeys(file.ResponseVars) {
+			ref := file.ResponseVars[name]
+			reference(name, byID[ref.Request], ref.Path)
+		}
+		for j, req := range file.Requests {
+			for _, capture := range req.Captures {
+				reference(capture.Variable, requestIDs[i][j], capture.Path)
+			}
+		}
+	}
+	return vars
+}
+
+// requestResource converts a request. Placeholders of environment variables
+// use the Insomnia template syntax; the others are replaced with the values
+// of the request variables.
+func requestResource(req models.HTTPRequest, vars map[string]string) resource {
+	expand := func(s string) string {
+		return template(s, func(name string) (string, bool) {
+			if _, ok := vars[name]; ok {
+				return "", false
+			}
+			value, ok := req.Vars[name]
+			return value, ok
+		})
+	}
+
+	path, query, _ := strings.Cut(req.Path, "?")
+	if strings.HasPrefix(path, "/") {
+		path = "{{baseUrl}}" + path
+	}
+	r := resource{
+		Type:        "request",
+		Name:        req.Name,
+		Description: req.Description,
+		Method:      strings.ToUpper(req.Method),
+		URL:         expand(path),
+	}
+
+	// Credentials set up as authentication are left out of the headers and query
+	if req.Auth != nil {
+		r.Authentication = requestAuthentication(req.Auth, expand)
+	}
+	for _, param := range splitPairs(query) {
+		if req.Auth != nil && req.Auth.Type == models.AuthAPIKey && req.Auth.In == "query" && param.Name == req.Auth.Name {
+			continue
+		}
+		r.Parameters = append(r.Parameters, pair{Name: expand(param.Name), Value: expand(param.Value)})
+	}
+
+	// Insomnia adds its own boundary to the Content-Type of multipart bodies
+	multipart := len(req.FormParts) > 0
+	var mimeType string
+	for _, name := range sortedKeys(req.Headers) {
+		value := req.Headers[name]
+		if strings.EqualFold(name, "Content-Type") {
+			if multipart {
+				value = "multipart/form-data"
+			}
+			mimeType = value
+		}
+		if req.Auth != nil && authHeader(req.Auth, name) {
+			continue
+		}
+		r.Headers = append(r.Headers, pair{Name: name, Value: expand(value)})
+	}
+
+	if multipart {
+		r.Body = &body{MimeType: mimeType}
+		for _, part := range req.FormParts {
+			if part.File != "" {
+				r.Body.Params = append(r.Body.Params, pair{Name: part.Name, Type: "file", FileName: part.File})
+				continue
+			}
+			r.Body.Params = append(r.Body.Params, pair{Name: part.Name, Value: expand(part.Value)})
+		}
+	} else if req.Body != "" {
+		r.Body = &body{MimeType: mimeType}
+		if strings.HasPrefix(mimeType, "application/x-www-form-urlencoded") {
+			for _, param := range splitPairs(req.Body) {
+				r.Body.Params = append(r.Body.Params, pair{Name: expand(param.Name), Value: expand(param.Value)})
+			}
+		} else {
+			r.Body.Text = expand(req.Body)
+		}
+	} else if req.BodyFile != "" {
+		r.Body = &body{MimeType: mimeType, FileName: req.BodyFile}
+	}
+	return r
+}
+
+// requestAuthentication converts the authentication of a request
+func requestAuthentication(a *models.HTTPAuth, expand func(string) string) *authentication {
+	switch a.Type {
+	case models.AuthBasic:
+		return &authentication{Type: "basic", Username: expand(a.Username), Password: expand(a.Password)}
+	case models.AuthBearer:
+		return &authentication{Type: "bearer", Token: expand(a.Token)}
+	default:
+		addTo := "header"
+		if a.In == "query" {
+			addTo = "queryParams"
+		}
+		return &authentication{Type: "apikey", Key: a.Name, Value: expand(a.Value), AddTo: addTo}
+	}
+}
+
+// authHeader reports whether a header carries the credentials of an authentication
+func authHeader(a *models.HTTPAuth, name string) bool {
+	if a.Type == models.AuthAPIKey {
+		return a.In == "header" && strings.EqualFold(name, a.Name)
+	}
+	return strings.EqualFold(name, "Authorization")
+}
+
+// splitPairs splits URL-encoded name=value pairs, decoding them since
+// Insomnia encodes parameters when sending them
+func splitPairs(s string) []pair {
+	if s == "" {
+		return nil
+	}
+	var pairs []pair
+	for _, part := range strings.Split(s, "&") {
+		name, value, _ := strings.Cut(part, "=")
+		pairs = append(pairs, pair{Name: unescape(name), Value: unescape(value)})
+	}
+	return pairs
+}
+
+// unescape decodes a URL-encoded value, leaving invalid encodings as they are
+func unescape(s string) string {
+	if decoded, err := url.QueryUnescape(s); err == nil {
+		return decoded
+	}
+	return s
+}
+
+// template turns {{name}} placeholders into Insomnia environment variables,
+// {{ _.name }}. local returns the value replacing a placeholder that is not
+// an environment variable; with nil local all variables are.
+func template(s string, local func(string) (string, bool)) string {
+	return placeholderPattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		if local != nil {
+			if value, ok := local(name); ok {
+				return value
+			}
+		}
+		return fmt.Sprintf("{{ _.%s }}", name)
+	})
+}
+
+// responseReference returns the template tag reading a value of the JSON
+// body of the last response of a request
+func responseReference(id, path string) string {
+	jsonPath := base64.StdEncoding.EncodeToString([]byte(jsonPathOf(path)))
+	return fmt.Sprintf("{%% response 'body', '%s', 'b64::%s::46b', 'never', 60 %%}", id, jsonPath)
+}
+
+// jsonPathOf returns the JSONPath of a dotted path, e.g. $.data.token or $["first-name"]
+func jsonPathOf(path string) string {
+	var builder strings.Builder
+	builder.WriteString("$")
+	for _, part := range strings.Split(path, ".") {
+		if identifier(part) {
+			builder.WriteString("." + part)
+		} else {
+			builder.WriteString(fmt.Sprintf("[%q]", part))
+		}
+	}
+	return builder.String()
+}
+
+// identifier reports whether a property name can follow a dot
+func identifier(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, r := range name {
+		if r != '_' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// requestResourceID returns the ID of a request, by its ID in the file or
+// its position when it has none
+func requestResourceID(name, tag string, req models.HTTPRequest, index int) string {
+	if req.ID != "" {
+		return resourceID("req", name, tag, req.ID)
+	}
+	return resourceID("req", name, tag, "#"+strconv.Itoa(index))
+}
+
+// resourceID derives a resource ID from names, e.g. fld_1f0e...
+func resourceID(prefix string, names ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(names, "\x00")))
+	return prefix + "_" + hex.EncodeToString(sum[:16])
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// NewFormatter creates a new Formatter instance
+func NewFormatter() *Formatter {
+	return &Formatter{}
+}
@@ -0,0 +1,157 @@
+package insomnia
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/application/formatter"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+var _ formatter.CollectionFormatter = (*Formatter)(nil)
+
+func TestFormatter_FormatCollection(t *testing.T) {
+	files := []*models.HTTPFile{
+		{
+			Tag:        "pets",
+			GlobalVars: map[string]string{"baseUrl": "https://{{region}}.example.com", "region": "eu"},
+			ResponseVars: map[string]models.ResponseRef{
+				"petId": {Request: "createPet", Path: "id"},
+			},
+			Requests: []models.HTTPRequest{
+				{
+					Name:    "Create pet",
+					ID:      "createPet",
+					Method:  "POST",
+					Path:    "/pets?dryRun={{dryRun}}&tag=a%20b",
+					Headers: map[string]string{"Content-Type": "application/json", "X-API-Key": "{{apiKey}}"},
+					Body:    `{"name": "{{name}}"}`,
+					Vars:    map[string]string{"dryRun": "false", "name": "Rex"},
+					Auth:    &models.HTTPAuth{Type: models.AuthAPIKey, Name: "X-API-Key", Value: "{{apiKey}}", In: "header"},
+				},
+				{
+					Name:   "Get pet",
+					ID:     "getPet",
+					Method: "GET",
+					Path:   "/pets/{{petId}}",
+				},
+			},
+		},
+	}
+
+	content, err := NewFormatter().FormatCollection("Pet Store", files)
+	if err != nil {
+		t.Fatalf("FormatCollection failed: %v", err)
+	}
+
+	var e export
+	if err := json.Unmarshal(content, &e); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if e.Type != "export" || e.Format != 4 || len(e.Resources) != 5 {
+		t.Fatalf("Expected an export with 5 resources, got %+v", e)
+	}
+
+	workspace, environment, folder, create, get := e.Resources[0], e.Resources[1], e.Resources[2], e.Resources[3], e.Resources[4]
+	if workspace.Type != "workspace" || workspace.ParentID != nil || workspace.Name != "Pet Store" {
+		t.Errorf("Unexpected workspace %+v", workspace)
+	}
+	if folder.Type != "request_group" || *folder.ParentID != workspace.ID || folder.Name != "pets" {
+		t.Errorf("Unexpected folder %+v", folder)
+	}
+	if *create.ParentID != folder.ID || *get.ParentID != folder.ID {
+		t.Errorf("Expected the requests in the folder")
+	}
+
+	expectedData := map[string]string{
+		"baseUrl": "https://{{ _.region }}.example.com",
+		"region":  "eu",
+		"petId":   "{% response 'body', '" + create.ID + "', 'b64::JC5pZA==::46b', 'never', 60 %}",
+	}
+	if environment.Type != "environment" || !reflect.DeepEqual(environment.Data, expectedData) {
+		t.Errorf("Expected environment data %v, got %v", expectedData, environment.Data)
+	}
+
+	if create.URL != "{{ _.baseUrl }}/pets" {
+		t.Errorf("Unexpected URL %s", create.URL)
+	}
+	expectedParams := []pair{{Name: "dryRun", Value: "false"}, {Name: "tag", Value: "a b"}}
+	if !reflect.DeepEqual(create.Parameters, expectedParams) {
+		t.Errorf("Expected parameters %+v, got %+v", expectedParams, create.Parameters)
+	}
+	if !reflect.DeepEqual(create.Headers, []pair{{Name: "Content-Type", Value: "application/json"}}) {
+		t.Errorf("Expected the API key header to be left out, got %+v", create.Headers)
+	}
+	expectedAuth := &authentication{Type: "apikey", Key: "X-API-Key", Value: "{{ _.apiKey }}", AddTo: "header"}
+	if !reflect.DeepEqual(create.Authentication, expectedAuth) {
+		t.Errorf("Expected authentication %+v, got %+v", expectedAuth, create.Authentication)
+	}
+	if create.Body == nil || create.Body.MimeType != "application/json" || create.Body.Text != `{"name": "Rex"}` {
+		t.Errorf("Unexpected body %+v", create.Body)
+	}
+	if get.URL != "{{ _.baseUrl }}/pets/{{ _.petId }}" {
+		t.Errorf("Unexpected URL %s", get.URL)
+	}
+
+	// IDs are stable between exports
+	again, err := NewFormatter().FormatCollection("Pet Store", files)
+	if err != nil || string(again) != string(content) {
+		t.Errorf("Expected identical exports")
+	}
+}
+
+func TestFormatter_FormatCollectionMultipart(t *testing.T) {
+	file := &models.HTTPFile{
+		GlobalVars: map[string]string{"baseUrl": "http://localhost:8080"},
+		Requests: []models.HTTPRequest{{
+			Name:    "Upload photo",
+			ID:      "uploadPhoto",
+			Method:  "POST",
+			Path:    "/photos",
+			Headers: map[string]string{"Content-Type": "multipart/form-data; boundary=WebAppBoundary"},
+			FormParts: []models.FormPart{
+				{Name: "caption", Value: "{{caption}}"},
+				{Name: "photo", File: "./photo.png", FileName: "photo.png", ContentType: "image/png"},
+			},
+			Vars: map[string]string{"caption": "Rex"},
+		}},
+	}
+
+	content, err := NewFormatter().FormatCollection("API", []*models.HTTPFile{file})
+	if err != nil {
+		t.Fatalf("FormatCollection failed: %v", err)
+	}
+
+	var e export
+	if err := json.Unmarshal(content, &e); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+
+	// Insomnia adds its own boundary
+	upload := e.Resources[len(e.Resources)-1]
+	if !reflect.DeepEqual(upload.Headers, []pair{{Name: "Content-Type", Value: "multipart/form-data"}}) {
+		t.Errorf("Expected a Content-Type without boundary, got %+v", upload.Headers)
+	}
+	expected := []pair{
+		{Name: "caption", Value: "Rex"},
+		{Name: "photo", Type: "file", FileName: "./photo.png"},
+	}
+	if upload.Body == nil || upload.Body.MimeType != "multipart/form-data" || !reflect.DeepEqual(upload.Body.Params, expected) {
+		t.Errorf("Expected form params %+v, got %+v", expected, upload.Body)
+	}
+}
+
+func TestJSONPathOf(t *testing.T) {
+	tests := map[string]string{
+		"id":             "$.id",
+		"data.token":     "$.data.token",
+		"first-name":     `$["first-name"]`,
+		"token_endpoint": "$.token_endpoint",
+	}
+	for path, want := range tests {
+		if got := jsonPathOf(path); got != want {
+			t.Errorf("jsonPathOf(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,432 @@
+package postman
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// SchemaURL identifies the Postman Collection Format v2.1.0
+const SchemaURL = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// placeholderPattern matches the {{variable}} placeholders of HTTP requests
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// collection is a Postman collection
+type collection struct {
+	Info     info       `json:"info"`
+	Item     []item     `json:"item"`
+	Variable []variable `json:"variable,omitempty"`
+}
+
+type info struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// item is a request, or a folder of requests when Item is set
+type item struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Item        []item   `json:"item,omitempty"`
+	Event       []event  `json:"event,omitempty"`
+	Request     *request `json:"request,omitempty"`
+}
+
+type request struct {
+	Method      string     `json:"method"`
+	Header      []keyValue `json:"header"`
+	Body        *body      `json:"body,omitempty"`
+	URL         requestURL `json:"url"`
+	Auth        *auth      `json:"auth,omitempty"`
+	Description string     `json:"description,omitempty"`
+}
+
+type requestURL struct {
+	Raw      string     `json:"raw"`
+	Protocol string     `json:"protocol,omitempty"`
+	Host     []string   `json:"host,omitempty"`
+	Port     string     `json:"port,omitempty"`
+	Path     []string   `json:"path,omitempty"`
+	Query    []keyValue `json:"query,omitempty"`
+}
+
+type keyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type,omitempty"`
+}
+
+type body struct {
+	Mode     string       `json:"mode"`
+	Raw      string       `json:"raw,omitempty"`
+	File     *bodyFile    `json:"file,omitempty"`
+	FormData []formParam  `json:"formdata,omitempty"`
+	Options  *bodyOptions `json:"options,omitempty"`
+}
+
+type formParam struct {
+	Key         string `json:"key"`
+	Value       string `json:"value,omitempty"`
+	Src         string `json:"src,omitempty"`
+	Type        string `json:"type"`
+	ContentType string `json:"contentType,omitempty"`
+}
+
+type bodyFile struct {
+	Src string `json:"src"`
+}
+
+type bodyOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+type auth struct {
+	Type   string     `json:"type"`
+	Basic  []keyValue `json:"basic,omitempty"`
+	Bearer []keyValue `json:"bearer,omitempty"`
+	APIKey []keyValue `json:"apikey,omitempty"`
+}
+
+type event struct {
+	Listen string `json:"listen"`
+	Script script `json:"script"`
+}
+
+type script struct {
+	Type string   `json:"type"`
+	Exec []string `json:"exec"`
+}
+
+type variable struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+}
+
+// Formatter exports HTTP files as a Postman Collection v2.1
+type Formatter struct{}
+
+// FormatCollection formats HTTP files as a Postman collection. The global
+// variables become collection variables, and the values requests read from
+// the responses of other requests are stored in them by test scripts.
+func (f *Formatter) FormatCollection(name string, files []*models.HTTPFile) ([]byte, error) {
+	c := collection{
+		Info: info{Name: name, Schema: SchemaURL},
+		Item: []item{},
+	}
+
+	vars := collectionVars(files)
+	for _, name := range sortedKeys(vars) {
+		c.Variable = append(c.Variable, vars[name])
+	}
+
+	for _, file := range files {
+		items := make([]item, 0, len(file.Requests))
+		for _, req := range file.Requests {
+			items = append(items, requestItem(req, file, vars))
+		}
+		if file.Tag == "" {
+			c.Item = append(c.Item, items...)
+			continue
+		}
+		c.Item = append(c.Item, item{Name: file.Tag, Item: items})
+	}
+
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Postman collection: %v", err)
+	}
+	return append(content, '\n'), nil
+}
+
+// collectionVars returns the collection variables: the global variables and
+// those set from responses, which start empty
+func collectionVars(files []*models.HTTPFile) map[string]variable {
+	vars := make(map[string]variable)
+	for _, file := range files {
+		for name, value := range file.GlobalVars {
+			vars[name] = variable{Key: name, Value: value, Type: "string", Description: file.VarComments[name]}
+		}
+	}
+	for _, file := range files {
+		for name := range file.ResponseVars {
+			if _, ok := vars[name]; !ok {
+				vars[name] = variable{Key: name, Value: "", Type: "string", Description: file.VarComments[name]}
+			}
+		}
+		for _, req := range file.Requests {
+			for _, capture := range req.Captures {
+				if _, ok := vars[capture.Variable]; !ok {
+					vars[capture.Variable] = variable{Key: capture.Variable, Value: "", Type: "string"}
+				}
+			}
+		}
+	}
+	return vars
+}
+
+// requestItem converts a request. Placeholders of variables that are not
+// collection variables are replaced with the values of the request variables.
+func requestItem(req models.HTTPRequest, file *models.HTTPFile, vars map[string]variable) item {
+	expand := func(s string) string {
+		return placeholderPattern.ReplaceAllStringFunc(s, func(match string) string {
+			name := placeholderPattern.FindStringSubmatch(match)[1]
+			if _, ok := vars[name]; ok {
+				return match
+			}
+			if value, ok := req.Vars[name]; ok {
+				return value
+			}
+			return match
+		})
+	}
+
+	r := &request{
+		Method:      strings.ToUpper(req.Method),
+		Header:      []keyValue{},
+		URL:         parseURL(expand(rawURL(req.Path))),
+		Description: req.Description,
+	}
+
+	// Credentials set up as authentication are left out of the headers and query
+	if req.Auth != nil {
+		r.Auth = requestAuth(req.Auth)
+		r.URL = withoutAuthQuery(r.URL, req.Auth)
+	}
+	// Postman sets the Content-Type of multipart bodies, with its own boundary
+	multipart := len(req.FormParts) > 0
+	for _, name := range sortedKeys(req.Headers) {
+		if req.Auth != nil && authHeader(req.Auth, name) {
+			continue
+		}
+		if multipart && strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+		r.Header = append(r.Header, keyValue{Key: name, Value: expand(req.Headers[name])})
+	}
+
+	if multipart {
+		r.Body = &body{Mode: "formdata", FormData: []formParam{}}
+		for _, part := range req.FormParts {
+			param := formParam{Key: part.Name, Type: "text", ContentType: part.ContentType}
+			if part.File != "" {
+				param.Type, param.Src = "file", part.File
+			} else {
+				param.Value = expand(part.Value)
+			}
+			r.Body.FormData = append(r.Body.FormData, param)
+		}
+	} else if req.Body != "" {
+		r.Body = &body{Mode: "raw", Raw: expand(req.Body)}
+		if language := bodyLanguage(req.Headers); language != "" {
+			r.Body.Options = &bodyOptions{}
+			r.Body.Options.Raw.Language = language
+		}
+	} else if req.BodyFile != "" {
+		r.Body = &body{Mode: "file", File: &bodyFile{Src: req.BodyFile}}
+	}
+
+	it := item{Name: req.Name, Request: r}
+	if exec := captureScript(req, file); len(exec) > 0 {
+		it.Event = []event{{Listen: "test", Script: script{Type: "text/javascript", Exec: exec}}}
+	}
+	return it
+}
+
+// captureScript returns the test script storing the values read from the
+// response in collection variables
+func captureScript(req models.HTTPRequest, file *models.HTTPFile) []string {
+	captures := append([]models.ResponseCapture{}, req.Captures...)
+	if req.ID != "" {
+		for _, name := range sortedKeys(file.ResponseVars) {
+			if ref := file.ResponseVars[name]; ref.Request == req.ID && !capturesVariable(req.Captures, name) {
+				captures = append(captures, models.ResponseCapture{Variable: name, Path: ref.Path})
+			}
+		}
+	}
+	if len(captures) == 0 {
+		return nil
+	}
+
+	exec := []string{"const body = pm.response.json();"}
+	for _, capture := range captures {
+		exec = append(exec, fmt.Sprintf("pm.collectionVariables.set(%q, body%s);", capture.Variable, jsPath(capture.Path)))
+	}
+	return exec
+}
+
+// capturesVariable reports whether captures store a variable
+func capturesVariable(captures []models.ResponseCapture, name string) bool {
+	for _, capture := range captures {
+		if capture.Variable == name {
+			return true
+		}
+	}
+	return false
+}
+
+// requestAuth converts the authentication of a request
+func requestAuth(a *models.HTTPAuth) *auth {
+	switch a.Type {
+	case models.AuthBasic:
+		return &auth{Type: "basic", Basic: []keyValue{
+			{Key: "username", Value: a.Username, Type: "string"},
+			{Key: "password", Value: a.Password, Type: "string"},
+		}}
+	case models.AuthBearer:
+		return &auth{Type: "bearer", Bearer: []keyValue{
+			{Key: "token", Value: a.Token, Type: "string"},
+		}}
+	default:
+		return &auth{Type: "apikey", APIKey: []keyValue{
+			{Key: "key", Value: a.Name, Type: "string"},
+			{Key: "value", Value: a.Value, Type: "string"},
+			{Key: "in", Value: a.In, Type: "string"},
+		}}
+	}
+}
+
+// authHeader reports whether a header carries the credentials of an authentication
+func authHeader(a *models.HTTPAuth, name string) bool {
+	if a.Type == models.AuthAPIKey {
+		return a.In == "header" && strings.EqualFold(name, a.Name)
+	}
+	return strings.EqualFold(name, "Authorization")
+}
+
+// withoutAuthQuery removes the API key sent as a query parameter from a URL
+func withoutAuthQuery(u requestURL, a *models.HTTPAuth) requestURL {
+	if a.Type != models.AuthAPIKey || a.In != "query" {
+		return u
+	}
+	query := make([]keyValue, 0, len(u.Query))
+	for _, param := range u.Query {
+		if key, err := url.QueryUnescape(param.Key); err == nil && key == a.Name {
+			continue
+		}
+		query = append(query, param)
+	}
+	u.Query = query
+
+	parts := make([]string, 0, len(query))
+	for _, param := range query {
+		parts = append(parts, param.Key+"="+param.Value)
+	}
+	u.Raw = strings.SplitN(u.Raw, "?", 2)[0]
+	if len(parts) > 0 {
+		u.Raw += "?" + strings.Join(parts, "&")
+	}
+	return u
+}
+
+// rawURL prefixes paths with the base URL variable
+func rawURL(path string) string {
+	if strings.HasPrefix(path, "/") {
+		return "{{baseUrl}}" + path
+	}
+	return path
+}
+
+// parseURL splits a URL into the parts of a Postman URL. Variables are kept
+// as they are, so the host may be a single {{variable}}.
+func parseURL(raw string) requestURL {
+	u := requestURL{Raw: raw}
+
+	rest, query, _ := strings.Cut(raw, "?")
+	if protocol, after, ok := strings.Cut(rest, "://"); ok {
+		u.Protocol = protocol
+		rest = after
+	}
+
+	host, path, _ := strings.Cut(rest, "/")
+	if name, port, ok := strings.Cut(host, ":"); ok && !strings.Contains(host, "{{") {
+		host, u.Port = name, port
+	}
+	if strings.Contains(host, "{{") {
+		u.Host = []string{host}
+	} else {
+		u.Host = strings.Split(host, ".")
+	}
+	if path != "" {
+		u.Path = strings.Split(path, "/")
+	}
+
+	if query != "" {
+		for _, param := range strings.Split(query, "&") {
+			key, value, _ := strings.Cut(param, "=")
+			u.Query = append(u.Query, keyValue{Key: key, Value: value})
+		}
+	}
+	return u
+}
+
+// bodyLanguage returns the language Postman highlights a raw body in
+func bodyLanguage(headers map[string]string) string {
+	for name, value := range headers {
+		if !strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+		switch value = strings.ToLower(value); {
+		case strings.Contains(value, "json"):
+			return "json"
+		case strings.Contains(value, "xml"):
+			return "xml"
+		case strings.HasPrefix(value, "text/html"):
+			return "html"
+		default:
+			return "text"
+		}
+	}
+	return ""
+}
+
+// jsPath returns the JavaScript property accessors of a dotted path, e.g.
+// .data.token or ["first-name"]
+func jsPath(path string) string {
+	var builder strings.Builder
+	for _, part := range strings.Split(path, ".") {
+		if jsIdentifier(part) {
+			builder.WriteString("." + part)
+		} else {
+			builder.WriteString(fmt.Sprintf("[%q]", part))
+		}
+	}
+	return builder.String()
+}
+
+// jsIdentifier reports whether a property name can follow a dot
+func jsIdentifier(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, r := range name {
+		if r != '_' && r != '$' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// NewFormatter creates a new Formatter instance
+func NewFormatter() *Formatter {
+	return &Formatter{}
+}
@@ -0,0 +1,227 @@
+package postman
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/application/formatter"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+var _ formatter.CollectionFormatter = (*Formatter)(nil)
+
+// testFiles returns a pets file chaining the id of created pets and an auth
+// file capturing a token
+func testFiles() []*models.HTTPFile {
+	globals := map[string]string{"baseUrl": "https://api.example.com", "apiKey": "your_api_key"}
+	return []*models.HTTPFile{
+		{
+			Tag:          "auth",
+			GlobalVars:   globals,
+			ResponseVars: map[string]models.ResponseRef{},
+			Requests: []models.HTTPRequest{
+				{
+					Name:     "Get token",
+					ID:       "getToken",
+					Method:   "POST",
+					Path:     "https://auth.example.com/token",
+					Headers:  map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+					Body:     "grant_type=client_credentials",
+					Captures: []models.ResponseCapture{{Variable: "authToken", Path: "access_token"}},
+				},
+			},
+		},
+		{
+			Tag:          "pets",
+			GlobalVars:   globals,
+			VarComments:  map[string]string{"petId": "id returned by createPet"},
+			ResponseVars: map[string]models.ResponseRef{"petId": {Request: "createPet", Path: "id"}},
+			Requests: []models.HTTPRequest{
+				{
+					Name:    "Create pet",
+					ID:      "createPet",
+					Method:  "post",
+					Path:    "/pets?api_key={{apiKey}}&dryRun={{dryRun}}",
+					Headers: map[string]string{"Content-Type": "application/json", "Authorization": "Bearer {{authToken}}"},
+					Body:    "{\n  \"name\": \"{{name}}\"\n}",
+					Vars:    map[string]string{"dryRun": "false", "name": "Rex"},
+					Auth:    &models.HTTPAuth{Type: models.AuthBearer, Token: "{{authToken}}"},
+				},
+				{
+					Name:    "Get pet",
+					ID:      "getPet",
+					Method:  "GET",
+					Path:    "/pets/{{petId}}?api_key={{apiKey}}",
+					Headers: map[string]string{"Accept": "application/json"},
+					Auth:    &models.HTTPAuth{Type: models.AuthAPIKey, Name: "api_key", Value: "{{apiKey}}", In: "query"},
+				},
+			},
+		},
+	}
+}
+
+func TestFormatter_FormatCollection(t *testing.T) {
+	content, err := NewFormatter().FormatCollection("Pet Store", testFiles())
+	if err != nil {
+		t.Fatalf("FormatCollection failed: %v", err)
+	}
+
+	var c collection
+	if err := json.Unmarshal(content, &c); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+
+	if c.Info.Name != "Pet Store" || c.Info.Schema != SchemaURL {
+		t.Errorf("Unexpected info %+v", c.Info)
+	}
+
+	expectedVars := []variable{
+		{Key: "apiKey", Value: "your_api_key", Type: "string"},
+		{Key: "authToken", Value: "", Type: "string"},
+		{Key: "baseUrl", Value: "https://api.example.com", Type: "string"},
+		{Key: "petId", Value: "", Type: "string", Description: "id returned by createPet"},
+	}
+	if !reflect.DeepEqual(c.Variable, expectedVars) {
+		t.Errorf("Expected variables %+v, got %+v", expectedVars, c.Variable)
+	}
+
+	if len(c.Item) != 2 || c.Item[0].Name != "auth" || c.Item[1].Name != "pets" || len(c.Item[1].Item) != 2 {
+		t.Fatalf("Expected an auth and a pets folder, got %+v", c.Item)
+	}
+
+	token := c.Item[0].Item[0]
+	if token.Request.URL.Protocol != "https" || !reflect.DeepEqual(token.Request.URL.Host, []string{"auth", "example", "com"}) {
+		t.Errorf("Unexpected token URL %+v", token.Request.URL)
+	}
+	if len(token.Event) != 1 || !reflect.DeepEqual(token.Event[0].Script.Exec, []string{
+		"const body = pm.response.json();",
+		`pm.collectionVariables.set("authToken", body.access_token);`,
+	}) {
+		t.Errorf("Unexpected token script %+v", token.Event)
+	}
+
+	create := c.Item[1].Item[0].Request
+	if create.Method != "POST" {
+		t.Errorf("Expected method POST, got %s", create.Method)
+	}
+	if create.URL.Raw != "{{baseUrl}}/pets?api_key={{apiKey}}&dryRun=false" {
+		t.Errorf("Unexpected URL %s", create.URL.Raw)
+	}
+	if !reflect.DeepEqual(create.Header, []keyValue{{Key: "Content-Type", Value: "application/json"}}) {
+		t.Errorf("Expected the Authorization header to be left out, got %+v", create.Header)
+	}
+	if create.Auth == nil || create.Auth.Type != "bearer" || create.Auth.Bearer[0].Value != "{{authToken}}" {
+		t.Errorf("Unexpected auth %+v", create.Auth)
+	}
+	if create.Body == nil || create.Body.Raw != "{\n  \"name\": \"Rex\"\n}" || create.Body.Options.Raw.Language != "json" {
+		t.Errorf("Unexpected body %+v", create.Body)
+	}
+	if !strings.Contains(strings.Join(c.Item[1].Item[0].Event[0].Script.Exec, "\n"), `pm.collectionVariables.set("petId", body.id);`) {
+		t.Errorf("Expected createPet to store petId, got %+v", c.Item[1].Item[0].Event)
+	}
+
+	get := c.Item[1].Item[1].Request
+	if get.URL.Raw != "{{baseUrl}}/pets/{{petId}}" || len(get.URL.Query) != 0 {
+		t.Errorf("Expected the API key to be left out of the query, got %+v", get.URL)
+	}
+	if get.Auth == nil || get.Auth.Type != "apikey" || get.Auth.APIKey[2].Value != "query" {
+		t.Errorf("Unexpected auth %+v", get.Auth)
+	}
+}
+
+func TestFormatter_FormatCollectionWithoutFolders(t *testing.T) {
+	file := &models.HTTPFile{
+		GlobalVars: map[string]string{"baseUrl": "http://localhost:8080"},
+		Requests:   []models.HTTPRequest{{Name: "List pets", Method: "GET", Path: "/pets"}},
+	}
+
+	content, err := NewFormatter().FormatCollection("API", []*models.HTTPFile{file})
+	if err != nil {
+		t.Fatalf("FormatCollection failed: %v", err)
+	}
+
+	var c collection
+	if err := json.Unmarshal(content, &c); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(c.Item) != 1 || c.Item[0].Request == nil || c.Item[0].Name != "List pets" {
+		t.Errorf("Expected a single request at the top level, got %+v", c.Item)
+	}
+}
+
+func TestFormatter_FormatCollectionMultipart(t *testing.T) {
+	file := &models.HTTPFile{
+		GlobalVars: map[string]string{"baseUrl": "http://localhost:8080"},
+		Requests: []models.HTTPRequest{{
+			Name:    "Upload photo",
+			Method:  "POST",
+			Path:    "/photos",
+			Headers: map[string]string{"Content-Type": "multipart/form-data; boundary=WebAppBoundary"},
+			FormParts: []models.FormPart{
+				{Name: "caption", Value: "{{caption}}"},
+				{Name: "photo", File: "./photo.png", FileName: "photo.png", ContentType: "image/png"},
+			},
+			Vars: map[string]string{"caption": "Rex"},
+		}},
+	}
+
+	content, err := NewFormatter().FormatCollection("API", []*models.HTTPFile{file})
+	if err != nil {
+		t.Fatalf("FormatCollection failed: %v", err)
+	}
+
+	var c collection
+	if err := json.Unmarshal(content, &c); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+
+	// Postman sets the Content-Type itself, with its own boundary
+	upload := c.Item[0].Request
+	if len(upload.Header) != 0 {
+		t.Errorf("Expected the Content-Type header to be left out, got %+v", upload.Header)
+	}
+	expected := []formParam{
+		{Key: "caption", Value: "Rex", Type: "text"},
+		{Key: "photo", Src: "./photo.png", Type: "file", ContentType: "image/png"},
+	}
+	if upload.Body == nil || upload.Body.Mode != "formdata" || !reflect.DeepEqual(upload.Body.FormData, expected) {
+		t.Errorf("Expected form data %+v, got %+v", expected, upload.Body)
+	}
+}
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected requestURL
+	}{
+		{
+			raw:      "{{baseUrl}}/pets/{{petId}}",
+			expected: requestURL{Raw: "{{baseUrl}}/pets/{{petId}}", Host: []string{"{{baseUrl}}"}, Path: []string{"pets", "{{petId}}"}},
+		},
+		{
+			raw: "http://localhost:8080/v1/pets?limit=10&tag=a%20b",
+			expected: requestURL{
+				Raw:      "http://localhost:8080/v1/pets?limit=10&tag=a%20b",
+				Protocol: "http",
+				Host:     []string{"localhost"},
+				Port:     "8080",
+				Path:     []string{"v1", "pets"},
+				Query:    []keyValue{{Key: "limit", Value: "10"}, {Key: "tag", Value: "a%20b"}},
+			},
+		},
+		{
+			raw:      "{{oidcTokenEndpoint}}",
+			expected: requestURL{Raw: "{{oidcTokenEndpoint}}", Host: []string{"{{oidcTokenEndpoint}}"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := parseURL(tt.raw); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
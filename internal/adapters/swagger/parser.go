@@ -6,15 +6,53 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/edgardnogueira/swagger-to-http-file/internal/application/parser"
 	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
 )
 
 // Parser implements the application.SwaggerParser interface
-type Parser struct{}
+type Parser struct {
+	baseLocation string
+	loader       Loader
+}
+
+// SetBaseLocation sets where the parsed document was read from.
+// Relative $ref locations are resolved against it.
+func (p *Parser) SetBaseLocation(location string) {
+	p.baseLocation = location
+}
+
+// SetLoader sets the Loader used to read documents referenced by $ref
+func (p *Parser) SetLoader(loader Loader) {
+	p.loader = loader
+}
 
-// Parse parses Swagger JSON data and returns a SwaggerDoc
+// Parse parses Swagger JSON or YAML data and returns a SwaggerDoc.
+// The encoding is detected from the content.
 func (p *Parser) Parse(data []byte) (*models.SwaggerDoc, error) {
+	return p.ParseFormat(data, DetectFormat(data))
+}
+
+// ParseFormat parses Swagger data in the given format and returns a SwaggerDoc.
+// FormatUnknown falls back to detecting the encoding from the content.
+func (p *Parser) ParseFormat(data []byte, format Format) (*models.SwaggerDoc, error) {
+	if format == FormatUnknown {
+		format = DetectFormat(data)
+	}
+
 	var doc models.SwaggerDoc
+
+	if format == FormatYAML {
+		jsonData, spans, err := yamlToJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Swagger YAML: %w", err)
+		}
+		if err := json.Unmarshal(jsonData, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse Swagger YAML: %w", yamlErrorAt(err, spans))
+		}
+		return &doc, nil
+	}
+
 	if err := json.Unmarshal(data, &doc); err != nil {
 		return nil, fmt.Errorf("failed to parse Swagger JSON: %w", err)
 	}
@@ -39,7 +77,7 @@ func (p *Parser) Validate(doc *models.SwaggerDoc) error {
 	}
 
 	// Validate paths and operations
-	if err := p.validatePaths(doc); err != nil {
+	if err := p.validatePaths(doc, p.resolver(doc)); err != nil {
 		return err
 	}
 
@@ -47,13 +85,14 @@ func (p *Parser) Validate(doc *models.SwaggerDoc) error {
 }
 
 // validatePaths validates the paths and operations in the Swagger document
-func (p *Parser) validatePaths(doc *models.SwaggerDoc) error {
-	for path, pathItem := range doc.Paths {
+func (p *Parser) validatePaths(doc *models.SwaggerDoc, resolver *Resolver) error {
+	for _, path := range doc.PathNames() {
+		pathItem := doc.Paths[path]
 		if !strings.HasPrefix(path, "/") {
 			return fmt.Errorf("path %q must begin with a forward slash", path)
 		}
 
-		if err := p.validatePathItem(path, &pathItem); err != nil {
+		if err := p.validatePathItem(path, &pathItem, resolver); err != nil {
 			return err
 		}
 	}
@@ -61,55 +100,61 @@ func (p *Parser) validatePaths(doc *models.SwaggerDoc) error {
 }
 
 // validatePathItem validates a single path item and its operations
-func (p *Parser) validatePathItem(path string, item *models.PathItem) error {
+func (p *Parser) validatePathItem(path string, item *models.PathItem, resolver *Resolver) error {
 	// Validate operations
 	if item.Get != nil {
-		if err := p.validateOperation(path, "GET", item.Get); err != nil {
+		if err := p.validateOperation(path, "GET", item.Get, item.Parameters, resolver); err != nil {
 			return err
 		}
 	}
 	if item.Post != nil {
-		if err := p.validateOperation(path, "POST", item.Post); err != nil {
+		if err := p.validateOperation(path, "POST", item.Post, item.Parameters, resolver); err != nil {
 			return err
 		}
 	}
 	if item.Put != nil {
-		if err := p.validateOperation(path, "PUT", item.Put); err != nil {
+		if err := p.validateOperation(path, "PUT", item.Put, item.Parameters, resolver); err != nil {
 			return err
 		}
 	}
 	if item.Delete != nil {
-		if err := p.validateOperation(path, "DELETE", item.Delete); err != nil {
+		if err := p.validateOperation(path, "DELETE", item.Delete, item.Parameters, resolver); err != nil {
 			return err
 		}
 	}
 	if item.Options != nil {
-		if err := p.validateOperation(path, "OPTIONS", item.Options); err != nil {
+		if err := p.validateOperation(path, "OPTIONS", item.Options, item.Parameters, resolver); err != nil {
 			return err
 		}
 	}
 	if item.Head != nil {
-		if err := p.validateOperation(path, "HEAD", item.Head); err != nil {
+		if err := p.validateOperation(path, "HEAD", item.Head, item.Parameters, resolver); err != nil {
 			return err
 		}
 	}
 	if item.Patch != nil {
-		if err := p.validateOperation(path, "PATCH", item.Patch); err != nil {
+		if err := p.validateOperation(path, "PATCH", item.Patch, item.Parameters, resolver); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// validateOperation validates a single operation in a path
-func (p *Parser) validateOperation(path, method string, op *models.Operation) error {
+// validateOperation validates a single operation in a path, together with the
+// parameters it inherits from the path item
+func (p *Parser) validateOperation(path, method string, op *models.Operation, pathParams []models.Parameter, resolver *Resolver) error {
 	// Check if responses are defined (required by OpenAPI spec)
 	if len(op.Responses) == 0 {
 		return fmt.Errorf("no responses defined for %s %s", method, path)
 	}
 
+	params, err := mergeParameters(resolver, pathParams, op.Parameters)
+	if err != nil {
+		return fmt.Errorf("invalid parameter for %s %s: %w", method, path, err)
+	}
+
 	// Validate parameters
-	for i, param := range op.Parameters {
+	for i, param := range params {
 		if param.Name == "" {
 			return fmt.Errorf("parameter %d for %s %s has no name", i, method, path)
 		}
@@ -129,9 +174,9 @@ func (p *Parser) validateOperation(path, method string, op *models.Operation) er
 
 // GetBaseURL computes the base URL from the Swagger document
 func (p *Parser) GetBaseURL(doc *models.SwaggerDoc) string {
-	// For OpenAPI v3
+	// For OpenAPI v3, with server variables set to their defaults
 	if len(doc.Servers) > 0 && doc.Servers[0].URL != "" {
-		return doc.Servers[0].URL
+		return doc.Servers[0].ExpandURL()
 	}
 
 	// For Swagger v2
@@ -147,34 +192,67 @@ func (p *Parser) GetBaseURL(doc *models.SwaggerDoc) string {
 	return "http://localhost"
 }
 
-// ExtractOperations extracts all operations from the Swagger document
+// ExtractOperations extracts all operations from the Swagger document.
+// Operations are listed in the order their paths are declared in the document.
 func (p *Parser) ExtractOperations(doc *models.SwaggerDoc) map[string][]models.OperationInfo {
 	operations := make(map[string][]models.OperationInfo)
+	resolver := p.resolver(doc)
 
-	for path, pathItem := range doc.Paths {
-		p.addOperation(operations, path, "GET", pathItem.Get)
-		p.addOperation(operations, path, "POST", pathItem.Post)
-		p.addOperation(operations, path, "PUT", pathItem.Put)
-		p.addOperation(operations, path, "DELETE", pathItem.Delete)
-		p.addOperation(operations, path, "OPTIONS", pathItem.Options)
-		p.addOperation(operations, path, "HEAD", pathItem.Head)
-		p.addOperation(operations, path, "PATCH", pathItem.Patch)
+	for _, path := range doc.PathNames() {
+		pathItem := doc.Paths[path]
+		p.addOperation(operations, doc, resolver, path, "GET", &pathItem, pathItem.Get)
+		p.addOperation(operations, doc, resolver, path, "POST", &pathItem, pathItem.Post)
+		p.addOperation(operations, doc, resolver, path, "PUT", &pathItem, pathItem.Put)
+		p.addOperation(operations, doc, resolver, path, "DELETE", &pathItem, pathItem.Delete)
+		p.addOperation(operations, doc, resolver, path, "OPTIONS", &pathItem, pathItem.Options)
+		p.addOperation(operations, doc, resolver, path, "HEAD", &pathItem, pathItem.Head)
+		p.addOperation(operations, doc, resolver, path, "PATCH", &pathItem, pathItem.Patch)
 	}
 
 	return operations
 }
 
-// addOperation adds an operation to the operations map, organized by tag
-func (p *Parser) addOperation(operations map[string][]models.OperationInfo, path, method string, op *models.Operation) {
+// addOperation adds an operation to the operations map, organized by tag.
+// The operation inherits the path-level parameters and servers, and the
+// document-level consumes/produces media types and security requirement.
+func (p *Parser) addOperation(operations map[string][]models.OperationInfo, doc *models.SwaggerDoc, resolver *Resolver, path, method string, item *models.PathItem, op *models.Operation) {
 	if op == nil {
 		return
 	}
 
+	// Unresolvable parameters are reported by Validate, skip them here
+	params, _ := mergeParameters(resolver, item.Parameters, op.Parameters)
+
+	consumes := op.Consumes
+	if len(consumes) == 0 {
+		consumes = doc.Consumes
+	}
+	produces := op.Produces
+	if len(produces) == 0 {
+		produces = doc.Produces
+	}
+
+	// An explicit empty list ("security: []") removes the document requirement
+	security := op.Security
+	if security == nil {
+		security = doc.Security
+	}
+
+	// Operation servers take precedence over path servers
+	servers := op.Servers
+	if len(servers) == 0 {
+		servers = item.Servers
+	}
+
 	info := models.OperationInfo{
 		Path:       path,
 		Method:     method,
 		Operation:  op,
-		Parameters: op.Parameters,
+		Parameters: params,
+		Consumes:   consumes,
+		Produces:   produces,
+		Security:   security,
+		Servers:    servers,
 	}
 
 	// Group by tag, or use "default" if no tags present
@@ -188,6 +266,48 @@ func (p *Parser) addOperation(operations map[string][]models.OperationInfo, path
 	}
 }
 
+// mergeParameters combines path-level and operation-level parameters and
+// resolves their references. An operation parameter overrides the path
+// parameter with the same name and location. Parameters that cannot be
+// resolved are left out and the first resolution error is returned.
+func mergeParameters(resolver *Resolver, pathParams, opParams []models.Parameter) ([]models.Parameter, error) {
+	var merged []models.Parameter
+	var firstErr error
+	index := make(map[string]int)
+
+	for _, list := range [][]models.Parameter{pathParams, opParams} {
+		for i := range list {
+			param, err := resolver.ResolveParameter(&list[i])
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+
+			key := param.In + ":" + param.Name
+			if pos, ok := index[key]; ok {
+				merged[pos] = *param
+				continue
+			}
+			index[key] = len(merged)
+			merged = append(merged, *param)
+		}
+	}
+
+	return merged, firstErr
+}
+
+// Resolver returns a RefResolver for $ref pointers in the document
+func (p *Parser) Resolver(doc *models.SwaggerDoc) parser.RefResolver {
+	return p.resolver(doc)
+}
+
+// resolver creates the Resolver used by the parser itself
+func (p *Parser) resolver(doc *models.SwaggerDoc) *Resolver {
+	return NewResolver(doc, p.baseLocation, p.loader)
+}
+
 // New creates a new Parser instance
 func New() *Parser {
 	return &Parser{}
@@ -2,6 +2,7 @@ package swagger
 
 import (
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
@@ -14,7 +15,13 @@ func TestParser_Parse(t *testing.T) {
 		t.Fatalf("Failed to read test file: %v", err)
 	}
 
+	validYAML, err := os.ReadFile("../../../test/samples/petstore.yaml")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
 	invalidJSON := []byte("{invalid:json}")
+	invalidYAML := []byte("swagger: \"2.0\"\npaths:\n  /pets: [\n")
 
 	tests := []struct {
 		name    string
@@ -26,11 +33,21 @@ func TestParser_Parse(t *testing.T) {
 			data:    validData,
 			wantErr: false,
 		},
+		{
+			name:    "valid swagger yaml",
+			data:    validYAML,
+			wantErr: false,
+		},
 		{
 			name:    "invalid json",
 			data:    invalidJSON,
 			wantErr: true,
 		},
+		{
+			name:    "invalid yaml",
+			data:    invalidYAML,
+			wantErr: true,
+		},
 		{
 			name:    "empty data",
 			data:    []byte{},
@@ -54,6 +71,34 @@ func TestParser_Parse(t *testing.T) {
 	}
 }
 
+func TestParser_ParseYAMLMatchesJSON(t *testing.T) {
+	jsonData, err := os.ReadFile("../../../test/samples/petstore.json")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	yamlData, err := os.ReadFile("../../../test/samples/petstore.yaml")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	parser := New()
+
+	jsonDoc, err := parser.ParseFormat(jsonData, FormatJSON)
+	if err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	yamlDoc, err := parser.ParseFormat(yamlData, FormatYAML)
+	if err != nil {
+		t.Fatalf("Failed to parse YAML: %v", err)
+	}
+
+	if !reflect.DeepEqual(jsonDoc, yamlDoc) {
+		t.Errorf("YAML document differs from JSON document\nJSON: %+v\nYAML: %+v", jsonDoc, yamlDoc)
+	}
+}
+
 func TestParser_Validate(t *testing.T) {
 	// Load test data
 	validData, err := os.ReadFile("../../../test/samples/petstore.json")
@@ -235,6 +280,21 @@ func TestParser_GetBaseURL(t *testing.T) {
 			},
 			want: "https://staging.example.com/api",
 		},
+		{
+			name: "openapi v3 server variables use their defaults",
+			doc: &models.SwaggerDoc{
+				Servers: []models.Server{
+					{
+						URL: "https://{region}.example.com/{version}",
+						Variables: map[string]models.ServerVariable{
+							"region":  {Default: "eu"},
+							"version": {Default: "v2"},
+						},
+					},
+				},
+			},
+			want: "https://eu.example.com/v2",
+		},
 		{
 			name: "fallback to default",
 			doc:  &models.SwaggerDoc{},
@@ -328,3 +388,123 @@ func TestParser_ExtractOperations(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_ExtractOperationsMergesParameters(t *testing.T) {
+	data, err := os.ReadFile("../../../test/samples/shared-params.json")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	parser := New()
+	doc, err := parser.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse data: %v", err)
+	}
+	if err := parser.Validate(doc); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	ops := make(map[string]models.OperationInfo)
+	for _, op := range parser.ExtractOperations(doc)["users"] {
+		ops[op.Method] = op
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		expectParams []string
+		pageSize     interface{}
+		consumes     string
+		produces     string
+	}{
+		{
+			name:         "operation parameter overrides path parameter",
+			method:       "GET",
+			expectParams: []string{"path:tenantId", "query:pageSize"},
+			pageSize:     float64(50),
+			consumes:     "application/json",
+			produces:     "application/json",
+		},
+		{
+			name:         "path parameters are inherited",
+			method:       "POST",
+			expectParams: []string{"path:tenantId", "query:pageSize", "body:user"},
+			pageSize:     float64(20),
+			consumes:     "application/json",
+			produces:     "application/xml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op, ok := ops[tt.method]
+			if !ok {
+				t.Fatalf("Operation %s not found", tt.method)
+			}
+
+			var params []string
+			for _, param := range op.Parameters {
+				if param.Ref != "" {
+					t.Errorf("Parameter %q was not resolved", param.Ref)
+				}
+				params = append(params, param.In+":"+param.Name)
+				if param.Name == "pageSize" && param.Default != tt.pageSize {
+					t.Errorf("Expected pageSize default %v, got %v", tt.pageSize, param.Default)
+				}
+			}
+			if !reflect.DeepEqual(params, tt.expectParams) {
+				t.Errorf("Expected parameters %v, got %v", tt.expectParams, params)
+			}
+
+			if len(op.Consumes) == 0 || op.Consumes[0] != tt.consumes {
+				t.Errorf("Expected consumes %q, got %v", tt.consumes, op.Consumes)
+			}
+			if len(op.Produces) == 0 || op.Produces[0] != tt.produces {
+				t.Errorf("Expected produces %q, got %v", tt.produces, op.Produces)
+			}
+		})
+	}
+}
+
+func TestParser_ValidateParameterRefs(t *testing.T) {
+	doc := &models.SwaggerDoc{
+		OpenAPI: "3.0.0",
+		Info:    models.Info{Title: "Test API", Version: "1.0.0"},
+		Components: &models.Components{
+			Parameters: map[string]models.Parameter{
+				"tenantId": {Name: "tenantId", In: "path", Required: true},
+				"optional": {Name: "orgId", In: "path"},
+			},
+		},
+		Paths: map[string]models.PathItem{},
+	}
+
+	tests := []struct {
+		name    string
+		ref     string
+		wantErr bool
+	}{
+		{name: "resolved parameter", ref: "#/components/parameters/tenantId", wantErr: false},
+		{name: "resolved parameter is validated", ref: "#/components/parameters/optional", wantErr: true},
+		{name: "missing parameter", ref: "#/components/parameters/missing", wantErr: true},
+	}
+
+	parser := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc.Paths = map[string]models.PathItem{
+				"/{tenantId}/users": {
+					Parameters: []models.Parameter{{Ref: tt.ref}},
+					Get: &models.Operation{
+						Responses: map[string]models.Response{"200": {Description: "OK"}},
+					},
+				},
+			}
+
+			err := parser.Validate(doc)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
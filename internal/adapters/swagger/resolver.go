@@ -0,0 +1,584 @@
+package swagger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// Loader reads the raw document found at a location, such as a file path or URL
+type Loader func(location string) ([]byte, error)
+
+// Resolver implements the application.RefResolver interface.
+// Local references are looked up in the root document, relative references
+// are loaded through the Loader relative to the location of the root document.
+type Resolver struct {
+	doc      *models.SwaggerDoc
+	base     string
+	loader   Loader
+	external map[string][]byte
+}
+
+// ResolveSchema follows a schema $ref, returning the referenced schema
+func (r *Resolver) ResolveSchema(schema *models.SchemaObj) (*models.SchemaObj, error) {
+	seen := make(map[string]bool)
+	for schema != nil && schema.Ref != "" {
+		ref := schema.Ref
+		if err := checkCycle(seen, ref); err != nil {
+			return nil, err
+		}
+
+		next := &models.SchemaObj{}
+		if err := r.lookup(ref, next); err != nil {
+			return nil, err
+		}
+		schema = next
+	}
+	return schema, nil
+}
+
+// ResolveParameter follows a parameter $ref, returning the referenced parameter
+func (r *Resolver) ResolveParameter(param *models.Parameter) (*models.Parameter, error) {
+	seen := make(map[string]bool)
+	for param != nil && param.Ref != "" {
+		ref := param.Ref
+		if err := checkCycle(seen, ref); err != nil {
+			return nil, err
+		}
+
+		next := &models.Parameter{}
+		if err := r.lookup(ref, next); err != nil {
+			return nil, err
+		}
+		param = next
+	}
+	return param, nil
+}
+
+// ResolveRequestBody follows a request body $ref, returning the referenced request body
+func (r *Resolver) ResolveRequestBody(body *models.RequestBody) (*models.RequestBody, error) {
+	seen := make(map[string]bool)
+	for body != nil && body.Ref != "" {
+		ref := body.Ref
+		if err := checkCycle(seen, ref); err != nil {
+			return nil, err
+		}
+
+		next := &models.RequestBody{}
+		if err := r.lookup(ref, next); err != nil {
+			return nil, err
+		}
+		body = next
+	}
+	return body, nil
+}
+
+// ResolveResponse follows a response $ref, returning the referenced response
+func (r *Resolver) ResolveResponse(resp *models.Response) (*models.Response, error) {
+	seen := make(map[string]bool)
+	for resp != nil && resp.Ref != "" {
+		ref := resp.Ref
+		if err := checkCycle(seen, ref); err != nil {
+			return nil, err
+		}
+
+		next := &models.Response{}
+		if err := r.lookup(ref, next); err != nil {
+			return nil, err
+		}
+		resp = next
+	}
+	return resp, nil
+}
+
+// ResolveHeader follows a header $ref, returning the referenced header
+func (r *Resolver) ResolveHeader(header *models.Header) (*models.Header, error) {
+	seen := make(map[string]bool)
+	for header != nil && header.Ref != "" {
+		ref := header.Ref
+		if err := checkCycle(seen, ref); err != nil {
+			return nil, err
+		}
+
+		next := &models.Header{}
+		if err := r.lookup(ref, next); err != nil {
+			return nil, err
+		}
+		header = next
+	}
+	return header, nil
+}
+
+// ResolveExample follows an example $ref, returning the referenced example
+func (r *Resolver) ResolveExample(example *models.Example) (*models.Example, error) {
+	seen := make(map[string]bool)
+	for example != nil && example.Ref != "" {
+		ref := example.Ref
+		if err := checkCycle(seen, ref); err != nil {
+			return nil, err
+		}
+
+		next := &models.Example{}
+		if err := r.lookup(ref, next); err != nil {
+			return nil, err
+		}
+		example = next
+	}
+	return example, nil
+}
+
+// checkCycle records a reference, failing if it was already followed
+func checkCycle(seen map[string]bool, ref string) error {
+	if seen[ref] {
+		return fmt.Errorf("circular reference %q", ref)
+	}
+	seen[ref] = true
+	return nil
+}
+
+// lookup decodes the object a reference points to into target
+func (r *Resolver) lookup(ref string, target interface{}) error {
+	location, fragment, _ := strings.Cut(ref, "#")
+
+	tokens, err := parsePointer(fragment)
+	if err != nil {
+		return fmt.Errorf("invalid reference %q: %w", ref, err)
+	}
+
+	if location != "" {
+		location = resolveLocation(r.base, location)
+	}
+
+	// References to the root document use the already parsed models
+	if location == "" || location == r.base {
+		if err := r.lookupRoot(tokens, target); err != nil {
+			return fmt.Errorf("failed to resolve reference %q: %w", ref, err)
+		}
+		return nil
+	}
+
+	data, err := r.load(location)
+	if err != nil {
+		return fmt.Errorf("failed to resolve reference %q: %w", ref, err)
+	}
+
+	raw, err := lookupPointer(data, tokens)
+	if err != nil {
+		return fmt.Errorf("failed to resolve reference %q: %w", ref, err)
+	}
+
+	if err := json.Unmarshal(raw, target); err != nil {
+		return fmt.Errorf("failed to resolve reference %q: %w", ref, err)
+	}
+
+	// Nested references are relative to the external document, make them absolute
+	rebase(target, location, r.base)
+
+	return nil
+}
+
+// lookupRoot resolves a JSON pointer against the root document
+func (r *Resolver) lookupRoot(tokens []string, target interface{}) error {
+	if r.doc == nil {
+		return fmt.Errorf("no document to resolve against")
+	}
+
+	value, rest, err := r.rootComponent(tokens)
+	if err != nil {
+		return err
+	}
+
+	// Pointers into a component are resolved on its JSON representation
+	if len(rest) > 0 {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		raw, err := lookupPointer(data, rest)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(raw, target)
+	}
+
+	switch t := target.(type) {
+	case *models.SchemaObj:
+		if v, ok := value.(models.SchemaObj); ok {
+			*t = v
+			return nil
+		}
+	case *models.Parameter:
+		if v, ok := value.(models.Parameter); ok {
+			*t = v
+			return nil
+		}
+	case *models.RequestBody:
+		if v, ok := value.(models.RequestBody); ok {
+			*t = v
+			return nil
+		}
+	case *models.Response:
+		if v, ok := value.(models.Response); ok {
+			*t = v
+			return nil
+		}
+	case *models.Header:
+		if v, ok := value.(models.Header); ok {
+			*t = v
+			return nil
+		}
+	case *models.Example:
+		if v, ok := value.(models.Example); ok {
+			*t = v
+			return nil
+		}
+	}
+
+	return fmt.Errorf("reference points to a %T, not a %T", value, target)
+}
+
+// rootComponent finds the reusable component a pointer starts with.
+// It returns the component and the remaining pointer tokens.
+func (r *Resolver) rootComponent(tokens []string) (interface{}, []string, error) {
+	doc := r.doc
+
+	if len(tokens) >= 2 && tokens[0] == "definitions" {
+		if v, ok := doc.Definitions[tokens[1]]; ok {
+			return v, tokens[2:], nil
+		}
+		return nil, nil, fmt.Errorf("definition %q not found", tokens[1])
+	}
+
+	// Swagger v2 reusable parameters and responses
+	if len(tokens) >= 2 && tokens[0] == "parameters" {
+		if v, ok := doc.Parameters[tokens[1]]; ok {
+			return v, tokens[2:], nil
+		}
+		return nil, nil, fmt.Errorf("parameter %q not found", tokens[1])
+	}
+	if len(tokens) >= 2 && tokens[0] == "responses" {
+		if v, ok := doc.Responses[tokens[1]]; ok {
+			return v, tokens[2:], nil
+		}
+		return nil, nil, fmt.Errorf("response %q not found", tokens[1])
+	}
+
+	if len(tokens) >= 3 && tokens[0] == "components" {
+		name := tokens[2]
+		var value interface{}
+		found := false
+
+		if doc.Components != nil {
+			switch tokens[1] {
+			case "schemas":
+				value, found = lookupMap(doc.Components.Schemas, name)
+			case "parameters":
+				value, found = lookupMap(doc.Components.Parameters, name)
+			case "responses":
+				value, found = lookupMap(doc.Components.Responses, name)
+			case "requestBodies":
+				value, found = lookupMap(doc.Components.RequestBodies, name)
+			case "headers":
+				value, found = lookupMap(doc.Components.Headers, name)
+			case "examples":
+				value, found = lookupMap(doc.Components.Examples, name)
+			default:
+				return nil, nil, fmt.Errorf("unsupported component type %q", tokens[1])
+			}
+		}
+
+		if !found {
+			return nil, nil, fmt.Errorf("component %s/%s not found", tokens[1], name)
+		}
+		return value, tokens[3:], nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported pointer %q", "/"+strings.Join(tokens, "/"))
+}
+
+// lookupMap returns a map entry as an interface value
+func lookupMap[T any](m map[string]T, key string) (interface{}, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+// load returns the JSON form of an external document, reading it at most once
+func (r *Resolver) load(location string) ([]byte, error) {
+	if data, ok := r.external[location]; ok {
+		return data, nil
+	}
+
+	data, err := r.loader(location)
+	if err != nil {
+		return nil, err
+	}
+
+	format := FormatFromPath(strings.SplitN(location, "?", 2)[0])
+	if format == FormatUnknown {
+		format = DetectFormat(data)
+	}
+
+	if format == FormatYAML {
+		converted, _, err := yamlToJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", location, err)
+		}
+		data = converted
+	}
+
+	r.external[location] = data
+	return data, nil
+}
+
+// parsePointer splits a URI fragment holding a JSON pointer into unescaped tokens
+func parsePointer(fragment string) ([]string, error) {
+	fragment, err := url.PathUnescape(fragment)
+	if err != nil {
+		return nil, err
+	}
+
+	if fragment == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(fragment, "/") {
+		return nil, fmt.Errorf("JSON pointer %q must start with '/'", fragment)
+	}
+
+	tokens := strings.Split(fragment[1:], "/")
+	for i, token := range tokens {
+		token = strings.ReplaceAll(token, "~1", "/")
+		tokens[i] = strings.ReplaceAll(token, "~0", "~")
+	}
+	return tokens, nil
+}
+
+// lookupPointer walks a JSON document following the given pointer tokens
+func lookupPointer(data []byte, tokens []string) ([]byte, error) {
+	current := data
+	for i, token := range tokens {
+		trimmed := bytes.TrimSpace(current)
+
+		if len(trimmed) > 0 && trimmed[0] == '[' {
+			var items []json.RawMessage
+			if err := json.Unmarshal(trimmed, &items); err != nil {
+				return nil, err
+			}
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(items) {
+				return nil, fmt.Errorf("index %q out of range at /%s", token, strings.Join(tokens[:i], "/"))
+			}
+			current = items[index]
+			continue
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &fields); err != nil {
+			return nil, fmt.Errorf("cannot descend into /%s", strings.Join(tokens[:i], "/"))
+		}
+		value, ok := fields[token]
+		if !ok {
+			return nil, fmt.Errorf("%q not found at /%s", token, strings.Join(tokens[:i], "/"))
+		}
+		current = value
+	}
+	return current, nil
+}
+
+// isURL reports whether a location is an HTTP(S) URL
+func isURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// resolveLocation resolves a document location relative to the base location.
+// File locations are made absolute so that resolving them again is a no-op.
+func resolveLocation(base, location string) string {
+	if isURL(location) {
+		return location
+	}
+
+	if isURL(base) {
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return location
+		}
+		ref, err := url.Parse(location)
+		if err != nil {
+			return location
+		}
+		return baseURL.ResolveReference(ref).String()
+	}
+
+	if base != "" && !filepath.IsAbs(location) {
+		location = filepath.Join(filepath.Dir(base), location)
+	}
+	return absPath(location)
+}
+
+// absPath returns an absolute version of a file path, or the cleaned path if that fails
+func absPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return abs
+}
+
+// rebaseRef makes a reference found in the document at location usable from the root document
+func rebaseRef(ref, location, root string) string {
+	refLocation, fragment, _ := strings.Cut(ref, "#")
+
+	if refLocation == "" {
+		refLocation = location
+	} else {
+		refLocation = resolveLocation(location, refLocation)
+	}
+
+	if refLocation == root {
+		refLocation = ""
+	}
+	return refLocation + "#" + fragment
+}
+
+// rebase rewrites all references inside a decoded object, see rebaseRef
+func rebase(target interface{}, location, root string) {
+	switch t := target.(type) {
+	case *models.SchemaObj:
+		rebaseSchema(t, location, root)
+	case *models.Parameter:
+		if t.Ref != "" {
+			t.Ref = rebaseRef(t.Ref, location, root)
+		}
+		rebaseSchema(t.Schema, location, root)
+		rebaseSchema(t.Items, location, root)
+		rebaseExamples(t.Examples, location, root)
+	case *models.RequestBody:
+		if t.Ref != "" {
+			t.Ref = rebaseRef(t.Ref, location, root)
+		}
+		rebaseContent(t.Content, location, root)
+	case *models.Response:
+		if t.Ref != "" {
+			t.Ref = rebaseRef(t.Ref, location, root)
+		}
+		rebaseSchema(t.Schema, location, root)
+		rebaseContent(t.Content, location, root)
+		for name, header := range t.Headers {
+			rebase(&header, location, root)
+			t.Headers[name] = header
+		}
+	case *models.Header:
+		if t.Ref != "" {
+			t.Ref = rebaseRef(t.Ref, location, root)
+		}
+		rebaseSchema(t.Schema, location, root)
+	case *models.Example:
+		if t.Ref != "" {
+			t.Ref = rebaseRef(t.Ref, location, root)
+		}
+	}
+}
+
+// rebaseContent rewrites the references in media type schemas and examples
+func rebaseContent(content map[string]models.MediaTypeObj, location, root string) {
+	for _, mediaType := range content {
+		rebaseSchema(mediaType.Schema, location, root)
+		rebaseExamples(mediaType.Examples, location, root)
+	}
+}
+
+// rebaseExamples rewrites the references of named examples
+func rebaseExamples(examples models.Examples, location, root string) {
+	for i := range examples {
+		if examples[i].Ref != "" {
+			examples[i].Ref = rebaseRef(examples[i].Ref, location, root)
+		}
+	}
+}
+
+// rebaseSchema rewrites the references in a schema and its subschemas
+func rebaseSchema(schema *models.SchemaObj, location, root string) {
+	if schema == nil {
+		return
+	}
+
+	if schema.Ref != "" {
+		schema.Ref = rebaseRef(schema.Ref, location, root)
+	}
+
+	rebaseSchema(schema.Items, location, root)
+	rebaseSchema(schema.Not, location, root)
+
+	for name, prop := range schema.Properties {
+		rebaseSchema(&prop, location, root)
+		schema.Properties[name] = prop
+	}
+
+	for _, branches := range [][]models.SchemaObj{schema.AllOf, schema.OneOf, schema.AnyOf} {
+		for i := range branches {
+			rebaseSchema(&branches[i], location, root)
+		}
+	}
+
+	// Mapping values are either schema names or references
+	if schema.Discriminator != nil {
+		for value, target := range schema.Discriminator.Mapping {
+			if strings.Contains(target, "#") {
+				schema.Discriminator.Mapping[value] = rebaseRef(target, location, root)
+			}
+		}
+	}
+
+	if additional, ok := schema.AdditionalProperties.(map[string]interface{}); ok {
+		rebaseValue(additional, location, root)
+	}
+}
+
+// rebaseValue rewrites "$ref" entries in an undecoded JSON object
+func rebaseValue(value map[string]interface{}, location, root string) {
+	for key, v := range value {
+		switch child := v.(type) {
+		case string:
+			if key == "$ref" {
+				value[key] = rebaseRef(child, location, root)
+			}
+		case map[string]interface{}:
+			rebaseValue(child, location, root)
+		case []interface{}:
+			for _, item := range child {
+				if m, ok := item.(map[string]interface{}); ok {
+					rebaseValue(m, location, root)
+				}
+			}
+		}
+	}
+}
+
+// readFile is the default Loader, reading documents from the local filesystem
+func readFile(location string) ([]byte, error) {
+	return os.ReadFile(location)
+}
+
+// NewResolver creates a Resolver for a document read from base.
+// A nil loader reads referenced files from the local filesystem.
+func NewResolver(doc *models.SwaggerDoc, base string, loader Loader) *Resolver {
+	if loader == nil {
+		loader = readFile
+	}
+	if base != "" && !isURL(base) {
+		base = absPath(base)
+	}
+
+	return &Resolver{
+		doc:      doc,
+		base:     base,
+		loader:   loader,
+		external: make(map[string][]byte),
+	}
+}
@@ -0,0 +1,272 @@
+package swagger
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+const refsSample = "../../../test/samples/refs/openapi.yaml"
+
+// loadRefsSample parses the multi-file sample and returns a resolver for it
+func loadRefsSample(t *testing.T) (*models.SwaggerDoc, *Resolver) {
+	t.Helper()
+
+	data, err := os.ReadFile(refsSample)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	parser := New()
+	doc, err := parser.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse data: %v", err)
+	}
+
+	return doc, NewResolver(doc, refsSample, nil)
+}
+
+func TestResolver_ResolveSchema(t *testing.T) {
+	_, resolver := loadRefsSample(t)
+
+	tests := []struct {
+		name      string
+		ref       string
+		wantProps []string
+		wantErr   string
+	}{
+		{
+			name:      "local component",
+			ref:       "#/components/schemas/Order",
+			wantProps: []string{"id", "customer", "lines", "parent"},
+		},
+		{
+			name:      "escaped pointer",
+			ref:       "#/components/schemas/Order~1Line",
+			wantProps: []string{"sku"},
+		},
+		{
+			name:      "percent encoded pointer",
+			ref:       "#/components/schemas/Order%7E1Line",
+			wantProps: []string{"sku"},
+		},
+		{
+			name:      "chain into external file",
+			ref:       "#/components/schemas/Customer",
+			wantProps: []string{"name", "address"},
+		},
+		{
+			name:      "relative file",
+			ref:       "common.yaml#/components/schemas/Error",
+			wantProps: []string{"code", "message"},
+		},
+		{
+			name:      "pointer into a component",
+			ref:       "#/components/schemas/Order/properties/lines/items",
+			wantProps: []string{"sku"},
+		},
+		{
+			name:    "circular chain",
+			ref:     "#/components/schemas/Ping",
+			wantErr: "circular reference",
+		},
+		{
+			name:    "missing component",
+			ref:     "#/components/schemas/Missing",
+			wantErr: "not found",
+		},
+		{
+			name:    "missing file",
+			ref:     "missing.yaml#/components/schemas/Error",
+			wantErr: "missing.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.ResolveSchema(&models.SchemaObj{Ref: tt.ref})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveSchema() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveSchema() error = %v", err)
+			}
+
+			if got.Ref != "" {
+				t.Errorf("ResolveSchema() returned unresolved reference %q", got.Ref)
+			}
+			for _, prop := range tt.wantProps {
+				if _, ok := got.Properties[prop]; !ok {
+					t.Errorf("ResolveSchema() result is missing property %q", prop)
+				}
+			}
+		})
+	}
+}
+
+func TestResolver_ExternalNestedRefs(t *testing.T) {
+	_, resolver := loadRefsSample(t)
+
+	customer, err := resolver.ResolveSchema(&models.SchemaObj{Ref: "#/components/schemas/Customer"})
+	if err != nil {
+		t.Fatalf("ResolveSchema() error = %v", err)
+	}
+
+	// The address reference is local to common.yaml and must still resolve from the root
+	address := customer.Properties["address"]
+	resolved, err := resolver.ResolveSchema(&address)
+	if err != nil {
+		t.Fatalf("ResolveSchema() error = %v", err)
+	}
+	if _, ok := resolved.Properties["city"]; !ok {
+		t.Errorf("expected address to resolve to the Address schema, got %+v", resolved)
+	}
+}
+
+func TestResolver_ResolveComponents(t *testing.T) {
+	doc, resolver := loadRefsSample(t)
+	op := doc.Paths["/orders"].Post
+
+	param, err := resolver.ResolveParameter(&op.Parameters[0])
+	if err != nil {
+		t.Fatalf("ResolveParameter() error = %v", err)
+	}
+	if param.Name != "X-Trace-Id" || param.In != "header" {
+		t.Errorf("ResolveParameter() = %+v, want the X-Trace-Id header", param)
+	}
+
+	body, err := resolver.ResolveRequestBody(op.RequestBody)
+	if err != nil {
+		t.Fatalf("ResolveRequestBody() error = %v", err)
+	}
+	if !body.Required || body.Content["application/json"].Schema == nil {
+		t.Errorf("ResolveRequestBody() = %+v, want the OrderBody request body", body)
+	}
+
+	created := op.Responses["201"]
+	resp, err := resolver.ResolveResponse(&created)
+	if err != nil {
+		t.Fatalf("ResolveResponse() error = %v", err)
+	}
+	if resp.Description != "Order created" {
+		t.Errorf("ResolveResponse() description = %q, want %q", resp.Description, "Order created")
+	}
+
+	location := resp.Headers["Location"]
+	header, err := resolver.ResolveHeader(&location)
+	if err != nil {
+		t.Fatalf("ResolveHeader() error = %v", err)
+	}
+	if header.Description != "URL of the new order" {
+		t.Errorf("ResolveHeader() description = %q, want %q", header.Description, "URL of the new order")
+	}
+
+	if _, err := resolver.ResolveParameter(&models.Parameter{Ref: "#/components/schemas/Order"}); err == nil {
+		t.Errorf("ResolveParameter() expected an error for a reference to a schema")
+	}
+}
+
+func TestResolver_ResolveExample(t *testing.T) {
+	data := []byte(`
+openapi: 3.0.0
+paths: {}
+components:
+  examples:
+    Dog:
+      summary: A dog
+      value:
+        name: Rex
+    Alias:
+      $ref: "#/components/examples/Dog"
+`)
+	doc, err := New().Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse data: %v", err)
+	}
+	resolver := NewResolver(doc, "", nil)
+
+	example, err := resolver.ResolveExample(&models.Example{Ref: "#/components/examples/Alias"})
+	if err != nil {
+		t.Fatalf("ResolveExample() error = %v", err)
+	}
+	if example.Summary != "A dog" || example.Value == nil {
+		t.Errorf("ResolveExample() = %+v, want the Dog example", example)
+	}
+
+	if _, err := resolver.ResolveExample(&models.Example{Ref: "#/components/examples/Cat"}); err == nil {
+		t.Errorf("ResolveExample() expected an error for a missing example")
+	}
+}
+
+func TestResolver_Loader(t *testing.T) {
+	doc := &models.SwaggerDoc{OpenAPI: "3.0.0"}
+	files := map[string]string{
+		"https://api.example.com/specs/schemas.json": `{"Pet": {"type": "object", "properties": {"owner": {"$ref": "people.json#/Person"}}}}`,
+		"https://api.example.com/specs/people.json":  `{"Person": {"type": "object", "properties": {"name": {"type": "string"}}}}`,
+	}
+
+	var loaded []string
+	loader := func(location string) ([]byte, error) {
+		loaded = append(loaded, location)
+		if data, ok := files[location]; ok {
+			return []byte(data), nil
+		}
+		return nil, os.ErrNotExist
+	}
+
+	resolver := NewResolver(doc, "https://api.example.com/specs/openapi.json", loader)
+
+	pet, err := resolver.ResolveSchema(&models.SchemaObj{Ref: "schemas.json#/Pet"})
+	if err != nil {
+		t.Fatalf("ResolveSchema() error = %v", err)
+	}
+
+	owner := pet.Properties["owner"]
+	person, err := resolver.ResolveSchema(&owner)
+	if err != nil {
+		t.Fatalf("ResolveSchema() error = %v", err)
+	}
+	if _, ok := person.Properties["name"]; !ok {
+		t.Errorf("expected owner to resolve to Person, got %+v", person)
+	}
+
+	// Each document is only fetched once
+	if _, err := resolver.ResolveSchema(&models.SchemaObj{Ref: "schemas.json#/Pet"}); err != nil {
+		t.Fatalf("ResolveSchema() error = %v", err)
+	}
+	if len(loaded) != 2 {
+		t.Errorf("expected 2 documents to be loaded, got %v", loaded)
+	}
+}
+
+func TestParsePointer(t *testing.T) {
+	tests := []struct {
+		fragment string
+		want     []string
+		wantErr  bool
+	}{
+		{fragment: "", want: nil},
+		{fragment: "/definitions/Pet", want: []string{"definitions", "Pet"}},
+		{fragment: "/paths/~1pets~1{id}/get", want: []string{"paths", "/pets/{id}", "get"}},
+		{fragment: "/a~01", want: []string{"a~1"}},
+		{fragment: "/a%20b", want: []string{"a b"}},
+		{fragment: "Pet", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fragment, func(t *testing.T) {
+			got, err := parsePointer(tt.fragment)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePointer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("parsePointer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
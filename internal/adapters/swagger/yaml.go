@@ -0,0 +1,417 @@
+package swagger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format identifies the encoding of a Swagger/OpenAPI document
+type Format int
+
+const (
+	// FormatUnknown means the encoding could not be determined
+	FormatUnknown Format = iota
+	// FormatJSON is a JSON encoded document
+	FormatJSON
+	// FormatYAML is a YAML encoded document
+	FormatYAML
+)
+
+// String returns the name of the format
+func (f Format) String() string {
+	switch f {
+	case FormatJSON:
+		return "JSON"
+	case FormatYAML:
+		return "YAML"
+	default:
+		return "unknown"
+	}
+}
+
+// DetectFormat guesses the encoding of a document from its content.
+// JSON documents always start with an object or array, anything else is treated as YAML.
+func DetectFormat(data []byte) Format {
+	trimmed := bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	trimmed = bytes.TrimLeft(trimmed, " \t\r\n")
+	if len(trimmed) == 0 {
+		return FormatJSON
+	}
+	if trimmed[0] == '{' || trimmed[0] == '[' {
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// FormatFromPath returns the format implied by a file extension, or FormatUnknown
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	default:
+		return FormatUnknown
+	}
+}
+
+// YAMLError is returned when a YAML document cannot be decoded.
+// Line and Column are 1-based and zero when the position is unknown.
+type YAMLError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+// Error implements the error interface
+func (e *YAMLError) Error() string {
+	if e.Line == 0 {
+		return e.Msg
+	}
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// yamlLineRe extracts the line number from yaml.v3 syntax errors
+var yamlLineRe = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// YAML 1.2 core schema patterns for plain scalars
+var (
+	yamlNullRe  = regexp.MustCompile(`^(~|null|Null|NULL)?$`)
+	yamlBoolRe  = regexp.MustCompile(`^(true|True|TRUE|false|False|FALSE)$`)
+	yamlIntRe   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlOctRe   = regexp.MustCompile(`^0o[0-7]+$`)
+	yamlHexRe   = regexp.MustCompile(`^0x[0-9a-fA-F]+$`)
+	yamlFloatRe = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+	yamlInfRe   = regexp.MustCompile(`^[-+]?(\.inf|\.Inf|\.INF)$`)
+	yamlNaNRe   = regexp.MustCompile(`^(\.nan|\.NaN|\.NAN)$`)
+)
+
+// yamlSpan records which YAML node produced a range of the generated JSON
+type yamlSpan struct {
+	start, end   int
+	line, column int
+}
+
+// yamlToJSON converts a YAML document into equivalent JSON, preserving key order.
+// The returned spans map offsets in the JSON output back to YAML positions.
+func yamlToJSON(data []byte) ([]byte, []yamlSpan, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		if m := yamlLineRe.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			return nil, nil, &YAMLError{Line: line, Msg: m[2]}
+		}
+		return nil, nil, &YAMLError{Msg: strings.TrimPrefix(err.Error(), "yaml: ")}
+	}
+
+	if len(root.Content) == 0 {
+		return nil, nil, &YAMLError{Msg: "document is empty"}
+	}
+
+	enc := &yamlEncoder{}
+	if err := enc.encode(root.Content[0], 0); err != nil {
+		return nil, nil, err
+	}
+
+	return enc.buf.Bytes(), enc.spans, nil
+}
+
+// yamlErrorAt maps an error returned by json.Unmarshal on converted YAML back to a YAMLError
+func yamlErrorAt(err error, spans []yamlSpan) error {
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		return &YAMLError{Msg: err.Error()}
+	}
+
+	msg := fmt.Sprintf("cannot use %s as %s", typeErr.Value, typeErr.Type)
+	if typeErr.Field != "" {
+		msg = fmt.Sprintf("cannot use %s as %s for field %s", typeErr.Value, typeErr.Type, typeErr.Field)
+	}
+
+	// Pick the innermost node whose JSON output contains the error offset
+	offset := int(typeErr.Offset)
+	best := -1
+	for i, s := range spans {
+		if s.start > offset || offset > s.end {
+			continue
+		}
+		if best < 0 || s.end-s.start < spans[best].end-spans[best].start {
+			best = i
+		}
+	}
+	if best < 0 {
+		return &YAMLError{Msg: msg}
+	}
+
+	return &YAMLError{Line: spans[best].line, Column: spans[best].column, Msg: msg}
+}
+
+// maxYAMLDepth guards against runaway nesting through anchors and aliases
+const maxYAMLDepth = 512
+
+// yamlEncoder writes yaml.Node trees as JSON
+type yamlEncoder struct {
+	buf   bytes.Buffer
+	spans []yamlSpan
+}
+
+func (e *yamlEncoder) errorf(node *yaml.Node, format string, args ...interface{}) error {
+	return &YAMLError{Line: node.Line, Column: node.Column, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (e *yamlEncoder) encode(node *yaml.Node, depth int) error {
+	if depth > maxYAMLDepth {
+		return e.errorf(node, "document is nested too deeply")
+	}
+
+	start := e.buf.Len()
+	var err error
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			e.buf.WriteString("null")
+		} else {
+			err = e.encode(node.Content[0], depth+1)
+		}
+	case yaml.AliasNode:
+		err = e.encode(node.Alias, depth+1)
+	case yaml.SequenceNode:
+		e.buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+			if err = e.encode(item, depth+1); err != nil {
+				break
+			}
+		}
+		e.buf.WriteByte(']')
+	case yaml.MappingNode:
+		err = e.encodeMapping(node, depth)
+	case yaml.ScalarNode:
+		err = e.encodeScalar(node)
+	default:
+		err = e.errorf(node, "unsupported YAML node")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	e.spans = append(e.spans, yamlSpan{start: start, end: e.buf.Len(), line: node.Line, column: node.Column})
+	return nil
+}
+
+// yamlEntry is a single key/value pair of a mapping after merge keys are applied
+type yamlEntry struct {
+	key   string
+	value *yaml.Node
+}
+
+// encodeMapping writes a mapping as a JSON object, applying "<<" merge keys
+func (e *yamlEncoder) encodeMapping(node *yaml.Node, depth int) error {
+	entries, err := e.mappingEntries(node, depth)
+	if err != nil {
+		return err
+	}
+
+	e.buf.WriteByte('{')
+	for i, entry := range entries {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(entry.key)
+		e.buf.Write(key)
+		e.buf.WriteByte(':')
+		if err := e.encode(entry.value, depth+1); err != nil {
+			return err
+		}
+	}
+	e.buf.WriteByte('}')
+
+	return nil
+}
+
+// mappingEntries returns the entries of a mapping in source order.
+// Explicit keys take precedence over merged ones and duplicate keys are rejected.
+func (e *yamlEncoder) mappingEntries(node *yaml.Node, depth int) ([]yamlEntry, error) {
+	if depth > maxYAMLDepth {
+		return nil, e.errorf(node, "document is nested too deeply")
+	}
+
+	var entries []yamlEntry
+	var merged []yamlEntry
+	seen := make(map[string]bool)
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+
+		if keyNode.Kind == yaml.ScalarNode && keyNode.ShortTag() == "!!merge" {
+			sources := []*yaml.Node{valueNode}
+			target := resolveAlias(valueNode)
+			if target.Kind == yaml.SequenceNode {
+				sources = target.Content
+			}
+			for _, src := range sources {
+				src = resolveAlias(src)
+				if src.Kind != yaml.MappingNode {
+					return nil, e.errorf(src, "merge key value must be a mapping")
+				}
+				srcEntries, err := e.mappingEntries(src, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				merged = append(merged, srcEntries...)
+			}
+			continue
+		}
+
+		if keyNode.Kind != yaml.ScalarNode {
+			return nil, e.errorf(keyNode, "mapping keys must be scalars")
+		}
+		if seen[keyNode.Value] {
+			return nil, e.errorf(keyNode, "mapping key %q already defined", keyNode.Value)
+		}
+		seen[keyNode.Value] = true
+		entries = append(entries, yamlEntry{key: keyNode.Value, value: valueNode})
+	}
+
+	for _, entry := range merged {
+		if !seen[entry.key] {
+			seen[entry.key] = true
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// encodeScalar writes a scalar using the YAML 1.2 core schema.
+// Number literals are copied verbatim so no precision is lost.
+func (e *yamlEncoder) encodeScalar(node *yaml.Node) error {
+	tag := node.ShortTag()
+	if node.Style&yaml.TaggedStyle == 0 {
+		tag = resolveCoreTag(node)
+	}
+
+	value := node.Value
+	switch tag {
+	case "!!null":
+		e.buf.WriteString("null")
+	case "!!bool":
+		e.buf.WriteString(strconv.FormatBool(strings.ToLower(value) == "true"))
+	case "!!int":
+		switch {
+		case yamlIntRe.MatchString(value):
+			e.buf.WriteString(normalizeInt(value))
+		case yamlOctRe.MatchString(value), yamlHexRe.MatchString(value):
+			n, err := strconv.ParseUint(value[2:], map[byte]int{'o': 8, 'x': 16}[value[1]], 64)
+			if err != nil {
+				return e.errorf(node, "invalid integer %q", value)
+			}
+			e.buf.WriteString(strconv.FormatUint(n, 10))
+		default:
+			return e.errorf(node, "invalid integer %q", value)
+		}
+	case "!!float":
+		if yamlInfRe.MatchString(value) || yamlNaNRe.MatchString(value) {
+			return e.errorf(node, "%s cannot be represented in JSON", value)
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsInf(f, 0) {
+			return e.errorf(node, "invalid float %q", value)
+		}
+		e.buf.WriteString(normalizeFloat(value))
+	default:
+		encoded, _ := json.Marshal(value)
+		e.buf.Write(encoded)
+	}
+
+	return nil
+}
+
+// resolveCoreTag resolves the tag of an untagged scalar following the YAML 1.2 core schema
+func resolveCoreTag(node *yaml.Node) string {
+	if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return "!!str"
+	}
+
+	value := node.Value
+	switch {
+	case yamlNullRe.MatchString(value):
+		return "!!null"
+	case yamlBoolRe.MatchString(value):
+		return "!!bool"
+	case yamlIntRe.MatchString(value), yamlOctRe.MatchString(value), yamlHexRe.MatchString(value):
+		return "!!int"
+	case yamlFloatRe.MatchString(value), yamlInfRe.MatchString(value), yamlNaNRe.MatchString(value):
+		return "!!float"
+	default:
+		return "!!str"
+	}
+}
+
+// normalizeInt strips the sign prefix and leading zeros that JSON does not allow
+func normalizeInt(value string) string {
+	sign := ""
+	switch {
+	case strings.HasPrefix(value, "-"):
+		sign, value = "-", value[1:]
+	case strings.HasPrefix(value, "+"):
+		value = value[1:]
+	}
+
+	value = strings.TrimLeft(value, "0")
+	if value == "" {
+		return "0"
+	}
+	return sign + value
+}
+
+// normalizeFloat rewrites a YAML float literal into a valid JSON number literal
+func normalizeFloat(value string) string {
+	value = strings.TrimPrefix(value, "+")
+
+	sign := ""
+	if strings.HasPrefix(value, "-") {
+		sign, value = "-", value[1:]
+	}
+
+	mantissa, exponent := value, ""
+	if i := strings.IndexAny(value, "eE"); i >= 0 {
+		mantissa, exponent = value[:i], value[i:]
+	}
+
+	intPart, fracPart, hasDot := strings.Cut(mantissa, ".")
+	intPart = strings.TrimLeft(intPart, "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+	if hasDot && fracPart == "" {
+		fracPart = "0"
+	}
+
+	result := sign + intPart
+	if hasDot {
+		result += "." + fracPart
+	}
+	return result + exponent
+}
+
+// resolveAlias follows alias nodes to their anchored value
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for i := 0; node.Kind == yaml.AliasNode && node.Alias != nil && i < maxYAMLDepth; i++ {
+		node = node.Alias
+	}
+	return node
+}
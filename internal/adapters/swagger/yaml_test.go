@@ -0,0 +1,178 @@
+package swagger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want Format
+	}{
+		{name: "json object", data: `{"swagger": "2.0"}`, want: FormatJSON},
+		{name: "json with leading whitespace", data: "\n\t  {}", want: FormatJSON},
+		{name: "json with byte order mark", data: "\xef\xbb\xbf{}", want: FormatJSON},
+		{name: "yaml mapping", data: "openapi: 3.0.0\n", want: FormatYAML},
+		{name: "yaml document marker", data: "---\nswagger: '2.0'\n", want: FormatYAML},
+		{name: "yaml comment", data: "# spec\nopenapi: 3.1.0\n", want: FormatYAML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectFormat([]byte(tt.data)); got != tt.want {
+				t.Errorf("DetectFormat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want Format
+	}{
+		{path: "openapi.yaml", want: FormatYAML},
+		{path: "specs/API.YML", want: FormatYAML},
+		{path: "swagger.json", want: FormatJSON},
+		{path: "swagger", want: FormatUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := FormatFromPath(tt.path); got != tt.want {
+				t.Errorf("FormatFromPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestYAMLToJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{
+			name: "core schema scalars",
+			yaml: "a: true\nb: ~\nc: 42\nd: -0.50\ne: yes\nf: \"12\"\ng: 1_000\nh: 0x1F\ni: 0o17\nj: 2024-01-01\n",
+			want: `{"a":true,"b":null,"c":42,"d":-0.50,"e":"yes","f":"12","g":"1_000","h":31,"i":15,"j":"2024-01-01"}`,
+		},
+		{
+			name: "numeric keys become strings",
+			yaml: "200:\n  description: OK\n",
+			want: `{"200":{"description":"OK"}}`,
+		},
+		{
+			name: "key order is preserved",
+			yaml: "zebra: 1\napple: 2\nmango: 3\n",
+			want: `{"zebra":1,"apple":2,"mango":3}`,
+		},
+		{
+			name: "anchors and merge keys",
+			yaml: "base: &b\n  name: id\n  in: path\nparam:\n  <<: *b\n  in: query\n",
+			want: `{"base":{"name":"id","in":"path"},"param":{"in":"query","name":"id"}}`,
+		},
+		{
+			name: "explicit tags",
+			yaml: "a: !!str 123\nb: !!float 1\n",
+			want: `{"a":"123","b":1}`,
+		},
+		{
+			name: "block scalars",
+			yaml: "description: |\n  line one\n  line two\n",
+			want: `{"description":"line one\nline two\n"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := yamlToJSON([]byte(tt.yaml))
+			if err != nil {
+				t.Fatalf("yamlToJSON() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("yamlToJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_ParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		yaml       string
+		wantLine   int
+		wantColumn int
+		wantMsg    string
+	}{
+		{
+			name:     "syntax error",
+			yaml:     "swagger: \"2.0\"\npaths:\n  /pets: get: x\n",
+			wantLine: 3,
+			wantMsg:  "mapping values are not allowed",
+		},
+		{
+			name:       "duplicate key",
+			yaml:       "swagger: \"2.0\"\ninfo:\n  title: A\n  title: B\n",
+			wantLine:   4,
+			wantColumn: 3,
+			wantMsg:    `mapping key "title" already defined`,
+		},
+		{
+			name:       "type mismatch",
+			yaml:       "swagger: \"2.0\"\ninfo:\n  title: Pets\n  version: 1.0\n",
+			wantLine:   4,
+			wantColumn: 12,
+			wantMsg:    "cannot use number",
+		},
+		{
+			name:       "type mismatch in nested list",
+			yaml:       "swagger: \"2.0\"\npaths:\n  /pets:\n    get:\n      parameters:\n        - name: limit\n          required: maybe\n",
+			wantLine:   7,
+			wantColumn: 21,
+			wantMsg:    "cannot use string",
+		},
+		{
+			name:       "infinity is not valid JSON",
+			yaml:       "swagger: \"2.0\"\ndefinitions:\n  N:\n    maximum: .inf\n",
+			wantLine:   4,
+			wantColumn: 14,
+			wantMsg:    ".inf cannot be represented in JSON",
+		},
+		{
+			name:    "empty document",
+			yaml:    "# nothing here\n",
+			wantMsg: "document is empty",
+		},
+	}
+
+	parser := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.ParseFormat([]byte(tt.yaml), FormatYAML)
+			if err == nil {
+				t.Fatalf("ParseFormat() expected an error")
+			}
+
+			var yamlErr *YAMLError
+			if !errors.As(err, &yamlErr) {
+				t.Fatalf("ParseFormat() error = %v, want a *YAMLError", err)
+			}
+			if yamlErr.Line != tt.wantLine {
+				t.Errorf("Line = %d, want %d (%v)", yamlErr.Line, tt.wantLine, err)
+			}
+			if tt.wantColumn != 0 && yamlErr.Column != tt.wantColumn {
+				t.Errorf("Column = %d, want %d (%v)", yamlErr.Column, tt.wantColumn, err)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("error %q does not contain %q", err.Error(), tt.wantMsg)
+			}
+			if !strings.HasPrefix(err.Error(), "failed to parse Swagger YAML") {
+				t.Errorf("error %q should mention YAML", err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package formatter
+
+import (
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// HTTPFormatter defines the interface for writing HTTP files in the syntax of an HTTP client
+type HTTPFormatter interface {
+	// FormatHTTPFile formats an HTTP file with its variables and requests
+	FormatHTTPFile(file *models.HTTPFile) string
+
+	// FormatHTTPRequest formats a single HTTP request
+	FormatHTTPRequest(req models.HTTPRequest) string
+}
+
+// CollectionFormatter defines the interface for exporting HTTP files as the
+// collection of an API client, such as Postman
+type CollectionFormatter interface {
+	// FormatCollection formats HTTP files as a single collection named after
+	// the API. Files with a tag become folders of the collection.
+	FormatCollection(name string, files []*models.HTTPFile) ([]byte, error)
+}
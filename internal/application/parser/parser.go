@@ -17,4 +17,35 @@ type SwaggerParser interface {
 	
 	// ExtractOperations extracts all operations from the document, grouped by tag
 	ExtractOperations(doc *models.SwaggerDoc) map[string][]models.OperationInfo
+
+	// Resolver returns a RefResolver for $ref pointers in the document
+	Resolver(doc *models.SwaggerDoc) RefResolver
+}
+
+// RefResolver follows $ref pointers to the objects they reference.
+// Objects without a $ref are returned unchanged.
+type RefResolver interface {
+	// ResolveSchema resolves a schema reference
+	ResolveSchema(schema *models.SchemaObj) (*models.SchemaObj, error)
+
+	// ResolveParameter resolves a parameter reference
+	ResolveParameter(param *models.Parameter) (*models.Parameter, error)
+
+	// ResolveRequestBody resolves a request body reference
+	ResolveRequestBody(body *models.RequestBody) (*models.RequestBody, error)
+
+	// ResolveResponse resolves a response reference
+	ResolveResponse(resp *models.Response) (*models.Response, error)
+
+	// ResolveHeader resolves a header reference
+	ResolveHeader(header *models.Header) (*models.Header, error)
+
+	// ResolveExample resolves an example reference
+	ResolveExample(example *models.Example) (*models.Example, error)
+}
+
+// HTTPParser defines the interface for reading .http files back into HTTP files
+type HTTPParser interface {
+	// Parse parses the content of a .http file
+	Parse(data []byte) (*models.HTTPFile, error)
 }
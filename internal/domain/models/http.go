@@ -3,19 +3,88 @@ package models
 // HTTPRequest represents a single HTTP request in the .http file format
 type HTTPRequest struct {
 	Name        string
+	ID          string // name other requests reference the response by, e.g. createPet
 	Method      string
 	Path        string
 	Headers     map[string]string
 	Body        string
+	BodyFile    string     // file the body is read from instead of Body, e.g. ./pet.json
+	FormParts   []FormPart // parts of a multipart/form-data body, instead of Body
 	Description string
 	Vars        map[string]string
 	Tag         string
+	Captures    []ResponseCapture
+	Expect      *ResponseExpectation // documented response, asserted by response handlers
+	PreScript   string               // pre-request script statements written by hand
+	Script      string               // response handler statements written by hand, such as client.test calls
+	Assertions  []string             // response lines written by hand, such as httpyac ?? assertions
+	Auth        *HTTPAuth            // credentials also set in Headers or Path, for clients with auth settings
+	Deprecated  bool                 // the operation is deprecated or no longer in the document
+	Generated   string               // fingerprint of the body generated from the document, empty for requests added by hand
+}
+
+// FormPart is a part of a multipart/form-data request body
+type FormPart struct {
+	Name        string // name of the form field
+	Value       string // content of the part, unless it is read from File
+	File        string // file the content is read from, e.g. ./photo.png
+	FileName    string // file name sent for the part, e.g. photo.png
+	ContentType string // media type of the part, empty for plain text
+}
+
+// Authentication types of HTTPAuth
+const (
+	AuthBasic  = "basic"
+	AuthBearer = "bearer"
+	AuthAPIKey = "apikey"
+)
+
+// HTTPAuth describes the credentials a request sends, so that API clients
+// with authentication settings can use them instead of the raw header
+type HTTPAuth struct {
+	Type     string // AuthBasic, AuthBearer or AuthAPIKey
+	Username string // basic auth user name, e.g. {{basicAuthUsername}}
+	Password string // basic auth password
+	Token    string // bearer token, e.g. {{authToken}}
+	Name     string // name of the API key header or query parameter
+	Value    string // value of the API key
+	In       string // where the API key is sent: "header" or "query"
+}
+
+// ResponseExpectation describes the documented success response of a request
+type ResponseExpectation struct {
+	Status      string   // status code, e.g. "201", or a range such as "2XX"
+	ContentType string   // media type of the body, empty when there is no body
+	Fields      []string // required top-level properties of the JSON body
+}
+
+// ResponseCapture stores a value of the response body in a global variable,
+// so that other requests can use it
+type ResponseCapture struct {
+	Variable string // name of the global variable
+	Path     string // dotted path of the value in the JSON response body, e.g. "access_token"
+}
+
+// ResponseRef reads a variable from the JSON response body of a named request
+type ResponseRef struct {
+	Request string // ID of the request
+	Path    string // dotted path of the value in the JSON response body, e.g. "id"
 }
 
 // HTTPFile represents a collection of HTTP requests to be saved in a .http file
 type HTTPFile struct {
-	BaseURL    string
-	GlobalVars map[string]string
-	Requests   []HTTPRequest
-	Tag        string
+	BaseURL      string
+	GlobalVars   map[string]string
+	VarComments  map[string]string      // comments shown above global variables, such as allowed values
+	ResponseVars map[string]ResponseRef // variables read from the responses of the file's requests
+	SharedVars   map[string]string      // variables captured by requests of other files, with placeholders for clients that cannot share them
+	Requests     []HTTPRequest
+	Tag          string
+}
+
+// Environment holds the variable values of one target environment, such as a server
+type Environment struct {
+	Name    string
+	Vars    map[string]string // public values, safe to commit
+	Secrets map[string]string // credentials, kept out of version control
 }
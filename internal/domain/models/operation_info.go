@@ -1,9 +1,21 @@
 package models
 
-// OperationInfo contains information about an API operation with its path and method
+// OperationInfo contains information about an API operation with its path and method.
+// Parameters, Consumes, Produces, Security and Servers include what the operation
+// inherits from its path item and from the document.
 type OperationInfo struct {
 	Path       string
 	Method     string
 	Operation  *Operation
 	Parameters []Parameter
+	Consumes   []string
+	Produces   []string
+
+	// Security is the effective security requirement. An empty, non-nil
+	// slice means the operation opted out of the document requirement.
+	Security []map[string][]string
+
+	// Servers overrides the document servers when the path item or the
+	// operation declares its own
+	Servers []Server
 }
@@ -1,18 +1,66 @@
 package models
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
 // SwaggerDoc represents the top-level Swagger/OpenAPI document structure
 type SwaggerDoc struct {
-	Swagger     string                `json:"swagger,omitempty"`
-	OpenAPI     string                `json:"openapi,omitempty"`
-	Info        Info                  `json:"info"`
-	BasePath    string                `json:"basePath,omitempty"`
-	Host        string                `json:"host,omitempty"`
-	Schemes     []string              `json:"schemes,omitempty"`
-	Paths       map[string]PathItem   `json:"paths"`
-	Definitions map[string]SchemaObj  `json:"definitions,omitempty"`
-	Components  *Components           `json:"components,omitempty"`
-	Servers     []Server              `json:"servers,omitempty"`
-	Tags        []Tag                 `json:"tags,omitempty"`
+	Swagger     string               `json:"swagger,omitempty"`
+	OpenAPI     string               `json:"openapi,omitempty"`
+	Info        Info                 `json:"info"`
+	BasePath    string               `json:"basePath,omitempty"`
+	Host        string               `json:"host,omitempty"`
+	Schemes     []string             `json:"schemes,omitempty"`
+	Consumes    []string             `json:"consumes,omitempty"` // Swagger v2
+	Produces    []string             `json:"produces,omitempty"` // Swagger v2
+	Paths       map[string]PathItem  `json:"paths"`
+	Definitions map[string]SchemaObj `json:"definitions,omitempty"`
+	Parameters  map[string]Parameter `json:"parameters,omitempty"` // Swagger v2
+	Responses   map[string]Response  `json:"responses,omitempty"`  // Swagger v2
+	Components  *Components          `json:"components,omitempty"`
+	Servers     []Server             `json:"servers,omitempty"`
+	Tags        []Tag                `json:"tags,omitempty"`
+
+	Security            []map[string][]string     `json:"security,omitempty"`
+	SecurityDefinitions map[string]SecurityScheme `json:"securityDefinitions,omitempty"` // Swagger v2
+
+	// PathOrder lists the paths in the order they are declared in the document
+	PathOrder []string `json:"-"`
+}
+
+// UnmarshalJSON decodes the document and records the declaration order of its paths
+func (d *SwaggerDoc) UnmarshalJSON(data []byte) error {
+	type swaggerDoc SwaggerDoc
+	var doc swaggerDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	var raw struct {
+		Paths json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	order, err := objectKeys(raw.Paths)
+	if err != nil {
+		return err
+	}
+
+	*d = SwaggerDoc(doc)
+	d.PathOrder = order
+	return nil
+}
+
+// PathNames returns the paths in declaration order. Paths missing from
+// PathOrder, such as those added in code, follow in sorted order.
+func (d *SwaggerDoc) PathNames() []string {
+	return orderedKeys(d.PathOrder, d.Paths)
 }
 
 // Info contains metadata about the API
@@ -45,6 +93,16 @@ type ServerVariable struct {
 	Description string   `json:"description,omitempty"`
 }
 
+// ExpandURL returns the server URL with every {variable} replaced by its
+// default value. Unknown variables are left as they are.
+func (s Server) ExpandURL() string {
+	url := s.URL
+	for name, variable := range s.Variables {
+		url = strings.ReplaceAll(url, "{"+name+"}", variable.Default)
+	}
+	return url
+}
+
 // Tag provides metadata about the API tags
 type Tag struct {
 	Name        string `json:"name"`
@@ -53,16 +111,18 @@ type Tag struct {
 
 // Components contains the reusable components in OpenAPI v3
 type Components struct {
-	Schemas    map[string]SchemaObj    `json:"schemas,omitempty"`
-	Parameters map[string]Parameter    `json:"parameters,omitempty"`
-	Responses  map[string]Response     `json:"responses,omitempty"`
-	Examples   map[string]interface{}  `json:"examples,omitempty"`
-	Headers    map[string]Header       `json:"headers,omitempty"`
+	Schemas         map[string]SchemaObj      `json:"schemas,omitempty"`
+	Parameters      map[string]Parameter      `json:"parameters,omitempty"`
+	Responses       map[string]Response       `json:"responses,omitempty"`
+	RequestBodies   map[string]RequestBody    `json:"requestBodies,omitempty"`
+	Examples        map[string]Example        `json:"examples,omitempty"`
+	Headers         map[string]Header         `json:"headers,omitempty"`
 	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
 }
 
 // Header represents a header parameter
 type Header struct {
+	Ref         string     `json:"$ref,omitempty"`
 	Description string     `json:"description,omitempty"`
 	Required    bool       `json:"required,omitempty"`
 	Schema      *SchemaObj `json:"schema,omitempty"`
@@ -70,12 +130,37 @@ type Header struct {
 
 // SecurityScheme defines a security scheme that can be used by operations
 type SecurityScheme struct {
-	Type        string `json:"type"`                  // "apiKey", "http", "oauth2", "openIdConnect"
-	Description string `json:"description,omitempty"`
-	Name        string `json:"name,omitempty"`        // for apiKey
-	In          string `json:"in,omitempty"`          // for apiKey: "query", "header", "cookie"
-	Scheme      string `json:"scheme,omitempty"`      // for http: "basic", "bearer"
+	Type         string `json:"type"` // "apiKey", "http", "oauth2", "openIdConnect"; Swagger v2 also "basic"
+	Description  string `json:"description,omitempty"`
+	Name         string `json:"name,omitempty"`         // for apiKey
+	In           string `json:"in,omitempty"`           // for apiKey: "query", "header", "cookie"
+	Scheme       string `json:"scheme,omitempty"`       // for http: "basic", "bearer"
 	BearerFormat string `json:"bearerFormat,omitempty"` // for http: "bearer"
+
+	Flows            *OAuthFlows `json:"flows,omitempty"`            // for oauth2
+	OpenIDConnectURL string      `json:"openIdConnectUrl,omitempty"` // for openIdConnect
+
+	// Swagger v2 oauth2
+	Flow             string            `json:"flow,omitempty"` // "implicit", "password", "application", "accessCode"
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes,omitempty"`
+}
+
+// OAuthFlows lists the OAuth2 flows supported by a security scheme
+type OAuthFlows struct {
+	Implicit          *OAuthFlow `json:"implicit,omitempty"`
+	Password          *OAuthFlow `json:"password,omitempty"`
+	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+	AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+}
+
+// OAuthFlow describes the endpoints and scopes of a single OAuth2 flow
+type OAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	RefreshURL       string            `json:"refreshUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes"`
 }
 
 // PathItem describes the operations available on a single path
@@ -91,25 +176,28 @@ type PathItem struct {
 	Head        *Operation  `json:"head,omitempty"`
 	Patch       *Operation  `json:"patch,omitempty"`
 	Parameters  []Parameter `json:"parameters,omitempty"`
+	Servers     []Server    `json:"servers,omitempty"` // OpenAPI v3
 }
 
 // Operation describes a single API operation on a path
 type Operation struct {
-	Tags        []string               `json:"tags,omitempty"`
-	Summary     string                 `json:"summary,omitempty"`
-	Description string                 `json:"description,omitempty"`
-	OperationID string                 `json:"operationId,omitempty"`
-	Consumes    []string               `json:"consumes,omitempty"`
-	Produces    []string               `json:"produces,omitempty"`
-	Parameters  []Parameter            `json:"parameters,omitempty"`
-	RequestBody *RequestBody           `json:"requestBody,omitempty"`
-	Responses   map[string]Response    `json:"responses"`
-	Security    []map[string][]string  `json:"security,omitempty"`
-	Deprecated  bool                   `json:"deprecated,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId,omitempty"`
+	Consumes    []string              `json:"consumes,omitempty"`
+	Produces    []string              `json:"produces,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	Servers     []Server              `json:"servers,omitempty"` // OpenAPI v3
 }
 
 // RequestBody represents a request body in OpenAPI v3
 type RequestBody struct {
+	Ref         string                  `json:"$ref,omitempty"`
 	Description string                  `json:"description,omitempty"`
 	Content     map[string]MediaTypeObj `json:"content"`
 	Required    bool                    `json:"required,omitempty"`
@@ -117,59 +205,257 @@ type RequestBody struct {
 
 // MediaTypeObj represents a media type object in OpenAPI v3
 type MediaTypeObj struct {
-	Schema   *SchemaObj             `json:"schema,omitempty"`
-	Examples map[string]interface{} `json:"examples,omitempty"`
+	Schema   *SchemaObj          `json:"schema,omitempty"`
+	Example  interface{}         `json:"example,omitempty"`
+	Examples Examples            `json:"examples,omitempty"`
+	Encoding map[string]Encoding `json:"encoding,omitempty"` // by property of a form body
+}
+
+// Encoding describes how a property of a form body is sent in OpenAPI v3
+type Encoding struct {
+	ContentType string `json:"contentType,omitempty"` // media types of the part, e.g. "image/png, image/jpeg"
+}
+
+// Example is an example of a media type or parameter in OpenAPI v3
+type Example struct {
+	Ref           string      `json:"$ref,omitempty"`
+	Summary       string      `json:"summary,omitempty"`
+	Description   string      `json:"description,omitempty"`
+	Value         interface{} `json:"value,omitempty"`
+	ExternalValue string      `json:"externalValue,omitempty"`
+}
+
+// NamedExample is an example with its name in the examples of a media type or parameter
+type NamedExample struct {
+	Name string
+	Example
+}
+
+// Examples lists named examples in the order they are declared in the document
+type Examples []NamedExample
+
+// UnmarshalJSON decodes an object of named examples, keeping their order
+func (e *Examples) UnmarshalJSON(data []byte) error {
+	var examples map[string]Example
+	if err := json.Unmarshal(data, &examples); err != nil {
+		return err
+	}
+	order, err := objectKeys(data)
+	if err != nil {
+		return err
+	}
+
+	*e = make(Examples, 0, len(examples))
+	for _, name := range orderedKeys(order, examples) {
+		*e = append(*e, NamedExample{Name: name, Example: examples[name]})
+	}
+	return nil
+}
+
+// MarshalJSON encodes the examples as an object of named examples
+func (e Examples) MarshalJSON() ([]byte, error) {
+	examples := make(map[string]Example, len(e))
+	for _, example := range e {
+		examples[example.Name] = example.Example
+	}
+	return json.Marshal(examples)
 }
 
 // Parameter describes a single operation parameter
 type Parameter struct {
-	Name            string      `json:"name"`
-	In              string      `json:"in"` // query, header, path, cookie, body
-	Description     string      `json:"description,omitempty"`
-	Required        bool        `json:"required,omitempty"`
-	Schema          *SchemaObj  `json:"schema,omitempty"`
-	Type            string      `json:"type,omitempty"`      // string, number, integer, boolean, array, object
-	Format          string      `json:"format,omitempty"`
-	Items           *SchemaObj  `json:"items,omitempty"`     // for array type
-	Enum            []interface{} `json:"enum,omitempty"`
-	Default         interface{} `json:"default,omitempty"`
-	Example         interface{} `json:"example,omitempty"`
-	Style           string      `json:"style,omitempty"`     // OpenAPI v3
-	Explode         bool        `json:"explode,omitempty"`   // OpenAPI v3
-	AllowReserved   bool        `json:"allowReserved,omitempty"` // OpenAPI v3
+	Ref         string        `json:"$ref,omitempty"`
+	Name        string        `json:"name"`
+	In          string        `json:"in"` // query, header, path, cookie, body
+	Description string        `json:"description,omitempty"`
+	Required    bool          `json:"required,omitempty"`
+	Schema      *SchemaObj    `json:"schema,omitempty"`
+	Type        string        `json:"type,omitempty"` // string, number, integer, boolean, array, object
+	Format      string        `json:"format,omitempty"`
+	Items       *SchemaObj    `json:"items,omitempty"` // for array type
+	Enum        []interface{} `json:"enum,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Example     interface{}   `json:"example,omitempty"`
+	Examples    Examples      `json:"examples,omitempty"`  // OpenAPI v3
+	XExample    interface{}   `json:"x-example,omitempty"` // Swagger v2 extension
+
+	// Swagger v2 validation of non-body parameters, kept in the schema in OpenAPI v3
+	Maximum          *float64 `json:"maximum,omitempty"`
+	Minimum          *float64 `json:"minimum,omitempty"`
+	ExclusiveMaximum bool     `json:"exclusiveMaximum,omitempty"`
+	ExclusiveMinimum bool     `json:"exclusiveMinimum,omitempty"`
+	MaxLength        int      `json:"maxLength,omitempty"`
+	MinLength        int      `json:"minLength,omitempty"`
+	Pattern          string   `json:"pattern,omitempty"`
+	MaxItems         int      `json:"maxItems,omitempty"`
+	MinItems         int      `json:"minItems,omitempty"`
+	MultipleOf       float64  `json:"multipleOf,omitempty"`
+
+	Style         string `json:"style,omitempty"`         // OpenAPI v3
+	Explode       *bool  `json:"explode,omitempty"`       // OpenAPI v3, defaults to true for form style
+	AllowReserved bool   `json:"allowReserved,omitempty"` // OpenAPI v3
+
+	CollectionFormat string `json:"collectionFormat,omitempty"` // Swagger v2: csv, ssv, tsv, pipes, multi
 }
 
 // Response describes a single response from an API Operation
 type Response struct {
-	Description string                 `json:"description"`
-	Schema      *SchemaObj             `json:"schema,omitempty"`
-	Headers     map[string]Header      `json:"headers,omitempty"`
+	Ref         string                  `json:"$ref,omitempty"`
+	Description string                  `json:"description"`
+	Schema      *SchemaObj              `json:"schema,omitempty"`
+	Headers     map[string]Header       `json:"headers,omitempty"`
 	Content     map[string]MediaTypeObj `json:"content,omitempty"` // OpenAPI v3
 }
 
 // SchemaObj describes a schema for request/response bodies and parameters
 type SchemaObj struct {
-	Ref           string                `json:"$ref,omitempty"`
-	Type          string                `json:"type,omitempty"`
-	Format        string                `json:"format,omitempty"`
-	Title         string                `json:"title,omitempty"`
-	Description   string                `json:"description,omitempty"`
-	Default       interface{}           `json:"default,omitempty"`
-	MultipleOf    float64               `json:"multipleOf,omitempty"`
-	Maximum       float64               `json:"maximum,omitempty"`
-	Minimum       float64               `json:"minimum,omitempty"`
-	MaxLength     int                   `json:"maxLength,omitempty"`
-	MinLength     int                   `json:"minLength,omitempty"`
-	Pattern       string                `json:"pattern,omitempty"`
-	MaxItems      int                   `json:"maxItems,omitempty"`
-	MinItems      int                   `json:"minItems,omitempty"`
-	UniqueItems   bool                  `json:"uniqueItems,omitempty"`
-	MaxProperties int                   `json:"maxProperties,omitempty"`
-	MinProperties int                   `json:"minProperties,omitempty"`
-	Required      []string              `json:"required,omitempty"`
-	Enum          []interface{}         `json:"enum,omitempty"`
-	Items         *SchemaObj            `json:"items,omitempty"`
-	Properties    map[string]SchemaObj  `json:"properties,omitempty"`
-	AdditionalProperties interface{}    `json:"additionalProperties,omitempty"`
-	Example       interface{}           `json:"example,omitempty"`
+	Ref                  string               `json:"$ref,omitempty"`
+	Type                 string               `json:"type,omitempty"`
+	Format               string               `json:"format,omitempty"`
+	Title                string               `json:"title,omitempty"`
+	Description          string               `json:"description,omitempty"`
+	Default              interface{}          `json:"default,omitempty"`
+	MultipleOf           float64              `json:"multipleOf,omitempty"`
+	Maximum              *float64             `json:"maximum,omitempty"`
+	Minimum              *float64             `json:"minimum,omitempty"`
+	ExclusiveMaximum     interface{}          `json:"exclusiveMaximum,omitempty"` // bool up to OpenAPI 3.0, the bound itself in 3.1
+	ExclusiveMinimum     interface{}          `json:"exclusiveMinimum,omitempty"` // bool up to OpenAPI 3.0, the bound itself in 3.1
+	MaxLength            int                  `json:"maxLength,omitempty"`
+	MinLength            int                  `json:"minLength,omitempty"`
+	Pattern              string               `json:"pattern,omitempty"`
+	MaxItems             int                  `json:"maxItems,omitempty"`
+	MinItems             int                  `json:"minItems,omitempty"`
+	UniqueItems          bool                 `json:"uniqueItems,omitempty"`
+	MaxProperties        int                  `json:"maxProperties,omitempty"`
+	MinProperties        int                  `json:"minProperties,omitempty"`
+	Required             []string             `json:"required,omitempty"`
+	Enum                 []interface{}        `json:"enum,omitempty"`
+	Items                *SchemaObj           `json:"items,omitempty"`
+	Properties           map[string]SchemaObj `json:"properties,omitempty"`
+	AdditionalProperties interface{}          `json:"additionalProperties,omitempty"`
+	Example              interface{}          `json:"example,omitempty"`
+	AllOf                []SchemaObj          `json:"allOf,omitempty"`
+	OneOf                []SchemaObj          `json:"oneOf,omitempty"`
+	AnyOf                []SchemaObj          `json:"anyOf,omitempty"`
+	Not                  *SchemaObj           `json:"not,omitempty"`
+	Discriminator        *Discriminator       `json:"discriminator,omitempty"`
+	XML                  *XMLObj              `json:"xml,omitempty"`
+
+	// PropertyOrder lists the properties in the order they are declared in the document
+	PropertyOrder []string `json:"-"`
+}
+
+// UnmarshalJSON decodes the schema and records the declaration order of its properties
+func (s *SchemaObj) UnmarshalJSON(data []byte) error {
+	type schemaObj SchemaObj
+	var schema schemaObj
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return err
+	}
+
+	var raw struct {
+		Properties json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	order, err := objectKeys(raw.Properties)
+	if err != nil {
+		return err
+	}
+
+	*s = SchemaObj(schema)
+	s.PropertyOrder = order
+	return nil
+}
+
+// PropertyNames returns the property names in declaration order. Properties
+// missing from PropertyOrder, such as those added in code, follow in sorted order.
+func (s *SchemaObj) PropertyNames() []string {
+	return orderedKeys(s.PropertyOrder, s.Properties)
+}
+
+// XMLObj describes how a schema is written in XML
+type XMLObj struct {
+	Name      string `json:"name,omitempty"`      // element or attribute name, instead of the property name
+	Namespace string `json:"namespace,omitempty"` // namespace URI
+	Prefix    string `json:"prefix,omitempty"`    // namespace prefix
+	Attribute bool   `json:"attribute,omitempty"` // the property is an attribute of its object
+	Wrapped   bool   `json:"wrapped,omitempty"`   // the items of an array are wrapped in an element
+}
+
+// Discriminator selects the schema of a polymorphic payload from a property value
+type Discriminator struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty"` // property value -> schema name or $ref
+}
+
+// UnmarshalJSON accepts both the OpenAPI v3 object and the Swagger v2 property name string
+func (d *Discriminator) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*d = Discriminator{PropertyName: name}
+		return nil
+	}
+
+	type discriminator Discriminator
+	var obj discriminator
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*d = Discriminator(obj)
+	return nil
+}
+
+// objectKeys returns the keys of a raw JSON object in the order they appear.
+// An empty or null value has no keys.
+func objectKeys(data json.RawMessage) ([]string, error) {
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object, got %v", tok)
+	}
+
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, tok.(string))
+
+		// Skip the value
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// orderedKeys returns the keys of m listed in order first, followed by the
+// remaining keys of m in sorted order. Names in order that are not in m are dropped.
+func orderedKeys[V any](order []string, m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	seen := make(map[string]bool, len(m))
+	for _, key := range order {
+		if _, ok := m[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+
+	var rest []string
+	for key := range m {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(keys, rest...)
 }
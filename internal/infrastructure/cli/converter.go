@@ -2,35 +2,145 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
 
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/edgardnogueira/swagger-to-http-file/internal/adapters/curl"
 	"github.com/edgardnogueira/swagger-to-http-file/internal/adapters/http"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/adapters/insomnia"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/adapters/postman"
 	"github.com/edgardnogueira/swagger-to-http-file/internal/adapters/swagger"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/application/formatter"
 	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/infrastructure/remote"
 )
 
+const (
+	// stdinInput is the input file name reading the document from stdin
+	stdinInput = "-"
+	// streamSeparator precedes each file written by WriteHTTPStream
+	streamSeparator = "# ==> %s <=="
+
+	// formatHTTP writes .http files
+	formatHTTP = "http"
+	// formatCurl writes executable shell scripts running curl
+	formatCurl = "curl"
+	// formatPostman writes a Postman Collection v2.1
+	formatPostman = "postman"
+	// formatInsomnia writes an Insomnia v4 export
+	formatInsomnia = "insomnia"
+
+	// defaultCollectionName names collections of documents without a title
+	defaultCollectionName = "API"
+)
+
+// outputFormat describes the files a format writes
+type outputFormat struct {
+	formatter formatter.HTTPFormatter
+	extension string      // file extension, e.g. ".http"
+	mode      os.FileMode // permissions of the written files
+}
+
+// newOutputFormat returns the output format of a --format value, .http
+// files use the syntax of dialect
+func newOutputFormat(format, dialect string) outputFormat {
+	if format == formatCurl {
+		return outputFormat{formatter: curl.NewFormatter(), extension: ".sh", mode: 0755}
+	}
+	return httpOutput(http.NewDialectFormatter(dialect))
+}
+
+// collectionFormat describes a format exporting all files as one collection
+type collectionFormat struct {
+	formatter formatter.CollectionFormatter
+	filename  string
+}
+
+// collectionFormats are the formats writing a collection instead of one file per tag
+var collectionFormats = map[string]collectionFormat{
+	formatPostman:  {formatter: postman.NewFormatter(), filename: "swagger.postman_collection.json"},
+	formatInsomnia: {formatter: insomnia.NewFormatter(), filename: "swagger.insomnia.json"},
+}
+
+// httpOutput returns the output format of .http files
+func httpOutput(httpFormatter formatter.HTTPFormatter) outputFormat {
+	return outputFormat{formatter: httpFormatter, extension: ".http", mode: 0644}
+}
+
+// convertConfig holds the settings of a conversion
+type convertConfig struct {
+	InputFile  string // file path, HTTP(S) URL or "-" for stdin
+	OutputDir  string
+	BaseURL    string // overrides the base URL of the document
+	Server     string // index or description of the server providing the base URL
+	GroupByTag bool
+	Overwrite  bool
+	Merge      bool // merge existing .http files with the regenerated ones
+	Verbose    bool
+	EnvFile    bool
+	Options    http.Options
+	Dialect    string // HTTP client whose syntax the files use, see http.ValidDialect
+	Format     string // kind of files to write: http (the default), curl, postman or insomnia
+	Fetch      remote.Options
+
+	// Stdin is read when InputFile is "-"
+	Stdin io.Reader
+	// Stdout receives the formatted files instead of OutputDir when set
+	Stdout io.Writer
+}
+
+// logf prints a verbose message. Messages go to stderr when the files are
+// written to stdout, so that they do not mix with the output.
+func (c convertConfig) logf(format string, args ...interface{}) {
+	if !c.Verbose {
+		return
+	}
+	if c.Stdout != nil {
+		fmt.Fprintf(os.Stderr, format, args...)
+		return
+	}
+	fmt.Printf(format, args...)
+}
+
 // convertSwaggerToHTTP converts a Swagger file to HTTP files
-func convertSwaggerToHTTP(inputFile, outputDir, baseURLOverride string, groupByTag, overwrite, verbose bool) error {
+func convertSwaggerToHTTP(config convertConfig) error {
+	inputFile := config.InputFile
+
 	// Read the Swagger file
-	if verbose {
-		fmt.Printf("Reading Swagger file: %s\n", inputFile)
-	}
+	config.logf("Reading Swagger file: %s\n", inputFile)
+
+	parser := swagger.New()
 
-	swaggerData, err := os.ReadFile(inputFile)
+	var swaggerData []byte
+	var err error
+	switch {
+	case inputFile == stdinInput:
+		// External references are resolved against the working directory
+		swaggerData, err = io.ReadAll(config.Stdin)
+	case remote.IsURL(inputFile):
+		// External references are resolved against the URL of the document
+		fetcher := remote.New(config.Fetch)
+		parser.SetBaseLocation(inputFile)
+		parser.SetLoader(fetcher.Load)
+		swaggerData, err = fetcher.Fetch(inputFile)
+	default:
+		parser.SetBaseLocation(inputFile)
+		swaggerData, err = os.ReadFile(inputFile)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to read input file: %v", err)
 	}
 
 	// Parse the Swagger file
-	if verbose {
-		fmt.Println("Parsing Swagger file...")
-	}
+	config.logf("Parsing Swagger file...\n")
 
-	parser := swagger.New()
-	doc, err := parser.Parse(swaggerData)
+	// Prefer the file extension, falling back to content detection
+	doc, err := parser.ParseFormat(swaggerData, swagger.FormatFromPath(remote.PathOf(inputFile)))
 	if err != nil {
 		return fmt.Errorf("failed to parse Swagger file: %v", err)
 	}
@@ -40,122 +150,249 @@ func convertSwaggerToHTTP(inputFile, outputDir, baseURLOverride string, groupByT
 		return fmt.Errorf("invalid Swagger document: %v", err)
 	}
 
+	// The chosen server becomes the first one, which provides the base URL
+	if config.Server != "" {
+		index, err := selectServer(doc.Servers, config.Server)
+		if err != nil {
+			return err
+		}
+		servers := []models.Server{doc.Servers[index]}
+		servers = append(servers, doc.Servers[:index]...)
+		doc.Servers = append(servers, doc.Servers[index+1:]...)
+	}
+
 	// Get base URL (use override if provided)
 	baseURL := parser.GetBaseURL(doc)
-	if baseURLOverride != "" {
-		baseURL = baseURLOverride
+	if config.BaseURL != "" {
+		baseURL = config.BaseURL
 	}
 
-	if verbose {
-		fmt.Printf("Using base URL: %s\n", baseURL)
-	}
+	config.logf("Using base URL: %s\n", baseURL)
 
 	// Generate HTTP files
-	if verbose {
-		fmt.Println("Generating HTTP files...")
-	}
+	config.logf("Generating HTTP files...\n")
 
-	generator := http.New(parser)
+	generator := http.NewWithOptions(parser, config.Options)
 	HTTPFiles, err := generator.Generate(doc, baseURL)
 	if err != nil {
 		return fmt.Errorf("failed to generate HTTP files: %v", err)
 	}
 
-	// Write files to disk
-	if verbose {
-		fmt.Printf("Writing HTTP files to: %s\n", outputDir)
+	// Variable values go to the environment files instead of the .http files
+	if config.EnvFile {
+		envs := generator.ExtractEnvironments(doc)
+		if config.BaseURL != "" {
+			for _, env := range envs {
+				env.Vars["baseUrl"] = config.BaseURL
+			}
+		}
+		if err := WriteEnvFiles(envs, config.OutputDir, config.Overwrite, config.Verbose); err != nil {
+			return err
+		}
+
+		// Variables of a single file, such as chained path parameters, stay
+		for _, file := range HTTPFiles {
+			file.GlobalVars = withoutEnvVars(file.GlobalVars, envs)
+		}
 	}
 
-	formatter := http.NewFormatter()
-	return WriteHTTPFiles(HTTPFiles, outputDir, formatter, groupByTag, overwrite, verbose)
-}
+	if collection, ok := collectionFormats[config.Format]; ok {
+		name := doc.Info.Title
+		if name == "" {
+			name = defaultCollectionName
+		}
+		return writeCollection(HTTPFiles, name, collection, config)
+	}
 
-// WriteHTTPFiles writes the HTTP files to disk
-func WriteHTTPFiles(files map[string]*models.HTTPFile, outputDir string, formatter *http.Formatter, groupByTag, overwrite, verbose bool) error {
-	if groupByTag {
-		// Write each tag to a separate file
-		for tag, file := range files {
-			filename := sanitizeTag(tag) + ".http"
-			fullPath := filepath.Join(outputDir, filename)
+	output := newOutputFormat(config.Format, config.Dialect)
+	if config.Stdout != nil {
+		return writeStream(HTTPFiles, config.Stdout, output, config.GroupByTag)
+	}
 
-			// Check if file exists and overwrite flag is not set
-			if fileExists(fullPath) && !overwrite {
-				if verbose {
-					fmt.Printf("Skipping existing file: %s\n", fullPath)
-				}
-				continue
-			}
+	// Write files to disk
+	config.logf("Writing HTTP files to: %s\n", config.OutputDir)
 
-			// Format the file content
-			content := formatter.FormatHTTPFile(file)
+	return writeFiles(HTTPFiles, config.OutputDir, output, config.GroupByTag, config.Overwrite, config.Merge, config.Verbose)
+}
 
-			// Write the file
-			if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
-				return fmt.Errorf("failed to write file %s: %v", fullPath, err)
-			}
+// WriteHTTPFiles writes the HTTP files to disk
+func WriteHTTPFiles(files map[string]*models.HTTPFile, outputDir string, httpFormatter formatter.HTTPFormatter, groupByTag, overwrite, verbose bool) error {
+	return writeFiles(files, outputDir, httpOutput(httpFormatter), groupByTag, overwrite, false, verbose)
+}
 
-			if verbose {
-				fmt.Printf("Created HTTP file: %s with %d requests\n", fullPath, len(file.Requests))
-			}
-		}
-	} else {
+// writeFiles writes the HTTP files to disk in an output format. With merge,
+// existing .http files are merged with the regenerated ones instead of being
+// skipped, see http.MergeFiles.
+func writeFiles(files map[string]*models.HTTPFile, outputDir string, output outputFormat, groupByTag, overwrite, merge, verbose bool) error {
+	if !groupByTag {
 		// Write all requests to a single file
-		combinedFile := &models.HTTPFile{
-			BaseURL:    "",
-			GlobalVars: extractGlobalVars(files),
-			Requests:   []models.HTTPRequest{},
-		}
-
-		// Collect all requests
-		for _, file := range files {
-			combinedFile.Requests = append(combinedFile.Requests, file.Requests...)
-		}
+		fullPath := filepath.Join(outputDir, "swagger"+output.extension)
+		return writeOutputFile(fullPath, http.CombineFiles(files), output, overwrite, merge, verbose)
+	}
 
-		// Set the base URL from the first file (if any)
-		for _, file := range files {
-			combinedFile.BaseURL = file.BaseURL
-			break
+	// Write each tag to a separate file
+	for _, tag := range sortedTags(files) {
+		fullPath := filepath.Join(outputDir, sanitizeTag(tag)+output.extension)
+		if err := writeOutputFile(fullPath, files[tag], output, overwrite, merge, verbose); err != nil {
+			return err
 		}
+	}
 
-		filename := "swagger.http"
-		fullPath := filepath.Join(outputDir, filename)
+	return nil
+}
 
-		// Check if file exists and overwrite flag is not set
-		if fileExists(fullPath) && !overwrite {
+// writeOutputFile writes a file to disk in an output format
+func writeOutputFile(fullPath string, file *models.HTTPFile, output outputFormat, overwrite, merge, verbose bool) error {
+	action := "Created"
+	if fileExists(fullPath) {
+		switch {
+		case merge:
+			existing, err := http.NewParser().ParseFile(fullPath)
+			if err != nil {
+				return fmt.Errorf("failed to merge file %s: %v", fullPath, err)
+			}
+			file = http.MergeFiles(existing, file)
+			action = "Merged"
+		case !overwrite:
+			// Check if file exists and overwrite flag is not set
 			if verbose {
 				fmt.Printf("Skipping existing file: %s\n", fullPath)
 			}
 			return nil
 		}
+	}
 
-		// Format the file content
-		content := formatter.FormatHTTPFile(combinedFile)
+	// Format the file content
+	content := output.formatter.FormatHTTPFile(file)
 
-		// Write the file
-		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
-			return fmt.Errorf("failed to write file %s: %v", fullPath, err)
+	// Write the file
+	if err := writeFile(fullPath, content, output.mode); err != nil {
+		return fmt.Errorf("failed to write file %s: %v", fullPath, err)
+	}
+
+	if verbose {
+		fmt.Printf("%s HTTP file: %s with %d requests\n", action, fullPath, len(file.Requests))
+	}
+
+	return nil
+}
+
+// WriteHTTPStream writes the formatted HTTP files to a stream. With groupByTag
+// each file is preceded by a "# ==> name.http <==" separator line, so that the
+// stream can be split back into the files WriteHTTPFiles would write.
+func WriteHTTPStream(files map[string]*models.HTTPFile, w io.Writer, httpFormatter formatter.HTTPFormatter, groupByTag bool) error {
+	return writeStream(files, w, httpOutput(httpFormatter), groupByTag)
+}
+
+// writeStream writes the HTTP files to a stream in an output format
+func writeStream(files map[string]*models.HTTPFile, w io.Writer, output outputFormat, groupByTag bool) error {
+	if !groupByTag {
+		_, err := io.WriteString(w, output.formatter.FormatHTTPFile(http.CombineFiles(files)))
+		return err
+	}
+
+	for i, tag := range sortedTags(files) {
+		separator := fmt.Sprintf(streamSeparator+"\n\n", sanitizeTag(tag)+output.extension)
+		if i > 0 {
+			separator = "\n" + separator
+		}
+		if _, err := io.WriteString(w, separator+output.formatter.FormatHTTPFile(files[tag])); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		if verbose {
-			fmt.Printf("Created HTTP file: %s with %d requests\n", fullPath, len(combinedFile.Requests))
+// writeCollection writes the HTTP files as a single collection, with a folder
+// per tag when grouping by tag
+func writeCollection(files map[string]*models.HTTPFile, name string, collection collectionFormat, config convertConfig) error {
+	var collected []*models.HTTPFile
+	if config.GroupByTag {
+		for _, tag := range sortedTags(files) {
+			collected = append(collected, files[tag])
 		}
+	} else {
+		collected = []*models.HTTPFile{http.CombineFiles(files)}
+	}
+
+	content, err := collection.formatter.FormatCollection(name, collected)
+	if err != nil {
+		return err
 	}
 
+	if config.Stdout != nil {
+		_, err := config.Stdout.Write(content)
+		return err
+	}
+
+	fullPath := filepath.Join(config.OutputDir, collection.filename)
+	if fileExists(fullPath) && !config.Overwrite {
+		config.logf("Skipping existing file: %s\n", fullPath)
+		return nil
+	}
+	if err := os.WriteFile(fullPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %v", fullPath, err)
+	}
+	config.logf("Created collection: %s with %d folders\n", fullPath, len(collected))
 	return nil
 }
 
-// extractGlobalVars extracts global variables from all files
-func extractGlobalVars(files map[string]*models.HTTPFile) map[string]string {
-	vars := make(map[string]string)
+// writeFile writes a file with the given permissions, also when it exists
+// with other permissions
+func writeFile(path, content string, mode os.FileMode) error {
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		return err
+	}
+	return os.Chmod(path, mode)
+}
+
+// withoutEnvVars returns the variables that are not set by the environments
+func withoutEnvVars(vars map[string]string, envs []models.Environment) map[string]string {
+	remaining := make(map[string]string)
+	for name, value := range vars {
+		remaining[name] = value
+	}
+	for _, env := range envs {
+		for name := range env.Vars {
+			delete(remaining, name)
+		}
+		for name := range env.Secrets {
+			delete(remaining, name)
+		}
+	}
+	return remaining
+}
 
-	// Collect all global variables
-	for _, file := range files {
-		for k, v := range file.GlobalVars {
-			vars[k] = v
+// selectServer finds a server by its index in the document, starting at 0, or
+// by its description, compared case-insensitively
+func selectServer(servers []models.Server, selector string) (int, error) {
+	if len(servers) == 0 {
+		return 0, fmt.Errorf("cannot select server %q: the document declares no servers", selector)
+	}
+
+	if index, err := strconv.Atoi(selector); err == nil {
+		if index < 0 || index >= len(servers) {
+			return 0, fmt.Errorf("server index %d out of range: the document declares %d servers", index, len(servers))
+		}
+		return index, nil
+	}
+
+	for i, server := range servers {
+		if strings.EqualFold(server.Description, selector) {
+			return i, nil
 		}
 	}
+	return 0, fmt.Errorf("no server described as %q", selector)
+}
 
-	return vars
+// sortedTags returns the tags of the files in sorted order
+func sortedTags(files map[string]*models.HTTPFile) []string {
+	tags := make([]string, 0, len(files))
+	for tag := range files {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
 }
 
 // sanitizeTag makes a tag suitable for use as a filename
@@ -47,40 +47,6 @@ func TestSanitizeTag(t *testing.T) {
 	}
 }
 
-func TestExtractGlobalVars(t *testing.T) {
-	// Create test files
-	files := map[string]*models.HTTPFile{
-		"tag1": {
-			GlobalVars: map[string]string{
-				"baseUrl": "http://api1.example.com",
-				"apiKey":  "key1",
-			},
-		},
-		"tag2": {
-			GlobalVars: map[string]string{
-				"baseUrl":  "http://api2.example.com",
-				"username": "user1",
-			},
-		},
-	}
-
-	// Extract global vars
-	vars := extractGlobalVars(files)
-
-	// Check results
-	expectedKeys := []string{"baseUrl", "apiKey", "username"}
-	for _, key := range expectedKeys {
-		if _, exists := vars[key]; !exists {
-			t.Errorf("Expected global var '%s' not found", key)
-		}
-	}
-
-	// Check specific values (last one wins for duplicates)
-	if vars["baseUrl"] != "http://api2.example.com" {
-		t.Errorf("Expected baseUrl to be http://api2.example.com, got %s", vars["baseUrl"])
-	}
-}
-
 func TestWriteHTTPFiles(t *testing.T) {
 	// Create temp directory
 	tempDir, err := os.MkdirTemp("", "swagger-to-http-test")
@@ -231,4 +197,69 @@ func TestWriteHTTPFiles(t *testing.T) {
 			t.Errorf("Expected file content to be updated")
 		}
 	})
+
+	// Test curl scripts
+	t.Run("curl scripts", func(t *testing.T) {
+		dir := filepath.Join(tempDir, "curl")
+		err := os.MkdirAll(dir, 0755)
+		if err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+
+		// Existing scripts are made executable when overwritten
+		path := filepath.Join(dir, "pets.sh")
+		err = os.WriteFile(path, []byte("existing content"), 0644)
+		if err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		err = writeFiles(HTTPFiles, dir, newOutputFormat(formatCurl, ""), true, true, false, false)
+		if err != nil {
+			t.Fatalf("writeFiles failed: %v", err)
+		}
+
+		for _, file := range []string{"pets.sh", "users.sh"} {
+			info, err := os.Stat(filepath.Join(dir, file))
+			if err != nil {
+				t.Errorf("Expected file %s to be created: %v", file, err)
+				continue
+			}
+			if info.Mode().Perm() != 0755 {
+				t.Errorf("Expected file %s to be executable, got mode %v", file, info.Mode().Perm())
+			}
+		}
+	})
+}
+
+func TestSelectServer(t *testing.T) {
+	servers := []models.Server{
+		{URL: "https://api.example.com", Description: "Production"},
+		{URL: "https://sandbox.example.com", Description: "Sandbox"},
+	}
+
+	tests := []struct {
+		name     string
+		servers  []models.Server
+		selector string
+		expected int
+		wantErr  bool
+	}{
+		{name: "by index", servers: servers, selector: "1", expected: 1},
+		{name: "by description", servers: servers, selector: "production", expected: 0},
+		{name: "index out of range", servers: servers, selector: "2", wantErr: true},
+		{name: "unknown description", servers: servers, selector: "staging", wantErr: true},
+		{name: "no servers", servers: nil, selector: "0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, err := selectServer(tt.servers, tt.selector)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectServer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && index != tt.expected {
+				t.Errorf("Expected index %d, got %d", tt.expected, index)
+			}
+		})
+	}
 }
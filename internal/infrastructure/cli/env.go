@@ -0,0 +1,261 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+const (
+	// jetBrainsEnvFile holds the public variables of the JetBrains HTTP Client
+	jetBrainsEnvFile = "http-client.env.json"
+	// jetBrainsPrivateEnvFile holds the secrets of the JetBrains HTTP Client
+	jetBrainsPrivateEnvFile = "http-client.private.env.json"
+	// vscodeSettingsFile holds the REST Client environments of VS Code
+	vscodeSettingsFile = ".vscode/settings.json"
+	// restClientEnvKey is the VS Code setting listing REST Client environments
+	restClientEnvKey = "rest-client.environmentVariables"
+)
+
+// envValues maps environment names to their variables. Values are decoded
+// as they are written, since env files also hold numbers, booleans and
+// objects, such as the "Security" settings of the JetBrains HTTP Client.
+type envValues map[string]map[string]interface{}
+
+// WriteEnvFiles writes the environments to the JetBrains HTTP Client env files
+// and to the REST Client environments of .vscode/settings.json. Existing files
+// are merged: values already set are kept unless overwrite is set, so edited
+// secrets survive a regeneration.
+func WriteEnvFiles(envs []models.Environment, outputDir string, overwrite, verbose bool) error {
+	public := make(envValues)
+	private := make(envValues)
+	combined := make(envValues)
+
+	for _, env := range envs {
+		public[env.Name] = make(map[string]interface{}, len(env.Vars))
+		private[env.Name] = make(map[string]interface{}, len(env.Secrets))
+		// REST Client has no private file, it gets all variables
+		combined[env.Name] = make(map[string]interface{}, len(env.Vars)+len(env.Secrets))
+
+		for k, v := range env.Vars {
+			public[env.Name][k] = v
+			combined[env.Name][k] = v
+		}
+		for k, v := range env.Secrets {
+			private[env.Name][k] = v
+			combined[env.Name][k] = v
+		}
+	}
+
+	if err := writeEnvFile(filepath.Join(outputDir, jetBrainsEnvFile), public, overwrite, verbose); err != nil {
+		return err
+	}
+	if err := writeEnvFile(filepath.Join(outputDir, jetBrainsPrivateEnvFile), private, overwrite, verbose); err != nil {
+		return err
+	}
+	return writeVSCodeSettings(filepath.Join(outputDir, vscodeSettingsFile), combined, overwrite, verbose)
+}
+
+// writeEnvFile writes a JetBrains HTTP Client env file, merging it with an existing one
+func writeEnvFile(path string, values envValues, overwrite, verbose bool) error {
+	if fileExists(path) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %v", path, err)
+		}
+		var existing envValues
+		if err := decodeJSON(data, &existing); err != nil {
+			return fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+		values = mergeEnvValues(existing, values, overwrite)
+	}
+
+	return writeJSONFile(path, values, verbose)
+}
+
+// writeVSCodeSettings sets the REST Client environments in a VS Code settings
+// file, keeping the other settings it contains. Comments and trailing commas,
+// which VS Code allows in settings, are dropped.
+func writeVSCodeSettings(path string, values envValues, overwrite, verbose bool) error {
+	settings := make(map[string]interface{})
+
+	if fileExists(path) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %v", path, err)
+		}
+		if err := decodeJSON(data, &settings); err != nil {
+			return fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+
+		if raw, ok := settings[restClientEnvKey]; ok {
+			data, err := json.Marshal(raw)
+			if err != nil {
+				return fmt.Errorf("failed to read %s from %s: %v", restClientEnvKey, path, err)
+			}
+			var existing envValues
+			if err := decodeJSON(data, &existing); err != nil {
+				return fmt.Errorf("failed to read %s from %s: %v", restClientEnvKey, path, err)
+			}
+			values = mergeEnvValues(existing, values, overwrite)
+		}
+	}
+
+	// REST Client expects the shared environment to be present
+	if _, ok := values["$shared"]; !ok {
+		values["$shared"] = map[string]interface{}{}
+	}
+	settings[restClientEnvKey] = values
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %v", filepath.Dir(path), err)
+	}
+	return writeJSONFile(path, settings, verbose)
+}
+
+// mergeEnvValues adds the generated values to the existing ones. Existing
+// environments and variables that are no longer generated are kept.
+func mergeEnvValues(existing, generated envValues, overwrite bool) envValues {
+	merged := make(envValues, len(existing)+len(generated))
+	for name, vars := range existing {
+		merged[name] = make(map[string]interface{}, len(vars))
+		for k, v := range vars {
+			merged[name][k] = v
+		}
+	}
+
+	for name, vars := range generated {
+		if merged[name] == nil {
+			merged[name] = make(map[string]interface{}, len(vars))
+		}
+		for k, v := range vars {
+			if _, exists := merged[name][k]; !exists || overwrite {
+				merged[name][k] = v
+			}
+		}
+	}
+	return merged
+}
+
+// writeJSONFile writes a value as indented JSON
+func writeJSONFile(path string, value interface{}, verbose bool) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode file %s: %v", path, err)
+	}
+	data = append(data, '\n')
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %v", path, err)
+	}
+
+	if verbose {
+		fmt.Printf("Created environment file: %s\n", path)
+	}
+	return nil
+}
+
+// decodeJSON decodes JSON with comments and trailing commas (JSONC), keeping
+// numbers as they are written
+func decodeJSON(data []byte, value interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(stripJSONC(data)))
+	decoder.UseNumber()
+	if err := decoder.Decode(value); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return fmt.Errorf("unexpected data after the JSON value")
+	}
+	return nil
+}
+
+// stripJSONC removes the // and /* */ comments and the trailing commas of
+// JSONC, leaving strings untouched
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == '"':
+			// Copy the string up to its closing quote
+			start := i
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(data) {
+				i = len(data) - 1
+			}
+			out = append(out, data[start:i+1]...)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			i--
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		case c == ',':
+			// Trailing commas are followed by the end of an object or array,
+			// after spaces and comments
+			rest := stripJSONC(data[i+1:])
+			if next := bytes.TrimLeft(rest, " \t\r\n"); len(next) == 0 || (next[0] != '}' && next[0] != ']') {
+				out = append(out, c)
+			}
+			return append(out, rest...)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// readEnvironment reads the variables of an environment from the JetBrains
+// HTTP Client env files of a directory, secrets overriding public values.
+// Numbers and booleans are read as they are written; objects, such as the
+// "Security" settings, are not variables and are skipped.
+func readEnvironment(dir, name string) (map[string]string, error) {
+	vars := make(map[string]string)
+	found := false
+
+	for _, file := range []string{jetBrainsEnvFile, jetBrainsPrivateEnvFile} {
+		path := filepath.Join(dir, file)
+		if !fileExists(path) {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %v", path, err)
+		}
+		var values envValues
+		if err := decodeJSON(data, &values); err != nil {
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+
+		env, ok := values[name]
+		if !ok {
+			continue
+		}
+		found = true
+		for k, v := range env {
+			switch v := v.(type) {
+			case string:
+				vars[k] = v
+			case json.Number, bool:
+				vars[k] = fmt.Sprint(v)
+			}
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("environment %q not found in %s", name, filepath.Join(dir, jetBrainsEnvFile))
+	}
+	return vars, nil
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+func TestWriteEnvFiles(t *testing.T) {
+	envs := []models.Environment{
+		{
+			Name:    "production",
+			Vars:    map[string]string{"baseUrl": "https://api.example.com"},
+			Secrets: map[string]string{"apiKey": "your_api_key"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		overwrite bool
+		existing  map[string]string
+		expected  map[string]interface{}
+	}{
+		{
+			name: "new files",
+			expected: map[string]interface{}{
+				jetBrainsEnvFile:        envValues{"production": {"baseUrl": "https://api.example.com"}},
+				jetBrainsPrivateEnvFile: envValues{"production": {"apiKey": "your_api_key"}},
+				vscodeSettingsFile: map[string]interface{}{
+					restClientEnvKey: envValues{
+						"$shared":    {},
+						"production": {"baseUrl": "https://api.example.com", "apiKey": "your_api_key"},
+					},
+				},
+			},
+		},
+		{
+			name: "existing values are kept",
+			existing: map[string]string{
+				jetBrainsPrivateEnvFile: `{"production": {"apiKey": "secret"}, "local": {"apiKey": "dev"}}`,
+				vscodeSettingsFile:      `{"editor.tabSize": 2, "rest-client.environmentVariables": {"production": {"apiKey": "secret"}}}`,
+			},
+			expected: map[string]interface{}{
+				jetBrainsPrivateEnvFile: envValues{
+					"production": {"apiKey": "secret"},
+					"local":      {"apiKey": "dev"},
+				},
+				vscodeSettingsFile: map[string]interface{}{
+					"editor.tabSize": float64(2),
+					restClientEnvKey: envValues{
+						"$shared":    {},
+						"production": {"baseUrl": "https://api.example.com", "apiKey": "secret"},
+					},
+				},
+			},
+		},
+		{
+			name: "settings with comments and trailing commas",
+			existing: map[string]string{
+				vscodeSettingsFile: `{
+  // Editor
+  "editor.tabSize": 2,
+  /* REST Client */
+  "rest-client.environmentVariables": {
+    "production": {"apiKey": "secret", "docs": "https://example.com/a//b",}, // edited
+  },
+}`,
+			},
+			expected: map[string]interface{}{
+				vscodeSettingsFile: map[string]interface{}{
+					"editor.tabSize": float64(2),
+					restClientEnvKey: envValues{
+						"$shared":    {},
+						"production": {"baseUrl": "https://api.example.com", "apiKey": "secret", "docs": "https://example.com/a//b"},
+					},
+				},
+			},
+		},
+		{
+			name: "values other than strings are kept",
+			existing: map[string]string{
+				jetBrainsEnvFile: `{"production": {"port": 8080, "debug": true, "Security": {"Auth": {"oauth": {"Type": "OAuth2"}}}}}`,
+			},
+			expected: map[string]interface{}{
+				jetBrainsEnvFile: envValues{"production": {
+					"baseUrl":  "https://api.example.com",
+					"port":     8080,
+					"debug":    true,
+					"Security": map[string]interface{}{"Auth": map[string]interface{}{"oauth": map[string]interface{}{"Type": "OAuth2"}}},
+				}},
+			},
+		},
+		{
+			name:      "overwrite replaces values",
+			overwrite: true,
+			existing: map[string]string{
+				jetBrainsPrivateEnvFile: `{"production": {"apiKey": "secret"}}`,
+			},
+			expected: map[string]interface{}{
+				jetBrainsPrivateEnvFile: envValues{"production": {"apiKey": "your_api_key"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.existing {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("Failed to create directory: %v", err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("Failed to write file: %v", err)
+				}
+			}
+
+			if err := WriteEnvFiles(envs, dir, tt.overwrite, false); err != nil {
+				t.Fatalf("WriteEnvFiles failed: %v", err)
+			}
+
+			for name, expected := range tt.expected {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatalf("Failed to read %s: %v", name, err)
+				}
+
+				// Decode into the type of the expectation, then compare as JSON
+				got := reflect.New(reflect.TypeOf(expected)).Interface()
+				if err := json.Unmarshal(data, got); err != nil {
+					t.Fatalf("Failed to parse %s: %v", name, err)
+				}
+				gotJSON, _ := json.Marshal(got)
+				wantJSON, _ := json.Marshal(expected)
+				if string(gotJSON) != string(wantJSON) {
+					t.Errorf("Unexpected %s:\n%s\nwant:\n%s", name, gotJSON, wantJSON)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteEnvFiles_InvalidExisting(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, jetBrainsEnvFile), []byte("not json"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if err := WriteEnvFiles(nil, dir, false, false); err == nil {
+		t.Errorf("Expected an error for an unreadable env file")
+	}
+}
+
+func TestReadEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		jetBrainsEnvFile: `{
+  "production": {
+    "baseUrl": "https://api.example.com",
+    "port": 8080,
+    "debug": true,
+    "Security": {"Auth": {"oauth": {"Type": "OAuth2"}}},
+  },
+}`,
+		jetBrainsPrivateEnvFile: `{"production": {"apiKey": "secret"}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	vars, err := readEnvironment(dir, "production")
+	if err != nil {
+		t.Fatalf("readEnvironment failed: %v", err)
+	}
+
+	// Numbers and booleans are variables, the Security settings are not
+	expected := map[string]string{"baseUrl": "https://api.example.com", "port": "8080", "debug": "true", "apiKey": "secret"}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Errorf("Expected %v, got %v", expected, vars)
+	}
+}
@@ -0,0 +1,656 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/adapters/http"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/infrastructure/remote"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+var update = flag.Bool("update", false, "update the golden files in test/golden")
+
+// TestConvertGolden converts the sample documents and compares the generated
+// files byte for byte with the golden files. Run with -update to regenerate them.
+func TestConvertGolden(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		golden     string
+		server     string
+		groupByTag bool
+		envFile    bool
+		dialect    string
+		format     string
+		options    http.Options
+	}{
+		{
+			name:       "petstore json",
+			input:      "petstore.json",
+			golden:     "petstore",
+			groupByTag: true,
+		},
+		{
+			name:       "petstore yaml matches json",
+			input:      "petstore.yaml",
+			golden:     "petstore",
+			groupByTag: true,
+		},
+		{
+			name:       "petstore single file sorted by path",
+			input:      "petstore.json",
+			golden:     "petstore-sorted",
+			groupByTag: false,
+			options:    http.Options{SortBy: http.SortPath},
+		},
+		{
+			name:       "external references",
+			input:      "refs/openapi.yaml",
+			golden:     "refs",
+			groupByTag: true,
+		},
+		{
+			name:       "polymorphic bodies",
+			input:      "polymorphic.yaml",
+			golden:     "polymorphic",
+			groupByTag: true,
+			options:    http.Options{BranchVariants: true},
+		},
+		{
+			name:       "shared parameters",
+			input:      "shared-params.json",
+			golden:     "shared-params",
+			groupByTag: true,
+		},
+		{
+			name:       "security schemes",
+			input:      "security.yaml",
+			golden:     "security",
+			groupByTag: true,
+		},
+		{
+			name:       "server variables and overrides",
+			input:      "servers.yaml",
+			golden:     "servers",
+			groupByTag: false,
+		},
+		{
+			name:       "server chosen by description",
+			input:      "servers.yaml",
+			golden:     "servers-sandbox",
+			server:     "sandbox",
+			groupByTag: false,
+			envFile:    true,
+		},
+		{
+			name:       "operations with several tags in a single file",
+			input:      "multi-tag.yaml",
+			golden:     "multi-tag",
+			groupByTag: false,
+		},
+		{
+			name:       "request chaining",
+			input:      "chaining.yaml",
+			golden:     "chaining",
+			groupByTag: true,
+		},
+		{
+			name:       "jetbrains assertions",
+			input:      "petstore.json",
+			golden:     "assertions-jetbrains",
+			groupByTag: true,
+			dialect:    http.DialectJetBrains,
+			options:    http.Options{Assertions: true},
+		},
+		{
+			name:       "httpyac assertions",
+			input:      "chaining.yaml",
+			golden:     "assertions-httpyac",
+			groupByTag: true,
+			dialect:    http.DialectHTTPYac,
+			options:    http.Options{Assertions: true},
+		},
+		{
+			name:       "rest client dialect",
+			input:      "chaining.yaml",
+			golden:     "dialect-rest-client",
+			groupByTag: true,
+			dialect:    http.DialectRESTClient,
+		},
+		{
+			name:       "rest client dialect with token requests",
+			input:      "security.yaml",
+			golden:     "dialect-rest-client-auth",
+			groupByTag: true,
+			dialect:    http.DialectRESTClient,
+		},
+		{
+			name:       "kulala dialect",
+			input:      "chaining.yaml",
+			golden:     "dialect-kulala",
+			groupByTag: true,
+			dialect:    http.DialectKulala,
+			options:    http.Options{Assertions: true},
+		},
+		{
+			name:       "curl scripts",
+			input:      "chaining.yaml",
+			golden:     "curl",
+			groupByTag: true,
+			format:     formatCurl,
+		},
+		{
+			name:       "curl scripts with token requests",
+			input:      "security.yaml",
+			golden:     "curl-auth",
+			groupByTag: true,
+			format:     formatCurl,
+		},
+		{
+			name:       "postman collection",
+			input:      "chaining.yaml",
+			golden:     "postman",
+			groupByTag: true,
+			format:     formatPostman,
+		},
+		{
+			name:       "postman collection with authentication",
+			input:      "security.yaml",
+			golden:     "postman-auth",
+			groupByTag: false,
+			format:     formatPostman,
+		},
+		{
+			name:       "insomnia export",
+			input:      "chaining.yaml",
+			golden:     "insomnia",
+			groupByTag: true,
+			format:     formatInsomnia,
+		},
+		{
+			name:       "insomnia export with authentication",
+			input:      "security.yaml",
+			golden:     "insomnia-auth",
+			groupByTag: true,
+			format:     formatInsomnia,
+		},
+		{
+			name:       "constrained values",
+			input:      "fake-data.yaml",
+			golden:     "constrained",
+			groupByTag: true,
+		},
+		{
+			name:       "seeded fake data",
+			input:      "fake-data.yaml",
+			golden:     "fake-data",
+			groupByTag: true,
+			options:    http.Options{FakeData: true, Seed: 42},
+		},
+		{
+			name:       "seeded fake data across tags",
+			input:      "chaining.yaml",
+			golden:     "fake-data-tags",
+			groupByTag: true,
+			options:    http.Options{FakeData: true, Seed: 42},
+		},
+		{
+			name:       "document examples",
+			input:      "examples.yaml",
+			golden:     "examples",
+			groupByTag: true,
+		},
+		{
+			name:       "named example variants",
+			input:      "examples.yaml",
+			golden:     "example-variants",
+			groupByTag: true,
+			options:    http.Options{ExampleVariants: true},
+		},
+		{
+			name:       "form, multipart and non-JSON bodies",
+			input:      "bodies.yaml",
+			golden:     "bodies",
+			groupByTag: true,
+		},
+		{
+			name:       "swagger 2 forms",
+			input:      "forms.json",
+			golden:     "forms",
+			groupByTag: true,
+		},
+		{
+			name:       "environment files",
+			input:      "security.yaml",
+			golden:     "security-env",
+			groupByTag: true,
+			envFile:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := filepath.Join("..", "..", "..", "test", "samples", tt.input)
+			goldenDir := filepath.Join("..", "..", "..", "test", "golden", tt.golden)
+
+			// Convert several times, the output must not change between runs
+			var previous map[string]string
+			for i := 0; i < 3; i++ {
+				outputDir := t.TempDir()
+				config := convertConfig{
+					InputFile:  input,
+					OutputDir:  outputDir,
+					Server:     tt.server,
+					GroupByTag: tt.groupByTag,
+					Overwrite:  true,
+					EnvFile:    tt.envFile,
+					Options:    tt.options,
+					Dialect:    tt.dialect,
+					Format:     tt.format,
+				}
+				if err := convertSwaggerToHTTP(config); err != nil {
+					t.Fatalf("convertSwaggerToHTTP failed: %v", err)
+				}
+
+				generated := readDir(t, outputDir)
+				if previous != nil && !equalFiles(previous, generated) {
+					t.Fatalf("Output changed between runs")
+				}
+				previous = generated
+			}
+
+			if *update {
+				if err := os.RemoveAll(goldenDir); err != nil {
+					t.Fatalf("Failed to clear golden directory: %v", err)
+				}
+				if err := os.MkdirAll(goldenDir, 0755); err != nil {
+					t.Fatalf("Failed to create golden directory: %v", err)
+				}
+				for name, content := range previous {
+					path := filepath.Join(goldenDir, name)
+					if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+						t.Fatalf("Failed to create golden directory: %v", err)
+					}
+					if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+						t.Fatalf("Failed to write golden file: %v", err)
+					}
+				}
+				return
+			}
+
+			golden := readDir(t, goldenDir)
+			for name, want := range golden {
+				got, ok := previous[name]
+				if !ok {
+					t.Errorf("Expected file %s was not generated", name)
+					continue
+				}
+				if got != want {
+					t.Errorf("File %s does not match the golden file:\n%s\nwant:\n%s", name, got, want)
+				}
+			}
+			for name := range previous {
+				if _, ok := golden[name]; !ok {
+					t.Errorf("Unexpected file %s was generated", name)
+				}
+			}
+		})
+	}
+}
+
+// readDir reads every file below a directory, keyed by slash-separated relative path
+func readDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(name)] = string(content)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to read directory %s: %v", dir, err)
+	}
+	return files
+}
+
+// equalFiles reports whether two sets of files have the same names and contents
+func equalFiles(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, content := range a {
+		if b[name] != content {
+			return false
+		}
+	}
+	return true
+}
+
+// TestConvertRemoteInput fetches a document with external references from a
+// server and expects the same files as the local conversion
+func TestConvertRemoteInput(t *testing.T) {
+	samples := filepath.Join("..", "..", "..", "test", "samples", "refs")
+	files := nethttp.FileServer(nethttp.Dir(samples))
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(nethttp.StatusUnauthorized)
+			return
+		}
+		files.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	outputDir := t.TempDir()
+	config := convertConfig{
+		InputFile:  server.URL + "/openapi.yaml",
+		OutputDir:  outputDir,
+		GroupByTag: true,
+		Fetch: remote.Options{
+			Headers:  nethttp.Header{"Authorization": {"Bearer secret"}},
+			CacheDir: t.TempDir(),
+		},
+	}
+	if err := convertSwaggerToHTTP(config); err != nil {
+		t.Fatalf("convertSwaggerToHTTP failed: %v", err)
+	}
+
+	golden := readDir(t, filepath.Join("..", "..", "..", "test", "golden", "refs"))
+	if generated := readDir(t, outputDir); !equalFiles(golden, generated) {
+		t.Errorf("Remote conversion does not match the golden files:\n%v", generated)
+	}
+
+	// Without credentials the document cannot be fetched
+	config.Fetch.Headers = nil
+	if err := convertSwaggerToHTTP(config); err == nil {
+		t.Error("Expected an error without credentials")
+	}
+}
+
+// TestConvertStdio reads documents from stdin and expects the golden files on
+// stdout, separated by file name when grouped by tag
+func TestConvertStdio(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		golden     string
+		groupByTag bool
+		options    http.Options
+	}{
+		{
+			name:       "single file",
+			input:      "petstore.json",
+			golden:     "petstore-sorted",
+			groupByTag: false,
+			options:    http.Options{SortBy: http.SortPath},
+		},
+		{
+			name:       "grouped by tag",
+			input:      "security.yaml",
+			golden:     "security",
+			groupByTag: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := os.ReadFile(filepath.Join("..", "..", "..", "test", "samples", tt.input))
+			if err != nil {
+				t.Fatalf("Failed to read sample: %v", err)
+			}
+
+			// Nothing may be written to the output directory
+			outputDir := t.TempDir()
+			var stdout bytes.Buffer
+			config := convertConfig{
+				InputFile:  stdinInput,
+				OutputDir:  outputDir,
+				GroupByTag: tt.groupByTag,
+				Options:    tt.options,
+				Stdin:      bytes.NewReader(input),
+				Stdout:     &stdout,
+			}
+			if err := convertSwaggerToHTTP(config); err != nil {
+				t.Fatalf("convertSwaggerToHTTP failed: %v", err)
+			}
+			if written := readDir(t, outputDir); len(written) > 0 {
+				t.Errorf("Expected no files in the output directory, got %d", len(written))
+			}
+
+			golden := readDir(t, filepath.Join("..", "..", "..", "test", "golden", tt.golden))
+			var generated map[string]string
+			if tt.groupByTag {
+				generated = splitStream(t, stdout.String())
+			} else {
+				generated = map[string]string{"swagger.http": stdout.String()}
+			}
+			if !equalFiles(golden, generated) {
+				t.Errorf("Stream does not match the golden files:\n%s", stdout.String())
+			}
+		})
+	}
+}
+
+// TestExportFormats converts every sample document to the collection formats
+// and checks the exports: Postman collections against the published schema
+// of the format, Insomnia exports for the fields the Insomnia importer reads
+func TestExportFormats(t *testing.T) {
+	schema, err := jsonschema.Compile(filepath.Join("..", "..", "..", "test", "schemas", "postman-collection-v2.1.json"))
+	if err != nil {
+		t.Fatalf("Failed to compile the Postman schema: %v", err)
+	}
+	checks := map[string]func(*testing.T, []byte){
+		formatPostman: func(t *testing.T, data []byte) {
+			var export interface{}
+			if err := json.Unmarshal(data, &export); err != nil {
+				t.Fatalf("Export is not valid JSON: %v", err)
+			}
+			if err := schema.Validate(export); err != nil {
+				t.Errorf("Export does not match the schema: %#v", err)
+			}
+		},
+		formatInsomnia: checkInsomniaExport,
+	}
+	samples := []string{"chaining.yaml", "petstore.json", "polymorphic.yaml", "refs/openapi.yaml", "security.yaml", "servers.yaml", "shared-params.json"}
+
+	for format, check := range checks {
+		for _, sample := range samples {
+			for _, groupByTag := range []bool{true, false} {
+				t.Run(fmt.Sprintf("%s %s grouped %t", format, sample, groupByTag), func(t *testing.T) {
+					var stdout bytes.Buffer
+					config := convertConfig{
+						InputFile:  filepath.Join("..", "..", "..", "test", "samples", sample),
+						GroupByTag: groupByTag,
+						Format:     format,
+						Stdout:     &stdout,
+					}
+					if err := convertSwaggerToHTTP(config); err != nil {
+						t.Fatalf("convertSwaggerToHTTP failed: %v", err)
+					}
+					check(t, stdout.Bytes())
+				})
+			}
+		}
+	}
+}
+
+// checkInsomniaExport checks an Insomnia export for the fields the Insomnia
+// importer reads. Insomnia publishes no schema for its export format 4.
+func checkInsomniaExport(t *testing.T, data []byte) {
+	t.Helper()
+
+	var export struct {
+		Type      string                   `json:"_type"`
+		Format    int                      `json:"__export_format"`
+		Resources []map[string]interface{} `json:"resources"`
+	}
+	if err := json.Unmarshal(data, &export); err != nil {
+		t.Fatalf("Export is not valid JSON: %v", err)
+	}
+	if export.Type != "export" || export.Format != 4 || len(export.Resources) == 0 {
+		t.Fatalf("Expected an export of format 4 with resources, got %s %d with %d resources", export.Type, export.Format, len(export.Resources))
+	}
+
+	// Required fields and ID prefix of the resource types
+	types := map[string]struct {
+		prefix string
+		fields []string
+	}{
+		"workspace":     {"wrk_", []string{"name"}},
+		"environment":   {"env_", []string{"name", "data"}},
+		"request_group": {"fld_", []string{"name"}},
+		"request":       {"req_", []string{"name", "url", "method"}},
+	}
+
+	ids := make(map[string]bool)
+	for _, resource := range export.Resources {
+		id, _ := resource["_id"].(string)
+		ids[id] = true
+	}
+	for _, resource := range export.Resources {
+		id, _ := resource["_id"].(string)
+		kind, _ := resource["_type"].(string)
+		resourceType, known := types[kind]
+		if !known {
+			t.Errorf("Resource %q has an unknown type %q", id, kind)
+			continue
+		}
+		if !strings.HasPrefix(id, resourceType.prefix) {
+			t.Errorf("Expected the ID of %s %q to start with %s", kind, id, resourceType.prefix)
+		}
+		for _, field := range resourceType.fields {
+			if _, ok := resource[field]; !ok {
+				t.Errorf("%s %q has no %s", kind, id, field)
+			}
+		}
+
+		parent, hasParent := resource["parentId"]
+		switch {
+		case !hasParent:
+			t.Errorf("%s %q has no parentId", kind, id)
+		case kind == "workspace" && parent != nil:
+			t.Errorf("Expected workspace %q to have no parent, got %v", id, parent)
+		case kind != "workspace" && !ids[fmt.Sprint(parent)]:
+			t.Errorf("%s %q has an unknown parent %v", kind, id, parent)
+		}
+	}
+}
+
+// splitStream splits the output of WriteHTTPStream back into files
+func splitStream(t *testing.T, stream string) map[string]string {
+	t.Helper()
+
+	files := make(map[string]string)
+	var name string
+	for _, part := range strings.SplitAfter(stream, "\n") {
+		var next string
+		if _, err := fmt.Sscanf(part, streamSeparator+"\n", &next); err == nil {
+			if name != "" {
+				// Drop the blank line separating the files
+				files[name] = strings.TrimSuffix(files[name], "\n")
+			}
+			name = next
+			continue
+		}
+		if name == "" {
+			t.Fatalf("Stream does not start with a separator: %q", part)
+		}
+		files[name] += part
+	}
+
+	// Drop the blank line following each separator
+	for name, content := range files {
+		files[name] = strings.TrimPrefix(content, "\n")
+	}
+	return files
+}
+
+// TestConvertMerge converts every sample document, then merges the document
+// into the files written: unchanged files stay the same and edited bodies are kept
+func TestConvertMerge(t *testing.T) {
+	samples := []string{"chaining.yaml", "petstore.json", "polymorphic.yaml", "refs/openapi.yaml", "security.yaml", "servers.yaml", "shared-params.json"}
+
+	for _, dialect := range []string{http.DialectJetBrains, http.DialectRESTClient} {
+		for _, sample := range samples {
+			t.Run(dialect+" "+sample, func(t *testing.T) {
+				outputDir := t.TempDir()
+				config := convertConfig{
+					InputFile:  filepath.Join("..", "..", "..", "test", "samples", sample),
+					OutputDir:  outputDir,
+					GroupByTag: true,
+					Dialect:    dialect,
+				}
+				if err := convertSwaggerToHTTP(config); err != nil {
+					t.Fatalf("convertSwaggerToHTTP failed: %v", err)
+				}
+				converted := readDir(t, outputDir)
+
+				config.Merge = true
+				if err := convertSwaggerToHTTP(config); err != nil {
+					t.Fatalf("convertSwaggerToHTTP failed to merge: %v", err)
+				}
+				if merged := readDir(t, outputDir); !equalFiles(converted, merged) {
+					t.Errorf("Merging unchanged files changed them:\n%v\nwant:\n%v", merged, converted)
+				}
+			})
+		}
+	}
+
+	t.Run("edited body", func(t *testing.T) {
+		outputDir := t.TempDir()
+		config := convertConfig{
+			InputFile:  filepath.Join("..", "..", "..", "test", "samples", "chaining.yaml"),
+			OutputDir:  outputDir,
+			GroupByTag: true,
+		}
+		if err := convertSwaggerToHTTP(config); err != nil {
+			t.Fatalf("convertSwaggerToHTTP failed: %v", err)
+		}
+
+		path := filepath.Join(outputDir, "pets.http")
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read file: %v", err)
+		}
+		edited := strings.Replace(string(content), `"name": "string"`, `"name": "Rex"`, 1)
+		edited += "### Check health\nGET {{baseUrl}}/health\n"
+		if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+
+		config.Merge = true
+		if err := convertSwaggerToHTTP(config); err != nil {
+			t.Fatalf("convertSwaggerToHTTP failed to merge: %v", err)
+		}
+
+		merged, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read file: %v", err)
+		}
+		for _, expected := range []string{`"name": "Rex"`, "### Check health\nGET {{baseUrl}}/health\n"} {
+			if !strings.Contains(string(merged), expected) {
+				t.Errorf("Expected merged file to contain %q:\n%s", expected, merged)
+			}
+		}
+	})
+}
@@ -2,26 +2,49 @@ package cli
 
 import (
 	"fmt"
+	nethttp "net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/edgardnogueira/swagger-to-http-file/internal/adapters/http"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/infrastructure/remote"
 	"github.com/spf13/cobra"
 )
 
 var (
-	inputFile  string
-	outputDir  string
-	baseURL    string
-	verbose    bool
-	overwrite  bool
-	groupByTag bool
+	inputFile       string
+	outputDir       string
+	baseURL         string
+	verbose         bool
+	overwrite       bool
+	merge           bool
+	groupByTag      bool
+	branches        []string
+	branchVariants  bool
+	exampleVariants bool
+	requiredQuery   bool
+	sortBy          string
+	envFile         bool
+	server          string
+	headers         []string
+	bearerToken     string
+	timeout         time.Duration
+	cacheDir        string
+	noCache         bool
+	toStdout        bool
+	assertions      bool
+	fakeData        bool
+	seed            int64
+	dialect         string
+	format          string
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "swagger-to-http-file",
 	Short: "Convert Swagger/OpenAPI documents to .http files",
-	Long: `A CLI tool that converts Swagger/OpenAPI JSON documents into .http files 
+	Long: `A CLI tool that converts Swagger/OpenAPI JSON or YAML documents into .http files 
 	for easy API testing. It handles various parameter types and 
 	can organize requests by tags.`,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -53,13 +76,32 @@ func Execute() error {
 }
 
 func init() {
-	// Define flags
-	rootCmd.PersistentFlags().StringVarP(&inputFile, "input", "i", "", "Swagger/OpenAPI JSON file to convert (required)")
-	rootCmd.PersistentFlags().StringVarP(&outputDir, "output", "o", ".", "Directory to save .http files")
-	rootCmd.PersistentFlags().StringVarP(&baseURL, "baseUrl", "b", "", "Base URL for API requests (overrides the one in Swagger)")
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
-	rootCmd.PersistentFlags().BoolVarP(&overwrite, "overwrite", "w", false, "Overwrite existing files")
-	rootCmd.PersistentFlags().BoolVarP(&groupByTag, "group-by-tag", "g", true, "Group requests by tags into separate files")
+	// Define flags. They only apply to the conversion, the subcommands have their own.
+	rootCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Swagger/OpenAPI JSON or YAML file or HTTP(S) URL to convert, - for stdin (required)")
+	rootCmd.Flags().StringVarP(&outputDir, "output", "o", ".", "Directory to save .http files")
+	rootCmd.Flags().StringVarP(&baseURL, "baseUrl", "b", "", "Base URL for API requests (overrides the one in Swagger)")
+	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.Flags().BoolVar(&toStdout, "stdout", false, "Write the .http files to stdout instead of the output directory")
+	rootCmd.Flags().BoolVarP(&overwrite, "overwrite", "w", false, "Overwrite existing files")
+	rootCmd.Flags().BoolVar(&merge, "merge", false, "Merge existing .http files with the document, keeping edited bodies, variable values and custom requests")
+	rootCmd.Flags().BoolVarP(&groupByTag, "group-by-tag", "g", true, "Group requests by tags into separate files")
+	rootCmd.Flags().StringSliceVar(&branches, "branch", nil, "Preferred oneOf/anyOf branch by schema name, title or discriminator value (repeatable)")
+	rootCmd.Flags().BoolVar(&branchVariants, "branch-variants", false, "Generate one request per oneOf/anyOf branch of a request body")
+	rootCmd.Flags().BoolVar(&exampleVariants, "example-variants", false, "Generate one request per named example of a request body, titled with the example summary")
+	rootCmd.Flags().BoolVar(&requiredQuery, "required-query-only", false, "Only include required query parameters in request URLs")
+	rootCmd.Flags().StringVar(&sortBy, "sort", http.SortSource, "Order of requests in each file: source, path, method or operationId")
+	rootCmd.Flags().StringVar(&server, "server", "", "Server to use for the base URL, by index (starting at 0) or description")
+	rootCmd.Flags().BoolVar(&assertions, "assertions", false, "Assert the documented status, Content-Type and required fields of responses")
+	rootCmd.Flags().BoolVar(&fakeData, "fake-data", false, "Fill bodies and parameters with plausible data, such as names, addresses and prices, chosen from property names and formats")
+	rootCmd.Flags().Int64Var(&seed, "seed", 0, "Seed of the --fake-data values, the same seed always gives the same values")
+	rootCmd.Flags().StringVar(&dialect, "dialect", http.DialectJetBrains, "HTTP client whose .http syntax to write: jetbrains, rest-client, httpyac or kulala")
+	rootCmd.Flags().StringVar(&format, "format", formatHTTP, "Kind of files to write: http for .http files, curl for executable curl scripts, postman for a Postman collection or insomnia for an Insomnia export")
+	rootCmd.Flags().BoolVar(&envFile, "env-file", false, "Write variable values to http-client.env.json, http-client.private.env.json and .vscode/settings.json instead of the .http files")
+	rootCmd.Flags().StringArrayVarP(&headers, "header", "H", nil, "Header sent when fetching a URL input, as \"Name: value\" (repeatable)")
+	rootCmd.Flags().StringVar(&bearerToken, "bearer-token", "", "Bearer token sent when fetching a URL input")
+	rootCmd.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "Timeout of each request when fetching a URL input")
+	rootCmd.Flags().StringVar(&cacheDir, "cache-dir", remote.DefaultCacheDir(), "Directory caching documents fetched from URLs")
+	rootCmd.Flags().BoolVar(&noCache, "no-cache", false, "Do not cache documents fetched from URLs")
 
 	// Make input file required
 	// We don't enforce this with cobra to allow for positional argument usage
@@ -72,13 +114,45 @@ func run() error {
 		return fmt.Errorf("input file is required")
 	}
 
-	// Check if input file exists
-	if !fileExists(inputFile) {
+	if !http.ValidSortBy(sortBy) {
+		return fmt.Errorf("invalid sort order %q: must be source, path, method or operationId", sortBy)
+	}
+
+	if !http.ValidDialect(dialect) {
+		return fmt.Errorf("invalid dialect %q: must be jetbrains, rest-client, httpyac or kulala", dialect)
+	}
+
+	if _, ok := collectionFormats[format]; !ok && format != formatHTTP && format != formatCurl {
+		return fmt.Errorf("invalid format %q: must be http, curl, postman or insomnia", format)
+	}
+
+	if format != formatHTTP && (envFile || assertions) {
+		return fmt.Errorf("--env-file and --assertions cannot be used with --format %s", format)
+	}
+
+	if merge && overwrite {
+		return fmt.Errorf("--merge cannot be used with --overwrite")
+	}
+
+	if merge && (format != formatHTTP || toStdout) {
+		return fmt.Errorf("--merge can only be used with --format http, without --stdout")
+	}
+
+	if assertions && !http.DialectAssertions(dialect) {
+		return fmt.Errorf("the %s dialect cannot assert responses", dialect)
+	}
+
+	if toStdout && envFile {
+		return fmt.Errorf("--env-file cannot be used with --stdout")
+	}
+
+	// Check if input file exists, URLs are checked when fetched
+	if inputFile != stdinInput && !remote.IsURL(inputFile) && !fileExists(inputFile) {
 		return fmt.Errorf("input file not found: %s", inputFile)
 	}
 
 	// Check if output directory exists, create if not
-	if !dirExists(outputDir) {
+	if !toStdout && !dirExists(outputDir) {
 		if verbose {
 			fmt.Printf("Creating output directory: %s\n", outputDir)
 		}
@@ -88,13 +162,72 @@ func run() error {
 	}
 
 	// This function will be implemented in another file
-	if err := convertSwaggerToHTTP(inputFile, outputDir, baseURL, groupByTag, overwrite, verbose); err != nil {
+	options := http.Options{
+		Branches:          branches,
+		BranchVariants:    branchVariants,
+		ExampleVariants:   exampleVariants,
+		RequiredQueryOnly: requiredQuery,
+		SortBy:            sortBy,
+		Assertions:        assertions,
+		FakeData:          fakeData,
+		Seed:              seed,
+	}
+
+	fetch, err := fetchOptions()
+	if err != nil {
+		return err
+	}
+
+	config := convertConfig{
+		InputFile:  inputFile,
+		OutputDir:  outputDir,
+		BaseURL:    baseURL,
+		Server:     server,
+		GroupByTag: groupByTag,
+		Overwrite:  overwrite,
+		Merge:      merge,
+		Verbose:    verbose,
+		EnvFile:    envFile,
+		Options:    options,
+		Dialect:    dialect,
+		Format:     format,
+		Fetch:      fetch,
+		Stdin:      os.Stdin,
+	}
+	if toStdout {
+		config.Stdout = os.Stdout
+	}
+	if err := convertSwaggerToHTTP(config); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// fetchOptions builds the options used to fetch URL inputs from the flags
+func fetchOptions() (remote.Options, error) {
+	options := remote.Options{
+		Headers: make(nethttp.Header),
+		Timeout: timeout,
+	}
+	if !noCache {
+		options.CacheDir = cacheDir
+	}
+
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return options, fmt.Errorf("invalid header %q: must be \"Name: value\"", header)
+		}
+		options.Headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	if bearerToken != "" {
+		options.Headers.Set("Authorization", "Bearer "+bearerToken)
+	}
+	return options, nil
+}
+
 // Helper functions
 func fileExists(path string) bool {
 	info, err := os.Stat(path)
@@ -85,3 +85,35 @@ func randString(length int) string {
 	}
 	return string(b)
 }
+
+func TestFetchOptions(t *testing.T) {
+	defer func() {
+		headers, bearerToken, noCache = nil, "", false
+	}()
+
+	headers = []string{"X-Tenant: acme", "Accept:application/yaml"}
+	bearerToken = "secret"
+	noCache = true
+
+	options, err := fetchOptions()
+	if err != nil {
+		t.Fatalf("fetchOptions failed: %v", err)
+	}
+	if got := options.Headers.Get("X-Tenant"); got != "acme" {
+		t.Errorf("Expected X-Tenant acme, got %q", got)
+	}
+	if got := options.Headers.Get("Accept"); got != "application/yaml" {
+		t.Errorf("Expected Accept application/yaml, got %q", got)
+	}
+	if got := options.Headers.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Expected bearer authorization, got %q", got)
+	}
+	if options.CacheDir != "" {
+		t.Errorf("Expected no cache directory with --no-cache, got %q", options.CacheDir)
+	}
+
+	headers = []string{"X-Tenant"}
+	if _, err := fetchOptions(); err == nil {
+		t.Error("Expected an error for a header without value")
+	}
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/adapters/http"
+	"github.com/edgardnogueira/swagger-to-http-file/internal/infrastructure/runner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runEnv     string
+	runVars    []string
+	runNames   []string
+	runTags    []string
+	runTimeout time.Duration
+)
+
+// runCmd sends the requests of .http files
+var runCmd = &cobra.Command{
+	Use:   "run [file or directory...]",
+	Short: "Send the requests of .http files",
+	Long: `Send the requests of .http and .rest files in order and print the status,
+	timing and body of each response. Directories are searched for .http and
+	.rest files. Values captured from a response are available to the requests
+	sent after it.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		vars, err := parseVars(runVars)
+		if err == nil {
+			config := runConfig{
+				Paths: args,
+				Env:   runEnv,
+				Options: runner.Options{
+					Vars:    vars,
+					Names:   runNames,
+					Tags:    runTags,
+					Timeout: runTimeout,
+				},
+			}
+			err = runRequests(config, os.Stdout)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// runConfig holds the settings of the run command
+type runConfig struct {
+	Paths   []string // .http or .rest files, or directories containing them
+	Env     string   // environment read from the env files next to each file
+	Options runner.Options
+}
+
+func init() {
+	runCmd.Flags().StringVar(&runEnv, "env", "", "Environment whose variables to read from the http-client.env.json and http-client.private.env.json files next to the .http files")
+	runCmd.Flags().StringArrayVar(&runVars, "var", nil, "Variable value, as name=value, overriding the files and the environment (repeatable)")
+	runCmd.Flags().StringArrayVar(&runNames, "name", nil, "Only send the requests with this name (# @name) or title (repeatable)")
+	runCmd.Flags().StringArrayVar(&runTags, "tag", nil, "Only send the requests of the files of this tag, e.g. pets for pets.http (repeatable)")
+	runCmd.Flags().DurationVar(&runTimeout, "timeout", 30*time.Second, "Timeout of each request")
+	rootCmd.AddCommand(runCmd)
+}
+
+// runRequests sends the requests of the files and prints the responses to out
+func runRequests(config runConfig, out io.Writer) error {
+	files, err := httpFilePaths(config.Paths)
+	if err != nil {
+		return err
+	}
+
+	r := runner.New(config.Options, out)
+	envs := make(map[string]map[string]string)
+	for _, path := range files {
+		file, err := http.NewParser().ParseFile(path)
+		if err != nil {
+			return err
+		}
+
+		dir := filepath.Dir(path)
+		env, ok := envs[dir]
+		if !ok && config.Env != "" {
+			if env, err = readEnvironment(dir, config.Env); err != nil {
+				return err
+			}
+			envs[dir] = env
+		}
+
+		r.Run(file, dir, env)
+	}
+
+	if r.Sent() == 0 {
+		return fmt.Errorf("no requests to send")
+	}
+	fmt.Fprintf(out, "%d requests sent, %d failed\n", r.Sent(), r.Failed())
+	if r.Failed() > 0 {
+		return fmt.Errorf("%d of %d requests failed", r.Failed(), r.Sent())
+	}
+	return nil
+}
+
+// httpFilePaths returns the files to run: the files given and the .http and
+// .rest files of the directories given, in name order
+func httpFilePaths(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		if !dirExists(path) {
+			if !fileExists(path) {
+				return nil, fmt.Errorf("file not found: %s", path)
+			}
+			files = append(files, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory %s: %v", path, err)
+		}
+		var found []string
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if !entry.IsDir() && (ext == ".http" || ext == ".rest") {
+				found = append(found, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// parseVars parses name=value variables
+func parseVars(values []string) (map[string]string, error) {
+	vars := make(map[string]string, len(values))
+	for _, value := range values {
+		name, v, ok := strings.Cut(value, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid variable %q: must be name=value", value)
+		}
+		vars[strings.TrimSpace(name)] = v
+	}
+	return vars, nil
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"bytes"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/infrastructure/runner"
+)
+
+func TestRunRequests(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		received = append(received, r.Method+" "+r.URL.RequestURI()+" "+r.Header.Get("X-API-Key"))
+		if r.Method == nethttp.MethodPost {
+			w.WriteHeader(nethttp.StatusCreated)
+			w.Write([]byte(`{"id":7}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"pets.http": "@baseUrl = http://unused.example.com\n\n" +
+			"### Create a pet\n# @name createPet\nPOST {{baseUrl}}/pets\nX-API-Key: {{apiKey}}\n\n" +
+			"> {% client.global.set(\"petId\", response.body.id); %}\n\n" +
+			"### Get a pet\n# @name getPet\nGET {{baseUrl}}/pets/{{petId}}?limit={{limit}}\nX-API-Key: {{apiKey}}\n",
+		"users.rest":            "### List users\n# @name listUsers\nGET {{baseUrl}}/users\n",
+		"notes.txt":             "GET /ignored\n",
+		jetBrainsEnvFile:        `{"local": {"baseUrl": "http://unused.example.com", "limit": "5"}}`,
+		jetBrainsPrivateEnvFile: `{"local": {"apiKey": "secret"}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	var out bytes.Buffer
+	err := runRequests(runConfig{
+		Paths:   []string{dir},
+		Env:     "local",
+		Options: runner.Options{Vars: map[string]string{"baseUrl": server.URL}},
+	}, &out)
+	if err != nil {
+		t.Fatalf("runRequests failed: %v\n%s", err, out.String())
+	}
+
+	expected := []string{
+		"POST /pets secret",
+		"GET /pets/7?limit=5 secret",
+		"GET /users ",
+	}
+	if strings.Join(received, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected requests:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(received, "\n"))
+	}
+	if !strings.HasSuffix(out.String(), "3 requests sent, 0 failed\n") {
+		t.Errorf("Expected a summary of 3 requests, got:\n%s", out.String())
+	}
+
+	err = runRequests(runConfig{Paths: []string{dir}, Env: "staging"}, &out)
+	if err == nil || !strings.Contains(err.Error(), `environment "staging" not found`) {
+		t.Errorf("Expected an unknown environment error, got %v", err)
+	}
+
+	err = runRequests(runConfig{Paths: []string{dir}, Options: runner.Options{Names: []string{"missing"}}}, &out)
+	if err == nil || err.Error() != "no requests to send" {
+		t.Errorf("Expected no requests to send, got %v", err)
+	}
+}
+
+func TestParseVars(t *testing.T) {
+	vars, err := parseVars([]string{"baseUrl=http://localhost:8080", " token =a=b", "empty="})
+	if err != nil {
+		t.Fatalf("parseVars failed: %v", err)
+	}
+	expected := map[string]string{"baseUrl": "http://localhost:8080", "token": "a=b", "empty": ""}
+	if len(vars) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, vars)
+	}
+	for name, value := range expected {
+		if vars[name] != value {
+			t.Errorf("Expected %s=%q, got %q", name, value, vars[name])
+		}
+	}
+
+	for _, invalid := range []string{"baseUrl", "=value"} {
+		if _, err := parseVars([]string{invalid}); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestRunCmd_Flags(t *testing.T) {
+	// The conversion flags are not inherited by the run command
+	for _, name := range []string{"input", "format", "merge", "dialect"} {
+		if runCmd.Flag(name) != nil {
+			t.Errorf("Expected run not to accept --%s", name)
+		}
+	}
+
+	// --timeout is the timeout of the requests sent, not of fetching a URL input
+	if flag := runCmd.Flag("timeout"); flag == nil || flag.Usage != "Timeout of each request" {
+		t.Errorf("Expected run to have its own --timeout, got %+v", flag)
+	}
+}
@@ -0,0 +1,215 @@
+package remote
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxDocumentSize limits the size of a downloaded document
+const maxDocumentSize = 64 << 20
+
+// Options controls how documents are downloaded
+type Options struct {
+	// Headers are sent with every request to the host of the input document
+	Headers http.Header
+
+	// Timeout limits the duration of a request, including reading the body
+	Timeout time.Duration
+
+	// CacheDir stores downloaded documents, revalidated with ETag and
+	// If-Modified-Since. Documents are not cached when it is empty.
+	CacheDir string
+}
+
+// Fetcher downloads Swagger/OpenAPI documents over HTTP(S)
+type Fetcher struct {
+	options Options
+	client  *http.Client
+	host    string
+}
+
+// cacheEntry describes a cached document
+type cacheEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// IsURL reports whether a location is an HTTP(S) URL rather than a file path
+func IsURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// Fetch downloads the document at rawURL. The headers are only sent to the
+// host of the first document fetched, the input document, so that credentials
+// do not leak to the hosts of external references.
+func (f *Fetcher) Fetch(rawURL string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+
+	if f.host == "" {
+		f.host = req.URL.Host
+	}
+	if req.URL.Host == f.host {
+		for name, values := range f.options.Headers {
+			for _, value := range values {
+				req.Header.Add(name, value)
+			}
+		}
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json, application/yaml;q=0.9, */*;q=0.8")
+	}
+
+	// Revalidate a cached copy instead of downloading it again
+	cached, entry := f.readCache(rawURL)
+	if cached != nil {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return cached, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to fetch %s: %s", rawURL, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	if len(body) > maxDocumentSize {
+		return nil, fmt.Errorf("document %s is larger than %d bytes", rawURL, maxDocumentSize)
+	}
+
+	if err := f.writeCache(rawURL, body, resp.Header); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// Load reads the document at a location, downloading URLs and reading file
+// paths from disk. It can be used as the swagger.Loader of external references.
+func (f *Fetcher) Load(location string) ([]byte, error) {
+	if IsURL(location) {
+		return f.Fetch(location)
+	}
+	return os.ReadFile(location)
+}
+
+// readCache returns the cached document for a URL, or nil when there is none
+func (f *Fetcher) readCache(rawURL string) ([]byte, *cacheEntry) {
+	if f.options.CacheDir == "" {
+		return nil, nil
+	}
+
+	base := f.cachePath(rawURL)
+	data, err := os.ReadFile(base + ".json")
+	if err != nil {
+		return nil, nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != rawURL {
+		return nil, nil
+	}
+
+	body, err := os.ReadFile(base + ".body")
+	if err != nil {
+		return nil, nil
+	}
+	return body, &entry
+}
+
+// writeCache stores a downloaded document with its validators. Documents
+// without ETag or Last-Modified cannot be revalidated and are not stored.
+func (f *Fetcher) writeCache(rawURL string, body []byte, header http.Header) error {
+	if f.options.CacheDir == "" {
+		return nil
+	}
+
+	entry := cacheEntry{
+		URL:          rawURL,
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+	}
+	if entry.ETag == "" && entry.LastModified == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(f.options.CacheDir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	base := f.cachePath(rawURL)
+	if err := os.WriteFile(base+".body", body, 0644); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := os.WriteFile(base+".json", data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	return nil
+}
+
+// cachePath returns the cache file path of a URL, without extension
+func (f *Fetcher) cachePath(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return filepath.Join(f.options.CacheDir, hex.EncodeToString(sum[:]))
+}
+
+// DefaultCacheDir returns the cache directory used when none is configured
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "swagger-to-http-file")
+}
+
+// PathOf returns the path component of a URL, used to detect its format from
+// the extension. Other locations are returned unchanged.
+func PathOf(location string) string {
+	if !IsURL(location) {
+		return location
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return location
+	}
+	return u.Path
+}
+
+// New creates a new Fetcher instance
+func New(options Options) *Fetcher {
+	return &Fetcher{
+		options: options,
+		client:  &http.Client{Timeout: options.Timeout},
+	}
+}
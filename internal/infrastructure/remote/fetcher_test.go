@@ -0,0 +1,201 @@
+package remote
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsURL(t *testing.T) {
+	tests := []struct {
+		location string
+		expected bool
+	}{
+		{"https://api.example.com/v3/api-docs", true},
+		{"http://localhost:8080/openapi.yaml", true},
+		{"openapi.yaml", false},
+		{"/tmp/openapi.json", false},
+		{"ftp://example.com/openapi.yaml", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsURL(tt.location); got != tt.expected {
+			t.Errorf("IsURL(%q) = %v, expected %v", tt.location, got, tt.expected)
+		}
+	}
+}
+
+func TestPathOf(t *testing.T) {
+	tests := []struct {
+		location string
+		expected string
+	}{
+		{"https://api.example.com/specs/openapi.yaml?version=2", "/specs/openapi.yaml"},
+		{"https://api.example.com/v3/api-docs", "/v3/api-docs"},
+		{"specs/openapi.json", "specs/openapi.json"},
+	}
+
+	for _, tt := range tests {
+		if got := PathOf(tt.location); got != tt.expected {
+			t.Errorf("PathOf(%q) = %q, expected %q", tt.location, got, tt.expected)
+		}
+	}
+}
+
+func TestFetch_Headers(t *testing.T) {
+	var external *httptest.Server
+	external = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Credentials sent to external host: %q", auth)
+		}
+		w.Write([]byte("external"))
+	}))
+	defer external.Close()
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("X-Tenant") != "acme" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("origin"))
+	}))
+	defer origin.Close()
+
+	fetcher := New(Options{Headers: http.Header{
+		"Authorization": {"Bearer secret"},
+		"X-Tenant":      {"acme"},
+	}})
+
+	body, err := fetcher.Fetch(origin.URL + "/openapi.json")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if string(body) != "origin" {
+		t.Errorf("Expected origin body, got %q", body)
+	}
+
+	// Headers are only sent to the host of the input document
+	if _, err := fetcher.Fetch(external.URL + "/common.json"); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+}
+
+func TestFetch_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		timeout  time.Duration
+		expected string
+	}{
+		{
+			name:     "not found",
+			path:     "/missing.json",
+			expected: "404 Not Found",
+		},
+		{
+			name:     "timeout",
+			path:     "/slow",
+			timeout:  50 * time.Millisecond,
+			expected: "Timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := New(Options{Timeout: tt.timeout})
+			_, err := fetcher.Fetch(server.URL + tt.path)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestFetch_Cache(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator string // response header validating the document
+		condition string // request header revalidating the cached copy
+		value     string
+	}{
+		{
+			name:      "etag",
+			validator: "ETag",
+			condition: "If-None-Match",
+			value:     `"v1"`,
+		},
+		{
+			name:      "last modified",
+			validator: "Last-Modified",
+			condition: "If-Modified-Since",
+			value:     "Wed, 14 Oct 2026 10:00:00 GMT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			downloads := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get(tt.condition) == tt.value {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				downloads++
+				w.Header().Set(tt.validator, tt.value)
+				w.Write([]byte(`{"openapi": "3.0.0"}`))
+			}))
+			defer server.Close()
+
+			cacheDir := t.TempDir()
+			for i := 0; i < 2; i++ {
+				// A new fetcher only shares the cache directory
+				fetcher := New(Options{CacheDir: cacheDir})
+				body, err := fetcher.Fetch(server.URL + "/openapi.json")
+				if err != nil {
+					t.Fatalf("Fetch failed: %v", err)
+				}
+				if string(body) != `{"openapi": "3.0.0"}` {
+					t.Errorf("Unexpected body %q", body)
+				}
+			}
+
+			if downloads != 1 {
+				t.Errorf("Expected the document to be downloaded once, got %d downloads", downloads)
+			}
+		})
+	}
+}
+
+func TestFetch_NoValidator(t *testing.T) {
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	for i := 0; i < 2; i++ {
+		if _, err := New(Options{CacheDir: cacheDir}).Fetch(server.URL); err != nil {
+			t.Fatalf("Fetch failed: %v", err)
+		}
+	}
+
+	// Documents that cannot be revalidated are downloaded every time
+	if downloads != 2 {
+		t.Errorf("Expected 2 downloads, got %d", downloads)
+	}
+}
@@ -0,0 +1,412 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// maxExpansionDepth limits the nesting of variables referencing other variables
+const maxExpansionDepth = 10
+
+// placeholderPattern matches the {{variable}} placeholders of .http files
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// Options controls which requests are sent and how
+type Options struct {
+	// Vars override every other value of a variable
+	Vars map[string]string
+
+	// Names selects the requests to send by name (# @name) or title, all when empty
+	Names []string
+
+	// Tags selects the files to send by tag, the name of the file without its
+	// extension, all when empty
+	Tags []string
+
+	// Timeout limits the duration of a request, including reading the body
+	Timeout time.Duration
+}
+
+// Runner sends the requests of .http files and prints their responses.
+// Values captured from a response are available to the requests sent after it,
+// including those of other files.
+type Runner struct {
+	options Options
+	client  *http.Client
+	out     io.Writer
+
+	captured  map[string]string // values set by response handlers
+	responses map[string][]byte // response bodies by request ID
+	sent      int
+	failed    int
+}
+
+// scope holds the variables available to a request
+type scope struct {
+	file *models.HTTPFile
+	req  models.HTTPRequest
+	env  map[string]string
+}
+
+// Run sends the selected requests of a file in order. Body files are read
+// relative to dir and env holds the values of the selected environment.
+// Requests that cannot be sent or receive an error status are reported and
+// counted as failed, the following requests are still sent.
+func (r *Runner) Run(file *models.HTTPFile, dir string, env map[string]string) {
+	if !r.selectedTag(file.Tag) {
+		return
+	}
+
+	for _, req := range file.Requests {
+		if !r.selectedName(req) {
+			continue
+		}
+		r.sent++
+		if err := r.send(req, scope{file: file, req: req, env: env}, dir); err != nil {
+			r.failed++
+			fmt.Fprintf(r.out, "Error: %v\n\n", err)
+		}
+	}
+}
+
+// Sent returns the number of requests sent
+func (r *Runner) Sent() int {
+	return r.sent
+}
+
+// Failed returns the number of requests that failed
+func (r *Runner) Failed() int {
+	return r.failed
+}
+
+// send sends a request and prints its response
+func (r *Runner) send(req models.HTTPRequest, s scope, dir string) error {
+	fmt.Fprintf(r.out, "### %s\n", req.Name)
+
+	rawURL := req.Path
+	if strings.HasPrefix(rawURL, "/") {
+		rawURL = "{{baseUrl}}" + rawURL
+	}
+	rawURL, err := r.expand(rawURL, s)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(r.out, "%s %s\n", req.Method, rawURL)
+
+	var body io.Reader
+	var contentType string
+	multipart := len(req.FormParts) > 0
+	switch {
+	case req.BodyFile != "":
+		data, err := os.ReadFile(bodyPath(req.BodyFile, dir))
+		if err != nil {
+			return fmt.Errorf("failed to read body file: %v", err)
+		}
+		body = bytes.NewReader(data)
+	case multipart:
+		data, formType, err := r.multipartBody(req.FormParts, s, dir)
+		if err != nil {
+			return err
+		}
+		body, contentType = bytes.NewReader(data), formType
+	case req.Body != "":
+		expanded, err := r.expand(req.Body, s)
+		if err != nil {
+			return err
+		}
+		body = strings.NewReader(expanded)
+	}
+
+	httpReq, err := http.NewRequest(req.Method, rawURL, body)
+	if err != nil {
+		return fmt.Errorf("invalid request: %v", err)
+	}
+	for _, name := range sortedKeys(req.Headers) {
+		value, err := r.expand(req.Headers[name], s)
+		if err != nil {
+			return err
+		}
+		if strings.EqualFold(name, "Authorization") {
+			value = basicCredentials(value)
+		}
+		httpReq.Header.Set(name, value)
+	}
+	if contentType != "" {
+		// The parts are separated by a new boundary
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+
+	start := time.Now()
+	resp, err := r.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+	elapsed := time.Since(start)
+
+	fmt.Fprintf(r.out, "%s (%s)\n", resp.Status, elapsed.Round(time.Millisecond))
+	if len(data) > 0 {
+		fmt.Fprintf(r.out, "\n%s", data)
+		if !bytes.HasSuffix(data, []byte("\n")) {
+			fmt.Fprintln(r.out)
+		}
+	}
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("%s returned %s", req.Method, resp.Status)
+	}
+
+	fmt.Fprintln(r.out)
+
+	if req.ID != "" {
+		r.responses[req.ID] = data
+	}
+	for _, capture := range req.Captures {
+		value, ok := jsonValue(data, capture.Path)
+		if !ok {
+			return fmt.Errorf("cannot capture %s: no %s in the response body", capture.Variable, capture.Path)
+		}
+		r.captured[capture.Variable] = value
+	}
+
+	return nil
+}
+
+// multipartBody encodes the parts of a multipart/form-data body, reading the
+// parts written as "< path" from their file. It returns the body and its
+// Content-Type with the boundary.
+func (r *Runner) multipartBody(parts []models.FormPart, s scope, dir string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, part := range parts {
+		header := make(textproto.MIMEHeader)
+		disposition := map[string]string{"name": part.Name}
+		if part.FileName != "" {
+			disposition["filename"] = part.FileName
+		}
+		header.Set("Content-Disposition", mime.FormatMediaType("form-data", disposition))
+		if part.ContentType != "" {
+			header.Set("Content-Type", part.ContentType)
+		}
+
+		var content []byte
+		if part.File != "" {
+			data, err := os.ReadFile(bodyPath(part.File, dir))
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to read form file: %v", err)
+			}
+			content = data
+		} else {
+			expanded, err := r.expand(part.Value, s)
+			if err != nil {
+				return nil, "", err
+			}
+			content = []byte(expanded)
+		}
+
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := w.Write(content); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+// bodyPath returns the path of a file a body is read from, relative to the
+// directory of the .http file
+func bodyPath(path, dir string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// expand replaces the placeholders of s with the values of their variables
+func (r *Runner) expand(s string, sc scope) (string, error) {
+	return r.expandDepth(s, sc, 0)
+}
+
+func (r *Runner) expandDepth(s string, sc scope, depth int) (string, error) {
+	if depth > maxExpansionDepth {
+		return "", fmt.Errorf("variables nested too deeply in %q", s)
+	}
+
+	var expandErr error
+	expanded := placeholderPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if expandErr != nil {
+			return match
+		}
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		value, err := r.lookup(name, sc)
+		if err == nil {
+			value, err = r.expandDepth(value, sc, depth+1)
+		}
+		if err != nil {
+			expandErr = err
+			return match
+		}
+		return value
+	})
+
+	return expanded, expandErr
+}
+
+// lookup returns the value of a variable. Values given to the runner win,
+// then captured values, response variables, the values of the request, which
+// override file variables of the same name, file variables and finally those
+// of the environment.
+func (r *Runner) lookup(name string, sc scope) (string, error) {
+	if value, ok := r.options.Vars[name]; ok {
+		return value, nil
+	}
+	if value, ok := r.captured[name]; ok {
+		return value, nil
+	}
+	if ref, ok := sc.file.ResponseVars[name]; ok {
+		data, sent := r.responses[ref.Request]
+		if !sent {
+			return "", fmt.Errorf("variable %s reads the response of %s, which has not been sent", name, ref.Request)
+		}
+		value, ok := jsonValue(data, ref.Path)
+		if !ok {
+			return "", fmt.Errorf("variable %s: no %s in the response of %s", name, ref.Path, ref.Request)
+		}
+		return value, nil
+	}
+	if value, ok := sc.req.Vars[name]; ok {
+		return value, nil
+	}
+	if value, ok := sc.file.GlobalVars[name]; ok {
+		return value, nil
+	}
+	if value, ok := sc.env[name]; ok {
+		return value, nil
+	}
+	return "", fmt.Errorf("undefined variable %s", name)
+}
+
+// selectedTag reports whether the requests of a file with the tag are sent
+func (r *Runner) selectedTag(tag string) bool {
+	if len(r.options.Tags) == 0 {
+		return true
+	}
+	for _, selected := range r.options.Tags {
+		if strings.EqualFold(selected, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectedName reports whether a request is sent
+func (r *Runner) selectedName(req models.HTTPRequest) bool {
+	if len(r.options.Names) == 0 {
+		return true
+	}
+	for _, selected := range r.options.Names {
+		if selected == req.ID || selected == req.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// basicCredentials encodes the credentials of an Authorization header written
+// as "Basic username password", the way HTTP clients send them
+func basicCredentials(value string) string {
+	fields := strings.Fields(value)
+	if len(fields) != 3 || !strings.EqualFold(fields[0], "Basic") {
+		return value
+	}
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(fields[1]+":"+fields[2]))
+}
+
+// jsonValue returns the value at a dot-separated path of a JSON document, e.g.
+// data.items.0.id. Strings are returned as they are, other values as JSON.
+func jsonValue(data []byte, path string) (string, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", false
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			child, ok := node[key]
+			if !ok {
+				return "", false
+			}
+			value = child
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return "", false
+			}
+			value = node[index]
+		default:
+			return "", false
+		}
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return value, true
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return "", false
+		}
+		return string(encoded), true
+	}
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// New creates a new Runner printing the responses to out
+func New(options Options, out io.Writer) *Runner {
+	return &Runner{
+		options:   options,
+		client:    &http.Client{Timeout: options.Timeout},
+		out:       out,
+		captured:  make(map[string]string),
+		responses: make(map[string][]byte),
+	}
+}
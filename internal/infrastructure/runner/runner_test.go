@@ -0,0 +1,302 @@
+package runner
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http-file/internal/domain/models"
+)
+
+// newPetServer serves a small pet API recording the requests it receives
+func newPetServer(t *testing.T, received *[]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fields := []string{r.Method, r.URL.RequestURI()}
+		for _, field := range []string{r.Header.Get("Authorization"), string(body)} {
+			if field != "" {
+				fields = append(fields, field)
+			}
+		}
+		*received = append(*received, strings.Join(fields, " "))
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/pets":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":42,"owner":{"name":"Ann"},"tags":["a","b"]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/pets/42":
+			w.Write([]byte(`{"id":42}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not found"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRunner_Run(t *testing.T) {
+	var received []string
+	server := newPetServer(t, &received)
+
+	file := &models.HTTPFile{
+		GlobalVars:   map[string]string{"baseUrl": "http://unused.example.com", "user": "ann", "password": "secret"},
+		ResponseVars: map[string]models.ResponseRef{"ownerName": {Request: "createPet", Path: "owner.name"}},
+		Requests: []models.HTTPRequest{
+			{
+				Name:     "Create a pet",
+				ID:       "createPet",
+				Method:   "POST",
+				Path:     "/pets",
+				Headers:  map[string]string{"Authorization": "Basic {{user}} {{password}}"},
+				Body:     `{"name": "{{name}}"}`,
+				Vars:     map[string]string{"name": "Rex"},
+				Captures: []models.ResponseCapture{{Variable: "petId", Path: "id"}, {Variable: "tag", Path: "tags.1"}},
+			},
+			{
+				Name:    "Get a pet",
+				ID:      "getPet",
+				Method:  "GET",
+				Path:    "/pets/{{petId}}?owner={{ownerName}}&tag={{tag}}&region={{region}}",
+				Headers: map[string]string{},
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	r := New(Options{Vars: map[string]string{"baseUrl": server.URL}}, &out)
+	r.Run(file, t.TempDir(), map[string]string{"region": "eu", "user": "ignored"})
+
+	if r.Sent() != 2 || r.Failed() != 0 {
+		t.Fatalf("Expected 2 requests sent and none failed, got %d and %d:\n%s", r.Sent(), r.Failed(), out.String())
+	}
+
+	expected := []string{
+		`POST /pets Basic YW5uOnNlY3JldA== {"name": "Rex"}`,
+		"GET /pets/42?owner=Ann&tag=b&region=eu",
+	}
+	if strings.Join(received, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected requests:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(received, "\n"))
+	}
+
+	for _, expected := range []string{
+		"### Create a pet\nPOST " + server.URL + "/pets\n201 Created (",
+		"\n\n{\"id\":42,\"owner\":{\"name\":\"Ann\"},\"tags\":[\"a\",\"b\"]}\n\n",
+		"### Get a pet\nGET " + server.URL + "/pets/42?owner=Ann&tag=b&region=eu\n200 OK (",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected output to contain %q:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestRunner_RequestVars(t *testing.T) {
+	var received []string
+	server := newPetServer(t, &received)
+
+	// The request variable overrides the file variable of the same name, for
+	// that request only
+	file := &models.HTTPFile{
+		GlobalVars: map[string]string{"baseUrl": server.URL, "name": "Max"},
+		Requests: []models.HTTPRequest{
+			{Name: "Create Rex", Method: "POST", Path: "/pets", Body: `{"name": "{{name}}"}`, Vars: map[string]string{"name": "Rex"}},
+			{Name: "Create Max", Method: "POST", Path: "/pets", Body: `{"name": "{{name}}"}`},
+		},
+	}
+
+	var out bytes.Buffer
+	r := New(Options{}, &out)
+	r.Run(file, t.TempDir(), nil)
+
+	expected := []string{`POST /pets {"name": "Rex"}`, `POST /pets {"name": "Max"}`}
+	if strings.Join(received, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected requests:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(received, "\n"))
+	}
+}
+
+func TestRunner_Multipart(t *testing.T) {
+	var fields []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			t.Errorf("Expected a multipart request: %v", err)
+			return
+		}
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			content, _ := io.ReadAll(part)
+			fields = append(fields, strings.Join([]string{part.FormName(), part.FileName(), part.Header.Get("Content-Type"), string(content)}, "|"))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "photo.png"), []byte("PNG"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &models.HTTPFile{
+		GlobalVars: map[string]string{"baseUrl": server.URL, "caption": "Rex at the beach"},
+		Requests: []models.HTTPRequest{{
+			Name:    "Upload a photo",
+			Method:  "POST",
+			Path:    "/photos",
+			Headers: map[string]string{"Content-Type": "multipart/form-data; boundary=WebAppBoundary"},
+			FormParts: []models.FormPart{
+				{Name: "caption", Value: "{{caption}}"},
+				{Name: "photo", File: "./photo.png", FileName: "photo.png", ContentType: "image/png"},
+			},
+		}},
+	}
+
+	var out bytes.Buffer
+	r := New(Options{}, &out)
+	r.Run(file, dir, nil)
+
+	if r.Failed() != 0 {
+		t.Fatalf("Expected the request to succeed:\n%s", out.String())
+	}
+	expected := []string{"caption|||Rex at the beach", "photo|photo.png|image/png|PNG"}
+	if strings.Join(fields, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected parts:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(fields, "\n"))
+	}
+}
+
+func TestRunner_Failures(t *testing.T) {
+	var received []string
+	server := newPetServer(t, &received)
+
+	file := &models.HTTPFile{
+		GlobalVars:   map[string]string{"baseUrl": server.URL},
+		ResponseVars: map[string]models.ResponseRef{"petId": {Request: "createPet", Path: "id"}},
+		Requests: []models.HTTPRequest{
+			{Name: "Get a pet", Method: "GET", Path: "/pets/{{petId}}"},
+			{Name: "Get a visit", Method: "GET", Path: "/visits/{{visitId}}"},
+			{Name: "List owners", Method: "GET", Path: "/owners"},
+			{Name: "Upload a photo", Method: "PUT", Path: "/pets/1/photo", BodyFile: "./missing.png"},
+			{Name: "Loop", Method: "GET", Path: "/{{a}}", Vars: map[string]string{"a": "{{b}}", "b": "{{a}}"}},
+		},
+	}
+
+	var out bytes.Buffer
+	r := New(Options{}, &out)
+	r.Run(file, t.TempDir(), nil)
+
+	if r.Sent() != 5 || r.Failed() != 5 {
+		t.Fatalf("Expected 5 requests sent and failed, got %d and %d:\n%s", r.Sent(), r.Failed(), out.String())
+	}
+	if len(received) != 1 {
+		t.Errorf("Expected only the request to /owners to be sent, got %v", received)
+	}
+
+	for _, expected := range []string{
+		"Error: variable petId reads the response of createPet, which has not been sent\n",
+		"Error: undefined variable visitId\n",
+		"404 Not Found (",
+		"Error: GET returned 404 Not Found\n",
+		"Error: failed to read body file: ",
+		"Error: variables nested too deeply in ",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected output to contain %q:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestRunner_Filters(t *testing.T) {
+	var received []string
+	server := newPetServer(t, &received)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pet.json"), []byte(`{"name":"Rex"}`), 0644); err != nil {
+		t.Fatalf("Failed to write body file: %v", err)
+	}
+
+	pets := &models.HTTPFile{
+		GlobalVars: map[string]string{"baseUrl": server.URL},
+		Tag:        "pets",
+		Requests: []models.HTTPRequest{
+			{Name: "Create a pet", ID: "createPet", Method: "POST", Path: "/pets", BodyFile: "./pet.json"},
+			{Name: "Get a pet", ID: "getPet", Method: "GET", Path: "/pets/42"},
+			{Name: "List pets", ID: "listPets", Method: "GET", Path: "/pets"},
+		},
+	}
+	visits := &models.HTTPFile{
+		GlobalVars: map[string]string{"baseUrl": server.URL},
+		Tag:        "visits",
+		Requests:   []models.HTTPRequest{{Name: "Create a pet", ID: "createPet", Method: "POST", Path: "/visits"}},
+	}
+
+	var out bytes.Buffer
+	r := New(Options{Names: []string{"createPet", "Get a pet"}, Tags: []string{"Pets"}}, &out)
+	r.Run(pets, dir, nil)
+	r.Run(visits, dir, nil)
+
+	expected := []string{`POST /pets {"name":"Rex"}`, "GET /pets/42"}
+	if strings.Join(received, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected requests:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(received, "\n"))
+	}
+	if r.Sent() != 2 || r.Failed() != 0 {
+		t.Errorf("Expected 2 requests sent and none failed, got %d and %d", r.Sent(), r.Failed())
+	}
+}
+
+func TestJSONValue(t *testing.T) {
+	data := []byte(`{"id": 12345678901234567890, "name": "Rex", "owner": {"first-name": "Ann"}, "tags": [{"id": 1}], "alive": true, "vet": null}`)
+
+	tests := []struct {
+		path     string
+		expected string
+		ok       bool
+	}{
+		{"id", "12345678901234567890", true},
+		{"name", "Rex", true},
+		{"owner.first-name", "Ann", true},
+		{"owner", `{"first-name":"Ann"}`, true},
+		{"tags.0.id", "1", true},
+		{"alive", "true", true},
+		{"vet", "", false},
+		{"tags.1", "", false},
+		{"name.first", "", false},
+		{"missing", "", false},
+	}
+
+	for _, tt := range tests {
+		value, ok := jsonValue(data, tt.path)
+		if value != tt.expected || ok != tt.ok {
+			t.Errorf("jsonValue(%q) = %q, %v, expected %q, %v", tt.path, value, ok, tt.expected, tt.ok)
+		}
+	}
+
+	if _, ok := jsonValue([]byte("not json"), "id"); ok {
+		t.Errorf("Expected no value in an invalid document")
+	}
+}
+
+func TestBasicCredentials(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"Basic ann secret", "Basic YW5uOnNlY3JldA=="},
+		{"Basic YW5uOnNlY3JldA==", "Basic YW5uOnNlY3JldA=="},
+		{"Bearer token", "Bearer token"},
+	}
+
+	for _, tt := range tests {
+		if got := basicCredentials(tt.value); got != tt.expected {
+			t.Errorf("basicCredentials(%q) = %q, expected %q", tt.value, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+#!/usr/bin/env bash
+set -euo pipefail
+
+# Variables, each one can be overridden from the environment
+adminKey="${adminKey:-your_api_key}"
+apiKeyQuery="${apiKeyQuery:-your_api_key}"
+baseUrl="${baseUrl:-https://api.example.com}"
+basicAuthPassword="${basicAuthPassword:-password}"
+basicAuthUsername="${basicAuthUsername:-username}"
+bearerAuth="${bearerAuth:-your_auth_token}"
+session="${session:-your_api_key}"
+
+# Run admin task
+curl --fail --silent --show-error \
+  --request POST \
+  --header "X-API-Key: ${adminKey}" \
+  --write-out '\n' \
+  "${baseUrl}/admin"
+
+# List admin users
+curl --fail --silent --show-error \
+  --request GET \
+  --user "${basicAuthUsername}:${basicAuthPassword}" \
+  --write-out '\n' \
+  "${baseUrl}/admin/users"
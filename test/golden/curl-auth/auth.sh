@@ -0,0 +1,78 @@
+#!/usr/bin/env bash
+set -euo pipefail
+
+# Variables, each one can be overridden from the environment
+adminKey="${adminKey:-your_api_key}"
+apiKeyQuery="${apiKeyQuery:-your_api_key}"
+baseUrl="${baseUrl:-https://api.example.com}"
+basicAuthPassword="${basicAuthPassword:-password}"
+basicAuthUsername="${basicAuthUsername:-username}"
+bearerAuth="${bearerAuth:-your_auth_token}"
+oauthAuthorizationCode="${oauthAuthorizationCode:-your_authorization_code}"
+oauthClientId="${oauthClientId:-your_client_id}"
+oauthClientSecret="${oauthClientSecret:-your_client_secret}"
+oauthRedirectUri="${oauthRedirectUri:-http://localhost:8080/callback}"
+oidcClientId="${oidcClientId:-your_client_id}"
+oidcClientSecret="${oidcClientSecret:-your_client_secret}"
+session="${session:-your_api_key}"
+
+# Get oauth token (client credentials)
+# Company identity provider
+response=$(curl --fail --silent --show-error \
+  --request POST \
+  --header "Accept: application/json" \
+  --header "Content-Type: application/x-www-form-urlencoded" \
+  --data-raw "$(cat <<BODY
+grant_type=client_credentials&client_id=${oauthClientId}&client_secret=${oauthClientSecret}&scope=profile%20reports%3Aread
+BODY
+)" \
+  --write-out '\n' \
+  "https://auth.example.com/oauth/token")
+printf '%s\n' "$response"
+authToken=$(printf '%s' "$response" | jq -r '.access_token')
+
+# Authorize oauth (authorization code)
+# Open this URL in a browser, then copy the code parameter of the redirect into @oauthAuthorizationCode
+curl --fail --silent --show-error \
+  --request GET \
+  --write-out '\n' \
+  "https://auth.example.com/oauth/authorize?response_type=code&client_id=${oauthClientId}&redirect_uri=${oauthRedirectUri}&scope=profile"
+
+# Get oauth token (authorization code)
+# Company identity provider
+response=$(curl --fail --silent --show-error \
+  --request POST \
+  --header "Accept: application/json" \
+  --header "Content-Type: application/x-www-form-urlencoded" \
+  --data-raw "$(cat <<BODY
+grant_type=authorization_code&code=${oauthAuthorizationCode}&redirect_uri=${oauthRedirectUri}&client_id=${oauthClientId}&client_secret=${oauthClientSecret}
+BODY
+)" \
+  --write-out '\n' \
+  "https://auth.example.com/oauth/token")
+printf '%s\n' "$response"
+authToken=$(printf '%s' "$response" | jq -r '.access_token')
+
+# Discover oidc endpoints
+# Captures the token endpoint of the OpenID Connect provider
+response=$(curl --fail --silent --show-error \
+  --request GET \
+  --header "Accept: application/json" \
+  --write-out '\n' \
+  "https://auth.example.com/.well-known/openid-configuration")
+printf '%s\n' "$response"
+oidcTokenEndpoint=$(printf '%s' "$response" | jq -r '.token_endpoint')
+
+# Get oidc token (client credentials)
+response=$(curl --fail --silent --show-error \
+  --request POST \
+  --header "Accept: application/json" \
+  --header "Content-Type: application/x-www-form-urlencoded" \
+  --data-raw "$(cat <<BODY
+grant_type=client_credentials&client_id=${oidcClientId}&client_secret=${oidcClientSecret}&scope=openid
+BODY
+)" \
+  --write-out '\n' \
+  "${oidcTokenEndpoint:-}")
+printf '%s\n' "$response"
+authToken=$(printf '%s' "$response" | jq -r '.access_token')
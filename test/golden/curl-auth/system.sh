@@ -0,0 +1,17 @@
+#!/usr/bin/env bash
+set -euo pipefail
+
+# Variables, each one can be overridden from the environment
+adminKey="${adminKey:-your_api_key}"
+apiKeyQuery="${apiKeyQuery:-your_api_key}"
+baseUrl="${baseUrl:-https://api.example.com}"
+basicAuthPassword="${basicAuthPassword:-password}"
+basicAuthUsername="${basicAuthUsername:-username}"
+bearerAuth="${bearerAuth:-your_auth_token}"
+session="${session:-your_api_key}"
+
+# Health check
+curl --fail --silent --show-error \
+  --request GET \
+  --write-out '\n' \
+  "${baseUrl}/health"
@@ -0,0 +1,39 @@
+#!/usr/bin/env bash
+set -euo pipefail
+
+# Variables, each one can be overridden from the environment
+authToken="${authToken:-your_auth_token}"
+baseUrl="${baseUrl:-https://clinic.example.com/v1}"
+
+# List pets
+curl --fail --silent --show-error \
+  --request GET \
+  --write-out '\n' \
+  "${baseUrl}/pets"
+
+# Create a pet
+response=$(curl --fail --silent --show-error \
+  --request POST \
+  --header "Content-Type: application/json" \
+  --data-raw "$(cat <<'BODY'
+{
+  "name": "string"
+}
+BODY
+)" \
+  --write-out '\n' \
+  "${baseUrl}/pets")
+printf '%s\n' "$response"
+petId=$(printf '%s' "$response" | jq -r '.id')
+
+# Get a pet
+curl --fail --silent --show-error \
+  --request GET \
+  --write-out '\n' \
+  "${baseUrl}/pets/${petId:-0}"
+
+# Delete a pet
+curl --fail --silent --show-error \
+  --request DELETE \
+  --write-out '\n' \
+  "${baseUrl}/pets/${petId:-0}"
@@ -0,0 +1,20 @@
+#!/usr/bin/env bash
+set -euo pipefail
+
+# Variables, each one can be overridden from the environment
+authToken="${authToken:-your_auth_token}"
+baseUrl="${baseUrl:-https://clinic.example.com/v1}"
+
+# Book a visit
+response=$(curl --fail --silent --show-error \
+  --request POST \
+  --write-out '\n' \
+  "${baseUrl}/pets/${petId:-0}/visits")
+printf '%s\n' "$response"
+visitId=$(printf '%s' "$response" | jq -r '.visitId')
+
+# Get a visit
+curl --fail --silent --show-error \
+  --request GET \
+  --write-out '\n' \
+  "${baseUrl}/pets/${petId:-0}/visits/${visitId:-string}"